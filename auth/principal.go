@@ -0,0 +1,56 @@
+// Package auth describes the authenticated caller of a request.
+package auth
+
+import "context"
+
+const (
+	// PrincipalPartner is a partner system calling with an API key.
+	PrincipalPartner = "partner"
+	// PrincipalStaff is an employee calling with a bearer JWT.
+	PrincipalStaff = "staff"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Type is PrincipalPartner or PrincipalStaff.
+	Type string
+	// ID is the API key ID of a partner or the JWT subject of a staff member.
+	ID   string
+	Name string
+	// Partner is the partner code the API key was issued to; empty for staff.
+	Partner string
+	Roles   []string
+}
+
+// HasRole reports whether the principal was granted role.
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor identifies the principal in audit records, e.g. "staff:budi" or
+// "partner:tokopedia/12".
+func (p *Principal) Actor() string {
+	if p.Type == PrincipalPartner {
+		return PrincipalPartner + ":" + p.Partner + "/" + p.ID
+	}
+	return p.Type + ":" + p.ID
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal of the request, or nil when ctx carries
+// none, as in background jobs.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,77 @@
+package auth
+
+const (
+	// RolePartner is granted to every partner API key.
+	RolePartner         = "partner"
+	RoleCustomerService = "customer-service"
+	RoleCreditOps       = "credit-ops"
+	RoleFinance         = "finance"
+	RoleAdmin           = "admin"
+)
+
+// Roles lists every role a principal can hold.
+var Roles = []string{RolePartner, RoleCustomerService, RoleCreditOps, RoleFinance, RoleAdmin}
+
+// Permission is an action a route requires.
+type Permission string
+
+const (
+	PermCustomersRead     Permission = "customers:read"
+	PermCustomersWrite    Permission = "customers:write"
+	PermLimitsRead        Permission = "limits:read"
+	PermLimitsWrite       Permission = "limits:write"
+	PermTransactionsRead  Permission = "transactions:read"
+	PermTransactionsWrite Permission = "transactions:write"
+	// PermTransactionsCancel voids a contract in its cooling-off window.
+	PermTransactionsCancel Permission = "transactions:cancel"
+	PermPaymentsRead       Permission = "payments:read"
+	PermPaymentsWrite      Permission = "payments:write"
+	PermLedgerRead         Permission = "ledger:read"
+	PermWebhooksManage     Permission = "webhooks:manage"
+	// PermWebhooksRead shows webhook subscriptions and their delivery log. A
+	// partner only sees its own subscriptions.
+	PermWebhooksRead Permission = "webhooks:read"
+	PermAuditRead    Permission = "audit:read"
+)
+
+// rolePermissions grants permissions per role. Setting limits is reserved to
+// credit-ops; admin manages the platform but does not make credit decisions.
+var rolePermissions = map[string][]Permission{
+	RolePartner: {
+		PermTransactionsRead, PermTransactionsWrite, PermTransactionsCancel, PermWebhooksRead,
+	},
+	RoleCustomerService: {
+		PermCustomersRead, PermCustomersWrite, PermLimitsRead,
+		PermTransactionsRead, PermTransactionsCancel, PermPaymentsRead, PermAuditRead,
+	},
+	RoleCreditOps: {
+		PermCustomersRead, PermCustomersWrite, PermLimitsRead, PermLimitsWrite,
+		PermTransactionsRead, PermTransactionsWrite, PermTransactionsCancel, PermPaymentsRead,
+	},
+	RoleFinance: {
+		PermCustomersRead, PermLimitsRead, PermTransactionsRead,
+		PermPaymentsRead, PermPaymentsWrite, PermLedgerRead,
+	},
+	RoleAdmin: {
+		PermCustomersRead, PermLimitsRead, PermTransactionsRead,
+		PermPaymentsRead, PermLedgerRead, PermWebhooksManage, PermWebhooksRead, PermAuditRead,
+	},
+}
+
+// Can reports whether any role of the principal grants perm.
+func (p *Principal) Can(perm Permission) bool {
+	for _, role := range p.Roles {
+		for _, granted := range rolePermissions[role] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsPartner reports whether the principal is a partner system, whose access is
+// scoped to its own channel.
+func (p *Principal) IsPartner() bool {
+	return p.Type == PrincipalPartner
+}
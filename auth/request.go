@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request, which
+// audit records use to tie a change to the call that made it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request, or "" when ctx carries none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -1,9 +1,231 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"multifinance/config"
+	"multifinance/database"
 	"multifinance/delivery"
+	"multifinance/repository"
+	"multifinance/usecase/apikey"
 )
 
+const usage = `usage:
+  main                      start the HTTP server
+  main migrate up           apply all pending migrations
+  main migrate down [n]     revert the last n migrations (default 1)
+  main migrate status       list migrations and when they were applied
+  main seed                 load the optional demo data
+  main apikey create <partner> [name]
+                            issue an API key to a partner and print it once
+  main apikey revoke <id>   revoke an API key
+  main outbox failed [n]    list the latest n events that ran out of attempts (default 50)
+  main outbox retry <event_id>
+                            queue a failed event again`
+
 func main() {
-	delivery.Run()
+	if len(os.Args) < 2 {
+		delivery.Run()
+		return
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "migrate":
+		err = runMigrate(os.Args[2:])
+	case "seed":
+		err = runSeed()
+	case "apikey":
+		err = runAPIKey(os.Args[2:])
+	case "outbox":
+		err = runOutbox(os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command %q", os.Args[1])
+	}
+	if err != nil {
+		log.Fatalf("%v\n%s", err, usage)
+	}
+}
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate subcommand")
+	}
+
+	db, _, err := config.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		adopted, err := migrator.Baseline(ctx)
+		if err != nil {
+			return err
+		}
+		if adopted {
+			log.Println("existing schema adopted as 0001_initial_schema")
+		}
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("applied %04d_%s", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			log.Println("schema is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			log.Printf("reverted %04d_%s", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q", args[0])
+	}
+}
+
+func runSeed() error {
+	db, _, err := config.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := database.Seed(context.Background(), db); err != nil {
+		return err
+	}
+	log.Println("seed data loaded")
+	return nil
+}
+
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing apikey subcommand")
+	}
+
+	db, _, err := config.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	keys := apikey.NewAPIKeyUsecase(repository.NewAPIKeyRepository(db))
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		if len(args) < 2 {
+			return fmt.Errorf("missing partner")
+		}
+		name := ""
+		if len(args) > 2 {
+			name = args[2]
+		}
+		key, k, err := keys.CreateKey(ctx, args[1], name)
+		if err != nil {
+			return err
+		}
+		log.Printf("created API key %d (%s) for partner %s; store it now, it is not shown again", k.ID, k.KeyPrefix, k.Partner)
+		fmt.Println(key)
+		return nil
+	case "revoke":
+		if len(args) < 2 {
+			return fmt.Errorf("missing key id")
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key id %q", args[1])
+		}
+		if err := keys.RevokeKey(ctx, id); err != nil {
+			return err
+		}
+		log.Printf("revoked API key %d", id)
+		return nil
+	default:
+		return fmt.Errorf("unknown apikey subcommand %q", args[0])
+	}
+}
+
+func runOutbox(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing outbox subcommand")
+	}
+
+	db, _, err := config.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	outboxRepo := repository.NewOutboxRepository(db)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "failed":
+		limit := 50
+		if len(args) > 1 {
+			if limit, err = strconv.Atoi(args[1]); err != nil || limit < 1 {
+				return fmt.Errorf("invalid number of events %q", args[1])
+			}
+		}
+		events, err := outboxRepo.ListFailedEvents(ctx, limit)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			lastError := ""
+			if e.LastError != nil {
+				lastError = *e.LastError
+			}
+			fmt.Printf("%s %-20s %-25s %s attempts=%d %s\n", e.EventID, e.EventType, e.AggregateID, e.FailedAt.Format("2006-01-02 15:04:05"), e.Attempts, lastError)
+		}
+		return nil
+	case "retry":
+		if len(args) < 2 {
+			return fmt.Errorf("missing event id")
+		}
+		ok, err := outboxRepo.RetryFailedEvent(ctx, args[1], time.Now())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no failed event %q", args[1])
+		}
+		log.Printf("queued event %s again", args[1])
+		return nil
+	default:
+		return fmt.Errorf("unknown outbox subcommand %q", args[0])
+	}
 }
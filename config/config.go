@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,11 +21,171 @@ type DBConfig struct {
 
 type APIConfig struct {
 	ApiPort string
+	// ReadTimeout, WriteTimeout and IdleTimeout bound a single HTTP connection;
+	// ReadHeaderTimeout bounds reading the request headers.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long a stopping server may drain in-flight requests
+	// and background jobs before the database is closed.
+	ShutdownTimeout time.Duration
+	// DrainDelay is how long a stopping server keeps serving with readiness
+	// failing, so load balancers stop routing to it first.
+	DrainDelay time.Duration
+}
+
+// HealthConfig controls the readiness probe.
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check, such as the database ping.
+	CheckTimeout time.Duration
+}
+
+// LimitPolicyConfig holds the parameters of the default salary-based limit policy.
+type LimitPolicyConfig struct {
+	// SalaryMultipliers maps a tenor to the multiple of monthly salary granted as its limit.
+	SalaryMultipliers map[int]float64
+	MaxLimit          int64
+	LimitRounding     int64
+	MinAge            int
+	MaxAge            int
+}
+
+// PricingConfig holds the product rates used to price transactions server-side.
+type PricingConfig struct {
+	// Method is either "flat" or "annuity".
+	Method string
+	// MonthlyRates maps a tenor to its monthly interest rate, e.g. 0.02 for 2%.
+	MonthlyRates map[int]float64
+	// MismatchPolicy decides what happens when client-sent amounts differ from
+	// the computed ones: "reject" the request or "override" the client values.
+	MismatchPolicy string
+	// Tolerance is the absolute difference in rupiah still treated as a match.
+	Tolerance int64
+}
+
+// ContractConfig holds the codes printed in generated contract numbers.
+type ContractConfig struct {
+	BranchCode  string
+	ProductCode string
+}
+
+// CancellationConfig controls when a contract may still be cancelled.
+type CancellationConfig struct {
+	// CoolingOff is how long after creation a contract can be cancelled.
+	CoolingOff time.Duration
+}
+
+// HoldConfig controls limit holds placed during a partner checkout.
+type HoldConfig struct {
+	// TTL is how long a hold reserves limit before it expires.
+	TTL time.Duration
+	// SweepInterval is how often expired holds are released.
+	SweepInterval time.Duration
+}
+
+// DelinquencyConfig holds the late fee rule and the schedule of the delinquency job.
+type DelinquencyConfig struct {
+	// PenaltyMethod is "flat_per_day" or "percent_per_day".
+	PenaltyMethod string
+	// PenaltyFlatPerDay is charged per overdue installment per late day.
+	PenaltyFlatPerDay int64
+	// PenaltyRatePerDay is the daily share of the installment amount, e.g. 0.001 for 0.1%.
+	PenaltyRatePerDay float64
+	// PenaltyCapRate caps the penalty of one installment as a share of its amount; 0 disables the cap.
+	PenaltyCapRate float64
+	// GraceDays are late days that do not accrue a penalty.
+	GraceDays   int
+	JobInterval time.Duration
+}
+
+// SettlementConfig holds the early settlement rules.
+type SettlementConfig struct {
+	// FeeRate is the early termination fee as a share of the remaining principal.
+	FeeRate float64
+	// InterestRebateRate is the share of accrued interest waived on early settlement.
+	InterestRebateRate float64
+}
+
+// LedgerConfig holds the schedule of the interest accrual job.
+type LedgerConfig struct {
+	AccrualJobInterval time.Duration
+}
+
+// OutboxConfig controls how outbox events are relayed to the event sink.
+type OutboxConfig struct {
+	// Sink is "http" or "file".
+	Sink string
+	// HTTPURL receives a POST per event when Sink is "http".
+	HTTPURL     string
+	HTTPTimeout time.Duration
+	// FilePath is the JSON lines file events are appended to when Sink is "file".
+	FilePath      string
+	RelayInterval time.Duration
+	BatchSize     int
+	// RetryBase is the delay after the first failed delivery; it doubles per
+	// failure up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// MaxAttempts is how many times an event is tried before it is marked failed.
+	MaxAttempts int
+	// ClaimTTL is how long a relay holds the events it claimed before another
+	// relay may take them over.
+	ClaimTTL time.Duration
+}
+
+// WebhookConfig controls how webhook deliveries are sent to partner endpoints.
+type WebhookConfig struct {
+	DeliveryInterval time.Duration
+	BatchSize        int
+	Timeout          time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is marked failed.
+	MaxAttempts int
+	// RetryBase is the delay after the first failed attempt; it doubles per
+	// failure up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// ClaimTTL is how long a sender holds the deliveries it claimed before
+	// another replica may take them over.
+	ClaimTTL time.Duration
+}
+
+// AuthConfig holds the keys staff bearer tokens are verified with. Partner API
+// keys are stored in the database.
+type AuthConfig struct {
+	// JWTSecret verifies HS256 tokens; empty rejects HS256.
+	JWTSecret string
+	// JWTPublicKeyFile is a PEM RSA public key verifying RS256 tokens; empty rejects RS256.
+	JWTPublicKeyFile string
+	// JWTIssuer and JWTAudience, when set, must match the iss and aud claims.
+	JWTIssuer   string
+	JWTAudience string
+	// JWTLeeway tolerates clock skew when checking exp and nbf.
+	JWTLeeway time.Duration
+}
+
+// CORSConfig lists the browser origins allowed to call the API.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
 }
 
 type Config struct {
 	DBConfig
 	APIConfig
+	HealthConfig
+	LimitPolicyConfig
+	PricingConfig
+	ContractConfig
+	CancellationConfig
+	HoldConfig
+	DelinquencyConfig
+	SettlementConfig
+	LedgerConfig
+	OutboxConfig
+	WebhookConfig
+	AuthConfig
+	CORSConfig
 }
 
 func (c *Config) readConfig() error {
@@ -59,6 +221,202 @@ func (c *Config) readConfig() error {
 		return fmt.Errorf("invalid API port number: %v", err)
 	}
 
+	var err error
+	if c.APIConfig.ReadTimeout, err = time.ParseDuration(getEnv("HTTP_READ_TIMEOUT", "15s")); err != nil || c.APIConfig.ReadTimeout <= 0 {
+		return fmt.Errorf("invalid HTTP_READ_TIMEOUT: %q", getEnv("HTTP_READ_TIMEOUT", "15s"))
+	}
+	if c.APIConfig.ReadHeaderTimeout, err = time.ParseDuration(getEnv("HTTP_READ_HEADER_TIMEOUT", "5s")); err != nil || c.APIConfig.ReadHeaderTimeout <= 0 {
+		return fmt.Errorf("invalid HTTP_READ_HEADER_TIMEOUT: %q", getEnv("HTTP_READ_HEADER_TIMEOUT", "5s"))
+	}
+	if c.APIConfig.WriteTimeout, err = time.ParseDuration(getEnv("HTTP_WRITE_TIMEOUT", "30s")); err != nil || c.APIConfig.WriteTimeout <= 0 {
+		return fmt.Errorf("invalid HTTP_WRITE_TIMEOUT: %q", getEnv("HTTP_WRITE_TIMEOUT", "30s"))
+	}
+	if c.APIConfig.IdleTimeout, err = time.ParseDuration(getEnv("HTTP_IDLE_TIMEOUT", "60s")); err != nil || c.APIConfig.IdleTimeout <= 0 {
+		return fmt.Errorf("invalid HTTP_IDLE_TIMEOUT: %q", getEnv("HTTP_IDLE_TIMEOUT", "60s"))
+	}
+	if c.APIConfig.ShutdownTimeout, err = time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "20s")); err != nil || c.APIConfig.ShutdownTimeout <= 0 {
+		return fmt.Errorf("invalid SHUTDOWN_TIMEOUT: %q", getEnv("SHUTDOWN_TIMEOUT", "20s"))
+	}
+	if c.APIConfig.DrainDelay, err = time.ParseDuration(getEnv("SHUTDOWN_DRAIN_DELAY", "5s")); err != nil || c.APIConfig.DrainDelay < 0 {
+		return fmt.Errorf("invalid SHUTDOWN_DRAIN_DELAY: %q", getEnv("SHUTDOWN_DRAIN_DELAY", "5s"))
+	}
+	if c.HealthConfig.CheckTimeout, err = time.ParseDuration(getEnv("HEALTH_CHECK_TIMEOUT", "2s")); err != nil || c.HealthConfig.CheckTimeout <= 0 {
+		return fmt.Errorf("invalid HEALTH_CHECK_TIMEOUT: %q", getEnv("HEALTH_CHECK_TIMEOUT", "2s"))
+	}
+
+	multipliers, err := parseTenorFloats(getEnv("LIMIT_SALARY_MULTIPLIERS", "1:0.5,2:1,3:1.5,4:2,6:3,12:5"))
+	if err != nil {
+		return fmt.Errorf("invalid LIMIT_SALARY_MULTIPLIERS: %v", err)
+	}
+	c.LimitPolicyConfig = LimitPolicyConfig{SalaryMultipliers: multipliers}
+
+	if c.LimitPolicyConfig.MaxLimit, err = strconv.ParseInt(getEnv("LIMIT_MAX_AMOUNT", "100000000"), 10, 64); err != nil {
+		return fmt.Errorf("invalid LIMIT_MAX_AMOUNT: %v", err)
+	}
+	if c.LimitPolicyConfig.LimitRounding, err = strconv.ParseInt(getEnv("LIMIT_ROUNDING", "10000"), 10, 64); err != nil {
+		return fmt.Errorf("invalid LIMIT_ROUNDING: %v", err)
+	}
+	if c.LimitPolicyConfig.MinAge, err = strconv.Atoi(getEnv("LIMIT_MIN_AGE", "21")); err != nil {
+		return fmt.Errorf("invalid LIMIT_MIN_AGE: %v", err)
+	}
+	if c.LimitPolicyConfig.MaxAge, err = strconv.Atoi(getEnv("LIMIT_MAX_AGE", "60")); err != nil {
+		return fmt.Errorf("invalid LIMIT_MAX_AGE: %v", err)
+	}
+
+	rates, err := parseTenorFloats(getEnv("PRICING_MONTHLY_RATES", "1:0.025,2:0.025,3:0.022,4:0.022,6:0.02,12:0.018"))
+	if err != nil {
+		return fmt.Errorf("invalid PRICING_MONTHLY_RATES: %v", err)
+	}
+	c.PricingConfig = PricingConfig{
+		Method:         getEnv("PRICING_METHOD", "flat"),
+		MonthlyRates:   rates,
+		MismatchPolicy: getEnv("PRICING_MISMATCH_POLICY", "reject"),
+	}
+	if c.PricingConfig.Method != "flat" && c.PricingConfig.Method != "annuity" {
+		return fmt.Errorf("invalid PRICING_METHOD: %q", c.PricingConfig.Method)
+	}
+	if c.PricingConfig.MismatchPolicy != "reject" && c.PricingConfig.MismatchPolicy != "override" {
+		return fmt.Errorf("invalid PRICING_MISMATCH_POLICY: %q", c.PricingConfig.MismatchPolicy)
+	}
+	if c.PricingConfig.Tolerance, err = strconv.ParseInt(getEnv("PRICING_TOLERANCE", "0"), 10, 64); err != nil {
+		return fmt.Errorf("invalid PRICING_TOLERANCE: %v", err)
+	}
+
+	c.ContractConfig = ContractConfig{
+		BranchCode:  strings.ToUpper(getEnv("CONTRACT_BRANCH_CODE", "JKT")),
+		ProductCode: strings.ToUpper(getEnv("CONTRACT_PRODUCT_CODE", "MF")),
+	}
+	if c.ContractConfig.BranchCode == "" || c.ContractConfig.ProductCode == "" {
+		return fmt.Errorf("CONTRACT_BRANCH_CODE and CONTRACT_PRODUCT_CODE must not be empty")
+	}
+
+	if c.CancellationConfig.CoolingOff, err = time.ParseDuration(getEnv("CANCEL_COOLING_OFF", "24h")); err != nil {
+		return fmt.Errorf("invalid CANCEL_COOLING_OFF: %v", err)
+	}
+
+	if c.HoldConfig.TTL, err = time.ParseDuration(getEnv("HOLD_TTL", "15m")); err != nil || c.HoldConfig.TTL <= 0 {
+		return fmt.Errorf("invalid HOLD_TTL: %q", getEnv("HOLD_TTL", "15m"))
+	}
+	if c.HoldConfig.SweepInterval, err = time.ParseDuration(getEnv("HOLD_SWEEP_INTERVAL", "1m")); err != nil || c.HoldConfig.SweepInterval <= 0 {
+		return fmt.Errorf("invalid HOLD_SWEEP_INTERVAL: %q", getEnv("HOLD_SWEEP_INTERVAL", "1m"))
+	}
+
+	c.DelinquencyConfig = DelinquencyConfig{PenaltyMethod: getEnv("PENALTY_METHOD", "percent_per_day")}
+	if c.DelinquencyConfig.PenaltyMethod != "flat_per_day" && c.DelinquencyConfig.PenaltyMethod != "percent_per_day" {
+		return fmt.Errorf("invalid PENALTY_METHOD: %q", c.DelinquencyConfig.PenaltyMethod)
+	}
+	if c.DelinquencyConfig.PenaltyFlatPerDay, err = strconv.ParseInt(getEnv("PENALTY_FLAT_PER_DAY", "5000"), 10, 64); err != nil {
+		return fmt.Errorf("invalid PENALTY_FLAT_PER_DAY: %v", err)
+	}
+	if c.DelinquencyConfig.PenaltyRatePerDay, err = strconv.ParseFloat(getEnv("PENALTY_RATE_PER_DAY", "0.001"), 64); err != nil {
+		return fmt.Errorf("invalid PENALTY_RATE_PER_DAY: %v", err)
+	}
+	if c.DelinquencyConfig.PenaltyCapRate, err = strconv.ParseFloat(getEnv("PENALTY_CAP_RATE", "0.1"), 64); err != nil {
+		return fmt.Errorf("invalid PENALTY_CAP_RATE: %v", err)
+	}
+	if c.DelinquencyConfig.GraceDays, err = strconv.Atoi(getEnv("PENALTY_GRACE_DAYS", "0")); err != nil {
+		return fmt.Errorf("invalid PENALTY_GRACE_DAYS: %v", err)
+	}
+	if c.DelinquencyConfig.JobInterval, err = time.ParseDuration(getEnv("DELINQUENCY_JOB_INTERVAL", "24h")); err != nil || c.DelinquencyConfig.JobInterval <= 0 {
+		return fmt.Errorf("invalid DELINQUENCY_JOB_INTERVAL: %q", getEnv("DELINQUENCY_JOB_INTERVAL", "24h"))
+	}
+
+	if c.SettlementConfig.FeeRate, err = strconv.ParseFloat(getEnv("SETTLEMENT_FEE_RATE", "0"), 64); err != nil {
+		return fmt.Errorf("invalid SETTLEMENT_FEE_RATE: %v", err)
+	}
+	if c.SettlementConfig.InterestRebateRate, err = strconv.ParseFloat(getEnv("SETTLEMENT_INTEREST_REBATE", "0"), 64); err != nil {
+		return fmt.Errorf("invalid SETTLEMENT_INTEREST_REBATE: %v", err)
+	}
+
+	if c.LedgerConfig.AccrualJobInterval, err = time.ParseDuration(getEnv("LEDGER_ACCRUAL_JOB_INTERVAL", "24h")); err != nil || c.LedgerConfig.AccrualJobInterval <= 0 {
+		return fmt.Errorf("invalid LEDGER_ACCRUAL_JOB_INTERVAL: %q", getEnv("LEDGER_ACCRUAL_JOB_INTERVAL", "24h"))
+	}
+
+	c.OutboxConfig = OutboxConfig{
+		Sink:     getEnv("OUTBOX_SINK", "file"),
+		HTTPURL:  getEnv("OUTBOX_HTTP_URL", ""),
+		FilePath: getEnv("OUTBOX_FILE_PATH", "outbox-events.jsonl"),
+	}
+	switch c.OutboxConfig.Sink {
+	case "http":
+		if c.OutboxConfig.HTTPURL == "" {
+			return fmt.Errorf("OUTBOX_HTTP_URL is required when OUTBOX_SINK is http")
+		}
+	case "file":
+	default:
+		return fmt.Errorf("invalid OUTBOX_SINK: %q", c.OutboxConfig.Sink)
+	}
+	if c.OutboxConfig.HTTPTimeout, err = time.ParseDuration(getEnv("OUTBOX_HTTP_TIMEOUT", "10s")); err != nil {
+		return fmt.Errorf("invalid OUTBOX_HTTP_TIMEOUT: %v", err)
+	}
+	if c.OutboxConfig.RelayInterval, err = time.ParseDuration(getEnv("OUTBOX_RELAY_INTERVAL", "5s")); err != nil || c.OutboxConfig.RelayInterval <= 0 {
+		return fmt.Errorf("invalid OUTBOX_RELAY_INTERVAL: %q", getEnv("OUTBOX_RELAY_INTERVAL", "5s"))
+	}
+	if c.OutboxConfig.BatchSize, err = strconv.Atoi(getEnv("OUTBOX_BATCH_SIZE", "100")); err != nil || c.OutboxConfig.BatchSize <= 0 {
+		return fmt.Errorf("invalid OUTBOX_BATCH_SIZE: %q", getEnv("OUTBOX_BATCH_SIZE", "100"))
+	}
+	if c.OutboxConfig.RetryBase, err = time.ParseDuration(getEnv("OUTBOX_RETRY_BASE", "10s")); err != nil {
+		return fmt.Errorf("invalid OUTBOX_RETRY_BASE: %v", err)
+	}
+	if c.OutboxConfig.RetryMax, err = time.ParseDuration(getEnv("OUTBOX_RETRY_MAX", "1h")); err != nil {
+		return fmt.Errorf("invalid OUTBOX_RETRY_MAX: %v", err)
+	}
+	if c.OutboxConfig.MaxAttempts, err = strconv.Atoi(getEnv("OUTBOX_MAX_ATTEMPTS", "20")); err != nil || c.OutboxConfig.MaxAttempts <= 0 {
+		return fmt.Errorf("invalid OUTBOX_MAX_ATTEMPTS: %q", getEnv("OUTBOX_MAX_ATTEMPTS", "20"))
+	}
+	if c.OutboxConfig.ClaimTTL, err = time.ParseDuration(getEnv("OUTBOX_CLAIM_TTL", "1m")); err != nil || c.OutboxConfig.ClaimTTL <= 0 {
+		return fmt.Errorf("invalid OUTBOX_CLAIM_TTL: %q", getEnv("OUTBOX_CLAIM_TTL", "1m"))
+	}
+
+	if c.WebhookConfig.DeliveryInterval, err = time.ParseDuration(getEnv("WEBHOOK_DELIVERY_INTERVAL", "5s")); err != nil || c.WebhookConfig.DeliveryInterval <= 0 {
+		return fmt.Errorf("invalid WEBHOOK_DELIVERY_INTERVAL: %q", getEnv("WEBHOOK_DELIVERY_INTERVAL", "5s"))
+	}
+	if c.WebhookConfig.BatchSize, err = strconv.Atoi(getEnv("WEBHOOK_BATCH_SIZE", "100")); err != nil || c.WebhookConfig.BatchSize <= 0 {
+		return fmt.Errorf("invalid WEBHOOK_BATCH_SIZE: %q", getEnv("WEBHOOK_BATCH_SIZE", "100"))
+	}
+	if c.WebhookConfig.Timeout, err = time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s")); err != nil {
+		return fmt.Errorf("invalid WEBHOOK_TIMEOUT: %v", err)
+	}
+	if c.WebhookConfig.MaxAttempts, err = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "10")); err != nil || c.WebhookConfig.MaxAttempts <= 0 {
+		return fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS: %q", getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+	}
+	if c.WebhookConfig.RetryBase, err = time.ParseDuration(getEnv("WEBHOOK_RETRY_BASE", "30s")); err != nil {
+		return fmt.Errorf("invalid WEBHOOK_RETRY_BASE: %v", err)
+	}
+	if c.WebhookConfig.RetryMax, err = time.ParseDuration(getEnv("WEBHOOK_RETRY_MAX", "6h")); err != nil {
+		return fmt.Errorf("invalid WEBHOOK_RETRY_MAX: %v", err)
+	}
+	if c.WebhookConfig.ClaimTTL, err = time.ParseDuration(getEnv("WEBHOOK_CLAIM_TTL", "5m")); err != nil || c.WebhookConfig.ClaimTTL <= 0 {
+		return fmt.Errorf("invalid WEBHOOK_CLAIM_TTL: %q", getEnv("WEBHOOK_CLAIM_TTL", "5m"))
+	}
+
+	c.AuthConfig = AuthConfig{
+		JWTSecret:        getEnv("AUTH_JWT_HS256_SECRET", ""),
+		JWTPublicKeyFile: getEnv("AUTH_JWT_RS256_PUBLIC_KEY_FILE", ""),
+		JWTIssuer:        getEnv("AUTH_JWT_ISSUER", ""),
+		JWTAudience:      getEnv("AUTH_JWT_AUDIENCE", ""),
+	}
+	if c.AuthConfig.JWTSecret != "" && len(c.AuthConfig.JWTSecret) < 32 {
+		return fmt.Errorf("AUTH_JWT_HS256_SECRET must be at least 32 characters")
+	}
+	if c.AuthConfig.JWTLeeway, err = time.ParseDuration(getEnv("AUTH_JWT_LEEWAY", "30s")); err != nil {
+		return fmt.Errorf("invalid AUTH_JWT_LEEWAY: %v", err)
+	}
+
+	for _, origin := range strings.Split(getEnv("CORS_ALLOWED_ORIGINS", "*"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			c.CORSConfig.AllowedOrigins = append(c.CORSConfig.AllowedOrigins, origin)
+		}
+	}
+	if c.CORSConfig.AllowCredentials, err = strconv.ParseBool(getEnv("CORS_ALLOW_CREDENTIALS", "false")); err != nil {
+		return fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: %v", err)
+	}
+	for _, origin := range c.CORSConfig.AllowedOrigins {
+		if origin == "*" && c.CORSConfig.AllowCredentials {
+			return fmt.Errorf("CORS_ALLOW_CREDENTIALS cannot be used with CORS_ALLOWED_ORIGINS=*")
+		}
+	}
+
 	return nil
 }
 
@@ -69,6 +427,31 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parseTenorFloats parses a "tenor:value" list such as "1:0.5,2:1".
+func parseTenorFloats(s string) (map[int]float64, error) {
+	result := make(map[int]float64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected tenor:value, got %q", pair)
+		}
+		tenor, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || tenor <= 0 {
+			return nil, fmt.Errorf("invalid tenor %q", parts[0])
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", parts[1])
+		}
+		result[tenor] = value
+	}
+	return result, nil
+}
+
 func NewConfig() (*Config, error) {
 	cfg := &Config{}
 	if err := cfg.readConfig(); err != nil {
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS customer_limits;
+DROP TABLE IF EXISTS customers;
@@ -1,6 +1,3 @@
-CREATE DATABASE IF NOT EXISTS xyz_multifinance;
-USE xyz_multifinance;
-
 CREATE TABLE customers (
     nik VARCHAR(16) PRIMARY KEY,
     full_name VARCHAR(255) NOT NULL,
@@ -31,4 +28,3 @@ CREATE TABLE transactions (
     created_at DATETIME NOT NULL,
     FOREIGN KEY (customer_nik) REFERENCES customers(nik)
 ) ENGINE=InnoDB;
-
@@ -0,0 +1,2 @@
+ALTER TABLE customers
+    ADD COLUMN gender CHAR(1) NOT NULL DEFAULT 'M' AFTER birth_date;
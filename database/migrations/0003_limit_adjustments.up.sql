@@ -0,0 +1,12 @@
+CREATE TABLE limit_adjustments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_nik VARCHAR(16) NOT NULL,
+    tenor INT NOT NULL,
+    previous_amount BIGINT NOT NULL,
+    new_amount BIGINT NOT NULL,
+    changed_by VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_limit_adjustments_customer (customer_nik, tenor),
+    FOREIGN KEY (customer_nik) REFERENCES customers(nik)
+) ENGINE=InnoDB;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    ADD COLUMN tenor INT NOT NULL DEFAULT 0 AFTER asset_name,
+    ADD INDEX idx_transactions_customer_created (customer_nik, created_at, contract_number);
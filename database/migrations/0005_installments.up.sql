@@ -0,0 +1,11 @@
+CREATE TABLE installments (
+    contract_number VARCHAR(50) NOT NULL,
+    period INT NOT NULL,
+    due_date DATE NOT NULL,
+    principal BIGINT NOT NULL,
+    interest BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    outstanding_balance BIGINT NOT NULL,
+    PRIMARY KEY (contract_number, period),
+    FOREIGN KEY (contract_number) REFERENCES transactions(contract_number)
+) ENGINE=InnoDB;
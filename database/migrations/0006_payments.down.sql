@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS payments;
+
+ALTER TABLE installments
+    DROP COLUMN paid_at,
+    DROP COLUMN status,
+    DROP COLUMN paid_interest,
+    DROP COLUMN paid_principal;
+
+ALTER TABLE transactions
+    DROP COLUMN status;
@@ -0,0 +1,20 @@
+ALTER TABLE transactions
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' AFTER tenor;
+
+ALTER TABLE installments
+    ADD COLUMN paid_principal BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN paid_interest BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'unpaid',
+    ADD COLUMN paid_at DATETIME NULL;
+
+CREATE TABLE payments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    contract_number VARCHAR(50) NOT NULL,
+    amount BIGINT NOT NULL,
+    principal_paid BIGINT NOT NULL,
+    interest_paid BIGINT NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    paid_at DATETIME NOT NULL,
+    INDEX idx_payments_contract (contract_number, paid_at),
+    FOREIGN KEY (contract_number) REFERENCES transactions(contract_number)
+) ENGINE=InnoDB;
@@ -0,0 +1,8 @@
+CREATE TABLE idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    contract_number VARCHAR(50) NOT NULL,
+    response JSON NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (contract_number) REFERENCES transactions(contract_number)
+) ENGINE=InnoDB;
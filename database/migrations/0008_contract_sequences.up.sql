@@ -0,0 +1,4 @@
+CREATE TABLE contract_sequences (
+    scope VARCHAR(50) PRIMARY KEY,
+    last_value BIGINT NOT NULL
+) ENGINE=InnoDB;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    DROP COLUMN cancelled_at,
+    DROP COLUMN cancel_reason;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    ADD COLUMN cancelled_at DATETIME NULL,
+    ADD COLUMN cancel_reason VARCHAR(255) NULL;
@@ -0,0 +1,2 @@
+DROP INDEX idx_transactions_status ON transactions;
+DROP TABLE IF EXISTS contract_delinquency;
@@ -0,0 +1,15 @@
+CREATE TABLE contract_delinquency (
+    contract_number VARCHAR(50) PRIMARY KEY,
+    dpd INT NOT NULL,
+    overdue_installments INT NOT NULL,
+    overdue_amount BIGINT NOT NULL,
+    penalty_amount BIGINT NOT NULL,
+    collectibility TINYINT NOT NULL,
+    next_due_date DATE NULL,
+    maturity_date DATE NOT NULL,
+    as_of DATE NOT NULL,
+    INDEX idx_contract_delinquency_collectibility (collectibility, dpd),
+    FOREIGN KEY (contract_number) REFERENCES transactions(contract_number)
+) ENGINE=InnoDB;
+
+CREATE INDEX idx_transactions_status ON transactions (status, contract_number);
@@ -0,0 +1,16 @@
+CREATE TABLE settlements (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    contract_number VARCHAR(50) NOT NULL,
+    payment_id BIGINT NOT NULL,
+    settlement_date DATE NOT NULL,
+    remaining_principal BIGINT NOT NULL,
+    accrued_interest BIGINT NOT NULL,
+    discount BIGINT NOT NULL,
+    penalty BIGINT NOT NULL,
+    fee BIGINT NOT NULL,
+    total BIGINT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_settlements_contract (contract_number),
+    FOREIGN KEY (contract_number) REFERENCES transactions(contract_number),
+    FOREIGN KEY (payment_id) REFERENCES payments(id)
+) ENGINE=InnoDB;
@@ -0,0 +1,4 @@
+ALTER TABLE installments DROP COLUMN accrued_interest;
+DROP TABLE IF EXISTS journal_lines;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
@@ -0,0 +1,40 @@
+CREATE TABLE ledger_accounts (
+    code VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type ENUM('asset', 'liability', 'equity', 'income', 'expense') NOT NULL,
+    normal_balance ENUM('debit', 'credit') NOT NULL
+) ENGINE=InnoDB;
+
+INSERT INTO ledger_accounts (code, name, type, normal_balance) VALUES
+    ('1101', 'Kas dan Bank', 'asset', 'debit'),
+    ('1201', 'Piutang Pembiayaan', 'asset', 'debit'),
+    ('1202', 'Piutang Bunga', 'asset', 'debit'),
+    ('4101', 'Pendapatan Bunga', 'income', 'credit'),
+    ('4102', 'Pendapatan Administrasi', 'income', 'credit'),
+    ('4103', 'Pendapatan Denda', 'income', 'credit'),
+    ('4104', 'Pendapatan Biaya Pelunasan', 'income', 'credit'),
+    ('5101', 'Diskon Bunga Pelunasan', 'expense', 'debit');
+
+CREATE TABLE journal_entries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    entry_type VARCHAR(30) NOT NULL,
+    contract_number VARCHAR(50) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    posted_at DATETIME NOT NULL,
+    INDEX idx_journal_entries_contract (contract_number, posted_at),
+    INDEX idx_journal_entries_posted_at (posted_at),
+    FOREIGN KEY (contract_number) REFERENCES transactions(contract_number)
+) ENGINE=InnoDB;
+
+CREATE TABLE journal_lines (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    entry_id BIGINT NOT NULL,
+    account_code VARCHAR(10) NOT NULL,
+    debit BIGINT NOT NULL DEFAULT 0,
+    credit BIGINT NOT NULL DEFAULT 0,
+    INDEX idx_journal_lines_account (account_code),
+    FOREIGN KEY (entry_id) REFERENCES journal_entries(id),
+    FOREIGN KEY (account_code) REFERENCES ledger_accounts(code)
+) ENGINE=InnoDB;
+
+ALTER TABLE installments ADD COLUMN accrued_interest BIGINT NOT NULL DEFAULT 0 AFTER paid_interest;
@@ -0,0 +1,15 @@
+CREATE TABLE outbox_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(50) NOT NULL,
+    payload JSON NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error VARCHAR(500) NULL,
+    published_at DATETIME NULL,
+    UNIQUE KEY uq_outbox_events_event_id (event_id),
+    INDEX idx_outbox_events_pending (published_at, next_attempt_at),
+    INDEX idx_outbox_events_aggregate (aggregate_id, published_at)
+) ENGINE=InnoDB;
@@ -0,0 +1,29 @@
+CREATE TABLE webhook_subscriptions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    partner VARCHAR(100) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types VARCHAR(500) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_webhook_subscriptions_partner (partner)
+) ENGINE=InnoDB;
+
+CREATE TABLE webhook_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSON NOT NULL,
+    status ENUM('pending', 'delivered', 'failed') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_status_code INT NULL,
+    last_error VARCHAR(500) NULL,
+    created_at DATETIME NOT NULL,
+    delivered_at DATETIME NULL,
+    UNIQUE KEY uq_webhook_deliveries_event (subscription_id, event_id),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id)
+) ENGINE=InnoDB;
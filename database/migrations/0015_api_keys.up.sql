@@ -0,0 +1,12 @@
+CREATE TABLE api_keys (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    partner VARCHAR(100) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME NULL,
+    revoked_at DATETIME NULL,
+    UNIQUE KEY uq_api_keys_hash (key_hash),
+    INDEX idx_api_keys_partner (partner)
+) ENGINE=InnoDB;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    ADD COLUMN channel VARCHAR(100) NOT NULL DEFAULT '' AFTER tenor,
+    ADD INDEX idx_transactions_channel (channel);
@@ -0,0 +1,21 @@
+CREATE TABLE audit_log (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(150) NOT NULL,
+    request_id VARCHAR(64) NULL,
+    before_data JSON NULL,
+    after_data JSON NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(6) NOT NULL,
+    INDEX idx_audit_log_entity (entity_type, entity_id, created_at),
+    INDEX idx_audit_log_created_at (created_at)
+) ENGINE=InnoDB;
+
+-- The audit log is append-only: rows can be inserted but never changed or removed.
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
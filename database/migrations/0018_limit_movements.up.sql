@@ -0,0 +1,18 @@
+CREATE TABLE limit_movements (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_nik VARCHAR(16) NOT NULL,
+    tenor INT NOT NULL,
+    delta BIGINT NOT NULL,
+    balance BIGINT NOT NULL,
+    reference_type VARCHAR(20) NOT NULL,
+    reference_id VARCHAR(50) NOT NULL DEFAULT '',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(6) NOT NULL,
+    INDEX idx_limit_movements_limit (customer_nik, tenor, id)
+) ENGINE=InnoDB;
+
+-- Open every existing limit with its current amount so the movements of a limit
+-- always sum up to its balance.
+INSERT INTO limit_movements (customer_nik, tenor, delta, balance, reference_type, created_at)
+SELECT customer_nik, tenor, limit_amount, limit_amount, 'opening', NOW(6)
+FROM customer_limits;
@@ -0,0 +1,14 @@
+CREATE TABLE limit_holds (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    customer_nik VARCHAR(16) NOT NULL,
+    tenor INT NOT NULL,
+    amount BIGINT NOT NULL,
+    channel VARCHAR(100) NOT NULL DEFAULT '',
+    status ENUM('active', 'confirmed', 'released', 'expired') NOT NULL DEFAULT 'active',
+    contract_number VARCHAR(50) NULL,
+    expires_at DATETIME(6) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    resolved_at DATETIME(6) NULL,
+    INDEX idx_limit_holds_due (status, expires_at),
+    INDEX idx_limit_holds_customer (customer_nik, tenor)
+) ENGINE=InnoDB;
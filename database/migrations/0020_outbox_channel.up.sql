@@ -0,0 +1,9 @@
+ALTER TABLE outbox_events
+    ADD COLUMN channel VARCHAR(100) NOT NULL DEFAULT '' AFTER aggregate_id;
+
+-- Contract and payment events are keyed by contract number; limit events stay
+-- without a channel.
+UPDATE outbox_events o
+JOIN transactions t ON t.contract_number = o.aggregate_id
+SET o.channel = t.channel
+WHERE o.event_type <> 'limit.changed';
@@ -0,0 +1,13 @@
+-- Collected penalties go back to being recognised as income on collection.
+UPDATE journal_lines l
+JOIN journal_entries e ON e.id = l.entry_id
+SET l.account_code = '4103'
+WHERE e.entry_type = 'penalty' AND l.account_code = '1203';
+
+DELETE l FROM journal_lines l
+JOIN journal_entries e ON e.id = l.entry_id
+WHERE e.entry_type = 'penalty_accrual';
+
+DELETE FROM journal_entries WHERE entry_type = 'penalty_accrual';
+
+DELETE FROM ledger_accounts WHERE code = '1203';
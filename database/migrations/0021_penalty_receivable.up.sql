@@ -0,0 +1,2 @@
+INSERT INTO ledger_accounts (code, name, type, normal_balance) VALUES
+    ('1203', 'Piutang Denda', 'asset', 'debit');
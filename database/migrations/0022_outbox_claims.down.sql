@@ -0,0 +1,5 @@
+ALTER TABLE outbox_events
+    DROP INDEX idx_outbox_events_failed,
+    DROP COLUMN failed_at,
+    DROP COLUMN claimed_until,
+    DROP COLUMN claimed_by;
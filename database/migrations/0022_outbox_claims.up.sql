@@ -0,0 +1,5 @@
+ALTER TABLE outbox_events
+    ADD COLUMN claimed_by VARCHAR(100) NULL AFTER next_attempt_at,
+    ADD COLUMN claimed_until DATETIME NULL AFTER claimed_by,
+    ADD COLUMN failed_at DATETIME NULL AFTER published_at,
+    ADD INDEX idx_outbox_events_failed (failed_at);
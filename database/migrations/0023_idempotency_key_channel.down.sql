@@ -0,0 +1,8 @@
+-- A key used on several channels keeps only one of its rows.
+DELETE k FROM idempotency_keys k
+JOIN idempotency_keys d ON d.idempotency_key = k.idempotency_key AND d.channel < k.channel;
+
+ALTER TABLE idempotency_keys
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (idempotency_key),
+    DROP COLUMN channel;
@@ -0,0 +1,11 @@
+-- Keys are scoped per channel so two partners can use the same key.
+ALTER TABLE idempotency_keys
+    ADD COLUMN channel VARCHAR(100) NOT NULL DEFAULT '' FIRST;
+
+UPDATE idempotency_keys k
+JOIN transactions t ON t.contract_number = k.contract_number
+SET k.channel = t.channel;
+
+ALTER TABLE idempotency_keys
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (channel, idempotency_key);
@@ -0,0 +1,2 @@
+ALTER TABLE customer_limits
+    DROP COLUMN credit_line;
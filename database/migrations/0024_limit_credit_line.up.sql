@@ -0,0 +1,23 @@
+-- limit_amount is the available balance: contracts and holds take from it and
+-- repayments give back. credit_line is the limit granted for the tenor, which
+-- set, raise and recompute change.
+ALTER TABLE customer_limits
+    ADD COLUMN credit_line BIGINT NOT NULL DEFAULT 0 AFTER tenor;
+
+-- The line is what is available plus what active contracts still owe in
+-- principal and active holds still reserve.
+UPDATE customer_limits cl
+LEFT JOIN (
+    SELECT t.customer_nik, t.tenor, SUM(i.principal - i.paid_principal) AS used
+    FROM transactions t
+    JOIN installments i ON i.contract_number = t.contract_number
+    WHERE t.status = 'active'
+    GROUP BY t.customer_nik, t.tenor
+) c ON c.customer_nik = cl.customer_nik AND c.tenor = cl.tenor
+LEFT JOIN (
+    SELECT customer_nik, tenor, SUM(amount) AS used
+    FROM limit_holds
+    WHERE status = 'active'
+    GROUP BY customer_nik, tenor
+) h ON h.customer_nik = cl.customer_nik AND h.tenor = cl.tenor
+SET cl.credit_line = cl.limit_amount + COALESCE(c.used, 0) + COALESCE(h.used, 0);
@@ -0,0 +1,2 @@
+-- The removed limit.changed subscriptions were never delivered; nothing to restore.
+DO 0;
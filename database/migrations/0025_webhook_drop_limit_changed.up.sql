@@ -0,0 +1,10 @@
+-- limit.changed carries no channel and is never delivered to partners, so it is
+-- no longer a webhook event type. Subscriptions left without any event type are
+-- deactivated.
+UPDATE webhook_subscriptions
+SET event_types = TRIM(BOTH ',' FROM REPLACE(CONCAT(',', event_types, ','), ',limit.changed,', ','))
+WHERE FIND_IN_SET('limit.changed', event_types) > 0;
+
+UPDATE webhook_subscriptions
+SET active = FALSE
+WHERE event_types = '';
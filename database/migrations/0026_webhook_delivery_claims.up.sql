@@ -0,0 +1,3 @@
+ALTER TABLE webhook_deliveries
+    ADD COLUMN claimed_by VARCHAR(100) NULL AFTER next_attempt_at,
+    ADD COLUMN claimed_until DATETIME NULL AFTER claimed_by;
@@ -0,0 +1,8 @@
+-- Instalment payments collect the penalty receivable before interest and principal.
+ALTER TABLE payments
+    ADD COLUMN penalty_paid BIGINT NOT NULL DEFAULT 0 AFTER interest_paid;
+
+-- Early settlements already collected the penalty of their quote.
+UPDATE payments p
+JOIN settlements s ON s.payment_id = p.id
+SET p.penalty_paid = s.penalty;
@@ -0,0 +1,365 @@
+// Package database holds the versioned schema migrations and optional seed data,
+// embedded into the binary so every deployment carries its own schema.
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed seeds/*.sql
+var seedFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrNotMigrated is returned by Current when migrations are pending or the
+// schema_migrations table does not exist yet.
+var ErrNotMigrated = errors.New("schema is not migrated")
+
+// legacyTables are the tables the released database/DDL.sql created. Migration
+// 0001 is that schema, so a database holding all of them is adopted by Baseline
+// instead of running 0001 again; every later change is a migration of its own.
+var legacyTables = []string{"customers", "customer_limits", "transactions"}
+
+// Migration is one schema version. Up applies it and Down reverts it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied and when.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations. Every version needs both an up and
+// a down file.
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		m := migrationName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at DATETIME NOT NULL
+		) ENGINE=InnoDB`)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, fmt.Errorf("gagal membuat tabel schema_migrations: %w", err)
+	}
+	return m.readApplied(ctx)
+}
+
+// readApplied reads schema_migrations, which must exist.
+func (m *Migrator) readApplied(ctx context.Context) (map[int64]time.Time, error) {
+	var rows []struct {
+		Version   int64     `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	if err := m.db.SelectContext(ctx, &rows, "SELECT version, applied_at FROM schema_migrations"); err != nil {
+		return nil, fmt.Errorf("gagal membaca schema_migrations: %w", err)
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r.AppliedAt
+	}
+	return applied, nil
+}
+
+// Status lists every known migration in version order with its applied time.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Current checks that every migration has been applied without writing anything,
+// so it is safe for a readiness probe running with read-only privileges. A
+// database without the schema_migrations table is not migrated.
+func (m *Migrator) Current(ctx context.Context) error {
+	var tables int
+	err := m.db.GetContext(ctx, &tables,
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'")
+	if err != nil {
+		return fmt.Errorf("gagal memeriksa tabel schema_migrations: %w", err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("%w: schema_migrations does not exist", ErrNotMigrated)
+	}
+
+	applied, err := m.readApplied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", mig.Version, mig.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending: %s", ErrNotMigrated, len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Baseline records migration 0001 as applied, without running it, when nothing
+// has been applied yet but the database was already created from the old
+// database/DDL.sql. It reports whether it did so. A database holding only some of
+// the legacy tables is not that schema and is rejected, since adopting it would
+// hide the missing tables.
+func (m *Migrator) Baseline(ctx context.Context) (bool, error) {
+	if len(m.migrations) == 0 || m.migrations[0].Version != 1 {
+		return false, nil
+	}
+	applied, err := m.applied(ctx)
+	if err != nil || len(applied) > 0 {
+		return false, err
+	}
+
+	query, args, err := sqlx.In("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name IN (?)", legacyTables)
+	if err != nil {
+		return false, err
+	}
+	var existing int
+	if err := m.db.GetContext(ctx, &existing, query, args...); err != nil {
+		return false, fmt.Errorf("gagal memeriksa skema lama: %w", err)
+	}
+	switch {
+	case existing == 0:
+		return false, nil
+	case existing < len(legacyTables):
+		return false, fmt.Errorf("database hanya berisi %d dari %d tabel DDL.sql; lengkapi skemanya sesuai migrasi 0001 sebelum menjalankan migrate up", existing, len(legacyTables))
+	}
+
+	mig := m.migrations[0]
+	if _, err := m.db.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", mig.Version, mig.Name, time.Now()); err != nil {
+		return false, fmt.Errorf("gagal mencatat migrasi %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return true, nil
+}
+
+// Up applies all pending migrations in version order and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, mig := range pending {
+		if err := m.run(ctx, mig.Up); err != nil {
+			return pending[:i], fmt.Errorf("gagal menjalankan migrasi %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if _, err := m.db.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", mig.Version, mig.Name, time.Now()); err != nil {
+			return pending[:i], fmt.Errorf("gagal mencatat migrasi %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return pending, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first, and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if err := m.run(ctx, mig.Down); err != nil {
+			return reverted, fmt.Errorf("gagal membatalkan migrasi %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if _, err := m.db.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version); err != nil {
+			return reverted, fmt.Errorf("gagal menghapus catatan migrasi %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+// Seed loads the optional demo data. Seed files use INSERT IGNORE so they can be
+// run more than once.
+func Seed(ctx context.Context, db *sqlx.DB) error {
+	entries, err := fs.ReadDir(seedFiles, "seeds")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		body, err := fs.ReadFile(seedFiles, path.Join("seeds", entry.Name()))
+		if err != nil {
+			return err
+		}
+		for _, stmt := range splitStatements(string(body)) {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("gagal menjalankan seed %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// run executes a migration script statement by statement. MySQL commits DDL
+// implicitly, so a failing script is not rolled back and has to be fixed by hand.
+func (m *Migrator) run(ctx context.Context, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a script on the current delimiter at the end of a line.
+// Like the mysql client, a "DELIMITER $$" line changes the delimiter so trigger
+// and procedure bodies can contain semicolons.
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		delimiter  = ";"
+	)
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		current.Reset()
+		if stmt != "" && !isComment(stmt) {
+			statements = append(statements, stmt)
+		}
+	}
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToUpper(trimmed), "DELIMITER ") {
+			flush()
+			delimiter = strings.TrimSpace(trimmed[len("DELIMITER "):])
+			continue
+		}
+		if strings.HasSuffix(trimmed, delimiter) {
+			current.WriteString(strings.TrimSuffix(trimmed, delimiter))
+			flush()
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+	return statements
+}
+
+func isComment(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
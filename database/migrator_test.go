@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.NotEmpty(t, splitStatements(m.Up), "migration %d up", m.Version)
+		assert.NotEmpty(t, splitStatements(m.Down), "migration %d down", m.Version)
+		if i > 0 {
+			assert.Greater(t, m.Version, migrations[i-1].Version)
+		}
+	}
+}
+
+func TestInitialMigrationIsLegacySchema(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	assert.NoError(t, err)
+
+	// Baseline adopts a database holding legacyTables as 0001, so 0001 must
+	// create exactly those tables and nothing else.
+	initial := migrations[0]
+	assert.Equal(t, int64(1), initial.Version)
+	assert.Equal(t, len(legacyTables), strings.Count(initial.Up, "CREATE TABLE"))
+	for _, table := range legacyTables {
+		assert.Contains(t, initial.Up, "CREATE TABLE "+table+" (")
+	}
+}
+
+func TestLoadMigrations_MissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	}
+
+	_, err := loadMigrations(fsys, "migrations")
+
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment only
+CREATE TABLE a (
+    id INT
+);
+INSERT INTO a VALUES (1);
+
+DELIMITER $$
+CREATE TRIGGER a_no_delete BEFORE DELETE ON a FOR EACH ROW
+BEGIN
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'append only';
+END$$
+DELIMITER ;
+DROP TABLE b;`
+
+	statements := splitStatements(script)
+
+	assert.Len(t, statements, 4)
+	assert.Contains(t, statements[0], "CREATE TABLE a")
+	assert.Equal(t, "INSERT INTO a VALUES (1)", statements[1])
+	assert.Contains(t, statements[2], "SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'append only';")
+	assert.Equal(t, "DROP TABLE b", statements[3])
+}
+
+func TestMigrator_Up_SkipsAppliedVersions(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	m := &Migrator{
+		db: sqlx.NewDb(db, "sqlmock"),
+		migrations: []Migration{
+			{Version: 1, Name: "init", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+			{Version: 2, Name: "add_b", Up: "CREATE TABLE b (id INT);", Down: "DROP TABLE b;"},
+		},
+	}
+
+	sqlMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	sqlMock.ExpectExec(regexp.QuoteMeta("CREATE TABLE b (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(2), "add_b", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	applied, err := m.Up(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestMigrator_Baseline(t *testing.T) {
+	tableQuery := regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name IN")
+	noneApplied := func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	}
+
+	tests := []struct {
+		namaTest         string
+		setupMocks       func(sqlMock sqlmock.Sqlmock)
+		adopsiDiharapkan bool
+		gagalDiharapkan  bool
+	}{
+		{
+			namaTest: "database dari DDL.sql diadopsi",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				noneApplied(sqlMock)
+				sqlMock.ExpectQuery(tableQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(legacyTables)))
+				sqlMock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(1), "initial_schema", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			adopsiDiharapkan: true,
+		},
+		{
+			namaTest: "database kosong tidak diadopsi",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				noneApplied(sqlMock)
+				sqlMock.ExpectQuery(tableQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+		},
+		{
+			namaTest: "database dengan sebagian tabel DDL.sql ditolak",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				noneApplied(sqlMock)
+				sqlMock.ExpectQuery(tableQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			gagalDiharapkan: true,
+		},
+		{
+			namaTest: "migrasi sudah pernah diterapkan",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+				sqlMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+					WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			tt.setupMocks(sqlMock)
+
+			m := &Migrator{
+				db:         sqlx.NewDb(db, "sqlmock"),
+				migrations: []Migration{{Version: 1, Name: "initial_schema"}, {Version: 2, Name: "add_b"}},
+			}
+			adopted, err := m.Baseline(context.Background())
+
+			assert.Equal(t, tt.gagalDiharapkan, err != nil, "%v", err)
+			assert.Equal(t, tt.adopsiDiharapkan, adopted)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Current(t *testing.T) {
+	tableQuery := regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.tables")
+	migrations := []Migration{
+		{Version: 1, Name: "init"},
+		{Version: 2, Name: "add_b"},
+	}
+
+	tests := []struct {
+		namaTest       string
+		setupMocks     func(sqlMock sqlmock.Sqlmock)
+		erorDiharapkan error
+	}{
+		{
+			namaTest: "tabel schema_migrations belum ada",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(tableQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			erorDiharapkan: ErrNotMigrated,
+		},
+		{
+			namaTest: "ada migrasi yang belum diterapkan",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(tableQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				sqlMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+					WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+			},
+			erorDiharapkan: ErrNotMigrated,
+		},
+		{
+			namaTest: "semua migrasi sudah diterapkan",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(tableQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				sqlMock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+					WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			tt.setupMocks(sqlMock)
+
+			m := &Migrator{db: sqlx.NewDb(db, "sqlmock"), migrations: migrations}
+			err = m.Current(context.Background())
+
+			if tt.erorDiharapkan != nil {
+				assert.ErrorIs(t, err, tt.erorDiharapkan)
+			} else {
+				assert.NoError(t, err)
+			}
+			// Any CREATE TABLE would be an unexpected exec and fail here.
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,26 @@
+INSERT IGNORE INTO customers (nik, full_name, legal_name, birth_place, birth_date, gender, salary, photo_ktp, photo_selfie)
+VALUES 
+    ('3171010101900001', 'Budi', 'Budi Santoso', 'Jakarta', '1990-01-01', 'M', 10000000, 'ktp_budi.jpg', 'selfie_budi.jpg'),
+    ('3273014505950002', 'Annisa', 'Annisa Rahma', 'Bandung', '1995-05-05', 'F', 15000000, 'ktp_annisa.jpg', 'selfie_annisa.jpg');
+
+INSERT IGNORE INTO customer_limits (customer_nik, tenor, credit_line, limit_amount)
+VALUES 
+    ('3171010101900001', 1, 100000, 100000),
+    ('3171010101900001', 2, 200000, 200000),
+    ('3171010101900001', 3, 500000, 500000),
+    ('3171010101900001', 4, 700000, 700000),
+    ('3273014505950002', 1, 1000000, 1000000),
+    ('3273014505950002', 2, 1200000, 1200000),
+    ('3273014505950002', 3, 1500000, 1500000),
+    ('3273014505950002', 4, 2000000, 2000000);
+
+-- Open each seeded limit that has no movements yet, as migration 0018 does for
+-- existing limits, so the movements of a limit always sum up to its balance.
+INSERT INTO limit_movements (customer_nik, tenor, delta, balance, reference_type, created_at)
+SELECT cl.customer_nik, cl.tenor, cl.limit_amount, cl.limit_amount, 'opening', NOW(6)
+FROM customer_limits cl
+WHERE cl.customer_nik IN ('3171010101900001', '3273014505950002')
+  AND NOT EXISTS (
+      SELECT 1 FROM limit_movements lm
+      WHERE lm.customer_nik = cl.customer_nik AND lm.tenor = cl.tenor
+  );
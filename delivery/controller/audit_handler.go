@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/audit"
+)
+
+type AuditHandler struct {
+	auditUsecase    audit.AuditUsecase
+	validateService service.ValidateService
+}
+
+func NewAuditHandler(
+	auditUsecase audit.AuditUsecase,
+	validateService service.ValidateService,
+) *AuditHandler {
+	return &AuditHandler{
+		auditUsecase:    auditUsecase,
+		validateService: validateService,
+	}
+}
+
+func (h *AuditHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/audit-logs", middleware.Require(auth.PermAuditRead), h.ListAuditLogs)
+}
+
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var req dto.ListAuditLogsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateListAuditLogsRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.auditUsecase.ListEntries(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case audit.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid cursor"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to list audit logs"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(resp))
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/usecase/audit"
+)
+
+// MockAuditUsecase is a mock implementation of AuditUsecase
+type MockAuditUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuditUsecase) ListEntries(ctx context.Context, req *dto.ListAuditLogsRequest) (*dto.ListAuditLogsResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListAuditLogsResponse), args.Error(1)
+}
+
+func setupAuditRouter(handler *AuditHandler, p *auth.Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(p))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestAuditHandler_ListAuditLogs(t *testing.T) {
+	finance := &auth.Principal{Type: auth.PrincipalStaff, ID: "fin", Roles: []string{auth.RoleFinance}}
+
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		query          string
+		setupMocks     func(u *MockAuditUsecase, v *MockValidateService)
+		expectedStatus int
+	}{
+		{
+			name:      "success",
+			principal: testStaff,
+			query:     "?entity_type=customer_limit&entity_id=3171010101900001/6",
+			setupMocks: func(u *MockAuditUsecase, v *MockValidateService) {
+				req := &dto.ListAuditLogsRequest{EntityType: "customer_limit", EntityID: "3171010101900001/6"}
+				v.On("ValidateListAuditLogsRequest", req).Return(nil)
+				u.On("ListEntries", mock.Anything, req).Return(&dto.ListAuditLogsResponse{
+					Items: []dto.AuditLogResponse{{ID: 3, EntityType: "customer_limit", Actor: "staff:budi"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "invalid cursor",
+			principal: testStaff,
+			query:     "?cursor=abc",
+			setupMocks: func(u *MockAuditUsecase, v *MockValidateService) {
+				v.On("ValidateListAuditLogsRequest", mock.Anything).Return(nil)
+				u.On("ListEntries", mock.Anything, mock.Anything).Return(nil, audit.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "finance cannot read the audit log",
+			principal:      finance,
+			setupMocks:     func(u *MockAuditUsecase, v *MockValidateService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockAuditUsecase)
+			mockValidate := new(MockValidateService)
+			tt.setupMocks(mockUsecase, mockValidate)
+
+			r := setupAuditRouter(NewAuditHandler(mockUsecase, mockValidate), tt.principal)
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/audit-logs"+tt.query, nil)
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data dto.ListAuditLogsResponse `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "staff:budi", response.Data.Items[0].Actor)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
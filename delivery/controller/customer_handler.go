@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/customer"
+)
+
+type CustomerHandler struct {
+	customerUsecase customer.CustomerUsecase
+	validateService service.ValidateService
+}
+
+func NewCustomerHandler(
+	customerUsecase customer.CustomerUsecase,
+	validateService service.ValidateService,
+) *CustomerHandler {
+	return &CustomerHandler{
+		customerUsecase: customerUsecase,
+		validateService: validateService,
+	}
+}
+
+func (h *CustomerHandler) RegisterRoutes(router *gin.RouterGroup) {
+	customerGroup := router.Group("/customers")
+	{
+		customerGroup.POST("", middleware.Require(auth.PermCustomersWrite), h.CreateCustomer)
+		customerGroup.GET("", middleware.Require(auth.PermCustomersRead), h.ListCustomers)
+		customerGroup.GET("/:nik", middleware.Require(auth.PermCustomersRead), h.GetCustomer)
+		customerGroup.PUT("/:nik", middleware.Require(auth.PermCustomersWrite), h.UpdateCustomer)
+	}
+}
+
+func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	var req dto.CreateCustomerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateCreateCustomerRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	created, err := h.customerUsecase.CreateCustomer(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case customer.ErrCustomerAlreadyExists:
+			c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Customer already exists"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to create customer"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(dto.NewCustomerResponse(created)))
+}
+
+func (h *CustomerHandler) GetCustomer(c *gin.Context) {
+	found, err := h.customerUsecase.GetCustomer(c.Request.Context(), c.Param("nik"))
+	if err != nil {
+		switch err {
+		case customer.ErrCustomerNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Customer not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to get customer"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewCustomerResponse(found)))
+}
+
+func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
+	var req dto.UpdateCustomerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateUpdateCustomerRequest(c.Param("nik"), &req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	updated, err := h.customerUsecase.UpdateCustomer(c.Request.Context(), c.Param("nik"), &req)
+	if err != nil {
+		switch err {
+		case customer.ErrCustomerNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Customer not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to update customer"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewCustomerResponse(updated)))
+}
+
+func (h *CustomerHandler) ListCustomers(c *gin.Context) {
+	var req dto.ListCustomersRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	resp, err := h.customerUsecase.ListCustomers(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to list customers"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(resp))
+}
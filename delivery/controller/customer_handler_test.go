@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	customerUsecase "multifinance/usecase/customer"
+)
+
+// MockCustomerUsecase is a mock implementation of CustomerUsecase
+type MockCustomerUsecase struct {
+	mock.Mock
+}
+
+func (m *MockCustomerUsecase) CreateCustomer(ctx context.Context, req *dto.CreateCustomerRequest) (*model.Customer, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerUsecase) GetCustomer(ctx context.Context, nik string) (*model.Customer, error) {
+	args := m.Called(ctx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerUsecase) UpdateCustomer(ctx context.Context, nik string, req *dto.UpdateCustomerRequest) (*model.Customer, error) {
+	args := m.Called(ctx, nik, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerUsecase) ListCustomers(ctx context.Context, req *dto.ListCustomersRequest) (*dto.ListCustomersResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListCustomersResponse), args.Error(1)
+}
+
+func setupCustomerRouter(handler *CustomerHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestCustomerHandler_CreateCustomer_Success(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockCustomerUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewCustomerHandler(mockUsecase, mockValidate)
+
+	req := dto.CreateCustomerRequest{
+		NIK:         "3171010101900001",
+		FullName:    "Budi",
+		LegalName:   "Budi Santoso",
+		BirthPlace:  "Jakarta",
+		BirthDate:   "1990-01-01",
+		Salary:      10000000,
+		PhotoKTP:    "ktp_budi.jpg",
+		PhotoSelfie: "selfie_budi.jpg",
+	}
+
+	mockValidate.On("ValidateCreateCustomerRequest", &req).Return(nil)
+	mockUsecase.On("CreateCustomer", mock.Anything, &req).Return(&model.Customer{
+		NIK:       req.NIK,
+		FullName:  req.FullName,
+		BirthDate: "1990-01-01T00:00:00Z",
+	}, nil)
+
+	// Execute
+	r := setupCustomerRouter(handler)
+	w := httptest.NewRecorder()
+	jsonReq, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("POST", "/api/customers", bytes.NewBuffer(jsonReq))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data dto.CustomerResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, req.NIK, response.Data.NIK)
+	assert.Equal(t, "1990-01-01", response.Data.BirthDate)
+
+	mockValidate.AssertExpectations(t)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCustomerHandler_CreateCustomer_ValidationError(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockCustomerUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewCustomerHandler(mockUsecase, mockValidate)
+
+	req := dto.CreateCustomerRequest{}
+	validationErrs := []dto.ValidationError{
+		{Field: "nik", Message: "nik is required"},
+	}
+	mockValidate.On("ValidateCreateCustomerRequest", &req).Return(dto.NewValidationError(validationErrs))
+
+	// Execute
+	r := setupCustomerRouter(handler)
+	w := httptest.NewRecorder()
+	jsonReq, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("POST", "/api/customers", bytes.NewBuffer(jsonReq))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response dto.Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Validation failed", response.Message)
+	assert.NotEmpty(t, response.Errors)
+
+	mockValidate.AssertExpectations(t)
+	mockUsecase.AssertNotCalled(t, "CreateCustomer", mock.Anything, mock.Anything)
+}
+
+func TestCustomerHandler_CreateCustomer_AlreadyExists(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockCustomerUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewCustomerHandler(mockUsecase, mockValidate)
+
+	req := dto.CreateCustomerRequest{NIK: "3171010101900001"}
+	mockValidate.On("ValidateCreateCustomerRequest", &req).Return(nil)
+	mockUsecase.On("CreateCustomer", mock.Anything, &req).Return(nil, customerUsecase.ErrCustomerAlreadyExists)
+
+	// Execute
+	r := setupCustomerRouter(handler)
+	w := httptest.NewRecorder()
+	jsonReq, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("POST", "/api/customers", bytes.NewBuffer(jsonReq))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Customer already exists", response["message"])
+}
+
+func TestCustomerHandler_GetCustomer_NotFound(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockCustomerUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewCustomerHandler(mockUsecase, mockValidate)
+
+	mockUsecase.On("GetCustomer", mock.Anything, "9999999999999999").Return(nil, customerUsecase.ErrCustomerNotFound)
+
+	// Execute
+	r := setupCustomerRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/customers/9999999999999999", nil)
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Customer not found", response["message"])
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCustomerHandler_ListCustomers_Success(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockCustomerUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewCustomerHandler(mockUsecase, mockValidate)
+
+	mockUsecase.On("ListCustomers", mock.Anything, &dto.ListCustomersRequest{Page: 2, PageSize: 10}).
+		Return(&dto.ListCustomersResponse{Items: []dto.CustomerResponse{}, Page: 2, PageSize: 10}, nil)
+
+	// Execute
+	r := setupCustomerRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/customers?page=2&page_size=10", nil)
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/delinquency"
+)
+
+type DelinquencyHandler struct {
+	delinquencyUsecase delinquency.DelinquencyUsecase
+}
+
+func NewDelinquencyHandler(delinquencyUsecase delinquency.DelinquencyUsecase) *DelinquencyHandler {
+	return &DelinquencyHandler{
+		delinquencyUsecase: delinquencyUsecase,
+	}
+}
+
+func (h *DelinquencyHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/transactions/:contract_number/delinquency", middleware.Require(auth.PermCustomersRead, auth.PermTransactionsRead), h.GetDelinquency)
+}
+
+func (h *DelinquencyHandler) GetDelinquency(c *gin.Context) {
+	d, err := h.delinquencyUsecase.GetDelinquency(c.Request.Context(), c.Param("contract_number"))
+	if err != nil {
+		switch err {
+		case delinquency.ErrTransactionNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Transaction not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to get delinquency"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewDelinquencyResponse(d, service.CollectibilityLabel(d.Collectibility))))
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	delinquencyUsecase "multifinance/usecase/delinquency"
+)
+
+// MockDelinquencyUsecase is a mock implementation of DelinquencyUsecase
+type MockDelinquencyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockDelinquencyUsecase) GetDelinquency(ctx context.Context, contractNumber string) (*model.Delinquency, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Delinquency), args.Error(1)
+}
+
+func (m *MockDelinquencyUsecase) RefreshAll(ctx context.Context, asOf time.Time) (int, error) {
+	args := m.Called(ctx, asOf)
+	return args.Int(0), args.Error(1)
+}
+
+func setupDelinquencyRouter(handler *DelinquencyHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestDelinquencyHandler_GetDelinquency_Success(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockDelinquencyUsecase)
+	handler := NewDelinquencyHandler(mockUsecase)
+
+	mockUsecase.On("GetDelinquency", mock.Anything, "CON-1").Return(&model.Delinquency{
+		ContractNumber: "CON-1",
+		DPD:            95,
+		Collectibility: 3,
+		AsOf:           time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		MaturityDate:   time.Date(2027, 1, 10, 0, 0, 0, 0, time.UTC),
+	}, nil)
+
+	// Execute
+	r := setupDelinquencyRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/transactions/CON-1/delinquency", nil)
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.DelinquencyResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 95, response.Data.DPD)
+	assert.Equal(t, "Kurang Lancar", response.Data.CollectibilityLabel)
+	assert.Equal(t, "2026-10-16", response.Data.AsOf)
+}
+
+func TestDelinquencyHandler_GetDelinquency_NotFound(t *testing.T) {
+	mockUsecase := new(MockDelinquencyUsecase)
+	handler := NewDelinquencyHandler(mockUsecase)
+	mockUsecase.On("GetDelinquency", mock.Anything, "CON-X").Return(nil, delinquencyUsecase.ErrTransactionNotFound)
+
+	r := setupDelinquencyRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/transactions/CON-X/delinquency", nil)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/delivery/dto"
+	"multifinance/usecase/health"
+)
+
+// HealthHandler serves the liveness and readiness probes. Both are public and
+// sit outside /api/v1.
+type HealthHandler struct {
+	healthUsecase health.HealthUsecase
+}
+
+func NewHealthHandler(healthUsecase health.HealthUsecase) *HealthHandler {
+	return &HealthHandler{
+		healthUsecase: healthUsecase,
+	}
+}
+
+func (h *HealthHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/livez", h.Livez)
+	router.GET("/readyz", h.Readyz)
+}
+
+// Livez only reports that the process is serving requests. It does not check
+// dependencies, so a database outage does not get the process restarted.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.HealthResponse{Status: dto.HealthStatusOK})
+}
+
+// Readyz answers 503 when any dependency check fails or the server is shutting
+// down, so load balancers stop sending traffic to this instance.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.healthUsecase.Readiness(c.Request.Context())
+
+	code := http.StatusOK
+	if !report.Ready() {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, dto.NewHealthResponse(report))
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+)
+
+type MockHealthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockHealthUsecase) Readiness(ctx context.Context) model.HealthReport {
+	args := m.Called(ctx)
+	return args.Get(0).(model.HealthReport)
+}
+
+func (m *MockHealthUsecase) Drain() {
+	m.Called()
+}
+
+func setupHealthRouter(handler *HealthHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	handler.RegisterRoutes(&r.RouterGroup)
+	return r
+}
+
+func TestHealthHandler_Livez(t *testing.T) {
+	mockUsecase := new(MockHealthUsecase)
+	r := setupHealthRouter(NewHealthHandler(mockUsecase))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/livez", nil)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	mockUsecase.AssertNotCalled(t, "Readiness", mock.Anything)
+}
+
+func TestHealthHandler_Readyz(t *testing.T) {
+	tests := []struct {
+		name         string
+		report       model.HealthReport
+		expectedCode int
+		expected     dto.HealthResponse
+	}{
+		{
+			name: "ready",
+			report: model.HealthReport{Checks: []model.HealthCheck{
+				{Name: "database", Duration: 3 * time.Millisecond},
+				{Name: "migrations"},
+			}},
+			expectedCode: http.StatusOK,
+			expected: dto.HealthResponse{Status: "ok", Checks: []dto.HealthCheckResponse{
+				{Name: "database", Status: "ok", DurationMs: 3},
+				{Name: "migrations", Status: "ok"},
+			}},
+		},
+		{
+			name: "database down",
+			report: model.HealthReport{Checks: []model.HealthCheck{
+				{Name: "database", Err: errors.New("connection refused")},
+				{Name: "migrations"},
+			}},
+			expectedCode: http.StatusServiceUnavailable,
+			expected: dto.HealthResponse{Status: "fail", Checks: []dto.HealthCheckResponse{
+				{Name: "database", Status: "fail"},
+				{Name: "migrations", Status: "ok"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockHealthUsecase)
+			mockUsecase.On("Readiness", mock.Anything).Return(tt.report)
+			r := setupHealthRouter(NewHealthHandler(mockUsecase))
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/readyz", nil)
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			var response dto.HealthResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expected, response)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
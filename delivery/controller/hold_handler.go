@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/transaction"
+)
+
+// HoldHandler serves limit holds, the reservation step of a two-phase partner
+// checkout. A confirmed hold becomes a contract, so it uses the transaction
+// permissions.
+type HoldHandler struct {
+	transactionUsecase transaction.TransactionUsecase
+	validateService    service.ValidateService
+}
+
+func NewHoldHandler(
+	transactionUsecase transaction.TransactionUsecase,
+	validateService service.ValidateService,
+) *HoldHandler {
+	return &HoldHandler{
+		transactionUsecase: transactionUsecase,
+		validateService:    validateService,
+	}
+}
+
+func (h *HoldHandler) RegisterRoutes(router *gin.RouterGroup) {
+	holdGroup := router.Group("/limits/holds")
+	{
+		holdGroup.POST("", middleware.Require(auth.PermTransactionsWrite), h.CreateHold)
+		holdGroup.GET("/:id", middleware.Require(auth.PermTransactionsRead), h.GetHold)
+		holdGroup.POST("/:id/confirm", middleware.Require(auth.PermTransactionsWrite), h.ConfirmHold)
+		holdGroup.POST("/:id/release", middleware.Require(auth.PermTransactionsWrite), h.ReleaseHold)
+	}
+}
+
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	var req dto.CreateHoldRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateCreateHoldRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	hold, err := h.transactionUsecase.CreateHold(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create hold")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(dto.NewHoldResponse(hold)))
+}
+
+func (h *HoldHandler) GetHold(c *gin.Context) {
+	id, ok := holdID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.transactionUsecase.GetHold(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewHoldResponse(hold)))
+}
+
+func (h *HoldHandler) ConfirmHold(c *gin.Context) {
+	id, ok := holdID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ConfirmHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateConfirmHoldRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	tx, err := h.transactionUsecase.ConfirmHold(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to confirm hold")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(dto.NewTransactionResponse(tx)))
+}
+
+func (h *HoldHandler) ReleaseHold(c *gin.Context) {
+	id, ok := holdID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.transactionUsecase.ReleaseHold(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to release hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewHoldResponse(hold)))
+}
+
+// holdID parses the :id path parameter and answers 404 when it is not a hold ID.
+func holdID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Hold not found"))
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *HoldHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case transaction.ErrCustomerNotFound:
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Customer not found"))
+	case transaction.ErrHoldNotFound:
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Hold not found"))
+	case transaction.ErrLimitExceeded:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Hold amount exceeds available limit"))
+	case transaction.ErrTenorNotOffered:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Tenor is not offered"))
+	case transaction.ErrHoldAmountExceeded:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Contract amount exceeds the held amount"))
+	case transaction.ErrHoldNotActive:
+		c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Hold is not active"))
+	case transaction.ErrHoldExpired:
+		c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Hold has expired"))
+	case transaction.ErrChannelForbidden:
+		c.JSON(http.StatusForbidden, dto.NewErrorResponse(http.StatusForbidden, "Partner cannot hold limit for another channel"))
+	default:
+		if _, ok := err.(interface{ GetErrors() []dto.ValidationError }); ok {
+			respondValidationError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, fallback))
+	}
+}
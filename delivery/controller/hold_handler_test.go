@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	transactionUsecase "multifinance/usecase/transaction"
+)
+
+func setupHoldRouter(handler *HoldHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestHoldHandler_CreateHold_Success(t *testing.T) {
+	mockUsecase := new(MockTransactionUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewHoldHandler(mockUsecase, mockValidate)
+
+	req := &dto.CreateHoldRequest{CustomerNIK: "3171010101900001", Tenor: 3, Amount: 1500000}
+	mockValidate.On("ValidateCreateHoldRequest", req).Return(nil)
+	mockUsecase.On("CreateHold", mock.Anything, req).Return(&model.LimitHold{
+		ID:          7,
+		CustomerNIK: "3171010101900001",
+		Tenor:       3,
+		Amount:      1500000,
+		Status:      model.LimitHoldActive,
+		ExpiresAt:   time.Now().Add(15 * time.Minute),
+		CreatedAt:   time.Now(),
+	}, nil)
+
+	r := setupHoldRouter(handler)
+	w := httptest.NewRecorder()
+	body, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("POST", "/api/limits/holds", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data dto.HoldResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(7), response.Data.ID)
+	assert.Equal(t, model.LimitHoldActive, response.Data.Status)
+	mockValidate.AssertExpectations(t)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestHoldHandler_ConfirmHold(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		err          error
+		expectedCode int
+	}{
+		{name: "confirmed", path: "/api/limits/holds/7/confirm", expectedCode: http.StatusCreated},
+		{name: "not found", path: "/api/limits/holds/7/confirm", err: transactionUsecase.ErrHoldNotFound, expectedCode: http.StatusNotFound},
+		{name: "expired", path: "/api/limits/holds/7/confirm", err: transactionUsecase.ErrHoldExpired, expectedCode: http.StatusConflict},
+		{name: "amount exceeded", path: "/api/limits/holds/7/confirm", err: transactionUsecase.ErrHoldAmountExceeded, expectedCode: http.StatusBadRequest},
+		{name: "invalid id", path: "/api/limits/holds/abc/confirm", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockTransactionUsecase)
+			mockValidate := new(MockValidateService)
+			handler := NewHoldHandler(mockUsecase, mockValidate)
+
+			req := &dto.ConfirmHoldRequest{OTR: 1450000, AdminFee: 50000, AssetName: "Kulkas"}
+			if tt.path == "/api/limits/holds/7/confirm" {
+				mockValidate.On("ValidateConfirmHoldRequest", req).Return(nil)
+				if tt.err != nil {
+					mockUsecase.On("ConfirmHold", mock.Anything, int64(7), req).Return(nil, tt.err)
+				} else {
+					mockUsecase.On("ConfirmHold", mock.Anything, int64(7), req).Return(&model.Transaction{
+						ContractNumber: "CON-1",
+						Status:         model.TransactionStatusActive,
+					}, nil)
+				}
+			}
+
+			r := setupHoldRouter(handler)
+			w := httptest.NewRecorder()
+			body, _ := json.Marshal(req)
+			httpReq, _ := http.NewRequest("POST", tt.path, bytes.NewBuffer(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockValidate.AssertExpectations(t)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHoldHandler_ReleaseHold_NotActive(t *testing.T) {
+	mockUsecase := new(MockTransactionUsecase)
+	handler := NewHoldHandler(mockUsecase, new(MockValidateService))
+
+	mockUsecase.On("ReleaseHold", mock.Anything, int64(7)).Return(nil, transactionUsecase.ErrHoldNotActive)
+
+	r := setupHoldRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/limits/holds/7/release", nil)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
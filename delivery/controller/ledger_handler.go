@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/ledger"
+)
+
+type LedgerHandler struct {
+	ledgerUsecase   ledger.LedgerUsecase
+	validateService service.ValidateService
+}
+
+func NewLedgerHandler(
+	ledgerUsecase ledger.LedgerUsecase,
+	validateService service.ValidateService,
+) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerUsecase:   ledgerUsecase,
+		validateService: validateService,
+	}
+}
+
+func (h *LedgerHandler) RegisterRoutes(router *gin.RouterGroup) {
+	ledgerGroup := router.Group("/ledger", middleware.Require(auth.PermLedgerRead))
+	{
+		ledgerGroup.GET("/trial-balance", h.GetTrialBalance)
+	}
+}
+
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	var req dto.TrialBalanceRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateTrialBalanceRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	asOf := time.Now()
+	if req.AsOf != "" {
+		day, _ := time.ParseInLocation("2006-01-02", req.AsOf, time.Local)
+		asOf = day.AddDate(0, 0, 1)
+	}
+
+	lines, err := h.ledgerUsecase.TrialBalance(c.Request.Context(), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to get trial balance"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewTrialBalanceResponse(lines, asOf)))
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+)
+
+// MockLedgerUsecase is a mock implementation of LedgerUsecase
+type MockLedgerUsecase struct {
+	mock.Mock
+}
+
+func (m *MockLedgerUsecase) TrialBalance(ctx context.Context, asOf time.Time) ([]model.TrialBalanceLine, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TrialBalanceLine), args.Error(1)
+}
+
+func (m *MockLedgerUsecase) AccrueInterest(ctx context.Context, asOf time.Time) (int, error) {
+	args := m.Called(ctx, asOf)
+	return args.Int(0), args.Error(1)
+}
+
+func setupLedgerRouter(handler *LedgerHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestLedgerHandler_GetTrialBalance(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockLedgerUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewLedgerHandler(mockUsecase, mockValidate)
+
+	// as_of includes the whole day, so entries are taken before the next midnight.
+	endOfDay := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	mockValidate.On("ValidateTrialBalanceRequest", &dto.TrialBalanceRequest{AsOf: "2026-03-01"}).Return(nil)
+	mockUsecase.On("TrialBalance", mock.Anything, endOfDay).Return([]model.TrialBalanceLine{
+		{AccountCode: model.AccountCash, AccountName: "Kas dan Bank", AccountType: "asset", NormalBalance: "debit", Debit: 0, Credit: 10000000},
+		{AccountCode: model.AccountLoanReceivable, AccountName: "Piutang Pembiayaan", AccountType: "asset", NormalBalance: "debit", Debit: 10500000},
+		{AccountCode: model.AccountAdminFeeIncome, AccountName: "Pendapatan Administrasi", AccountType: "income", NormalBalance: "credit", Credit: 500000},
+	}, nil)
+
+	// Execute
+	r := setupLedgerRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/ledger/trial-balance?as_of=2026-03-01", nil)
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.TrialBalanceResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Data.Balanced)
+	assert.Equal(t, int64(10500000), response.Data.TotalDebit)
+	assert.Equal(t, int64(-10000000), response.Data.Accounts[0].Balance)
+	assert.Equal(t, int64(500000), response.Data.Accounts[2].Balance)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/limit"
+)
+
+type LimitHandler struct {
+	limitUsecase    limit.LimitUsecase
+	validateService service.ValidateService
+}
+
+func NewLimitHandler(
+	limitUsecase limit.LimitUsecase,
+	validateService service.ValidateService,
+) *LimitHandler {
+	return &LimitHandler{
+		limitUsecase:    limitUsecase,
+		validateService: validateService,
+	}
+}
+
+func (h *LimitHandler) RegisterRoutes(router *gin.RouterGroup) {
+	limitGroup := router.Group("/customers/:nik/limits")
+	{
+		limitGroup.GET("", middleware.Require(auth.PermLimitsRead), h.GetLimits)
+		limitGroup.PUT("", middleware.Require(auth.PermLimitsWrite), h.SetLimits)
+		limitGroup.POST("/recompute", middleware.Require(auth.PermLimitsWrite), h.RecomputeLimits)
+		limitGroup.GET("/:tenor/history", middleware.Require(auth.PermLimitsRead), h.GetLimitHistory)
+	}
+}
+
+func (h *LimitHandler) GetLimits(c *gin.Context) {
+	nik := c.Param("nik")
+
+	limits, err := h.limitUsecase.GetLimits(c.Request.Context(), nik)
+	if err != nil {
+		h.handleError(c, err, "Failed to get limits")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewCustomerLimitsResponse(nik, limits)))
+}
+
+func (h *LimitHandler) SetLimits(c *gin.Context) {
+	var req dto.SetLimitsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateSetLimitsRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	nik := c.Param("nik")
+	limits, err := h.limitUsecase.SetLimits(c.Request.Context(), nik, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to set limits")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewCustomerLimitsResponse(nik, limits)))
+}
+
+func (h *LimitHandler) RecomputeLimits(c *gin.Context) {
+	var req dto.RecomputeLimitsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateRecomputeLimitsRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	nik := c.Param("nik")
+	limits, err := h.limitUsecase.RecomputeLimits(c.Request.Context(), nik, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to recompute limits")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewCustomerLimitsResponse(nik, limits)))
+}
+
+func (h *LimitHandler) GetLimitHistory(c *gin.Context) {
+	tenor, err := strconv.Atoi(c.Param("tenor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid tenor"))
+		return
+	}
+
+	var req dto.LimitHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateLimitHistoryRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.limitUsecase.GetLimitHistory(c.Request.Context(), c.Param("nik"), tenor, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to get limit history")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(resp))
+}
+
+func (h *LimitHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case limit.ErrCustomerNotFound:
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Customer not found"))
+	case limit.ErrLimitDecrease:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Raise mode cannot lower an existing limit"))
+	case limit.ErrLimitNotFound:
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Limit not found"))
+	case limit.ErrInvalidCursor:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid cursor"))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, fallback))
+	}
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	limitUsecase "multifinance/usecase/limit"
+)
+
+// MockLimitUsecase is a mock implementation of LimitUsecase
+type MockLimitUsecase struct {
+	mock.Mock
+}
+
+func (m *MockLimitUsecase) GetLimits(ctx context.Context, nik string) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+func (m *MockLimitUsecase) SetLimits(ctx context.Context, nik string, req *dto.SetLimitsRequest) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, nik, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+func (m *MockLimitUsecase) RecomputeLimits(ctx context.Context, nik string, req *dto.RecomputeLimitsRequest) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, nik, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+func (m *MockLimitUsecase) AssignLimits(ctx context.Context, tx repo.DBTx, customer *model.Customer, changedBy, reason string) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, tx, customer, changedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+func (m *MockLimitUsecase) GetLimitHistory(ctx context.Context, nik string, tenor int, req *dto.LimitHistoryRequest) (*dto.LimitHistoryResponse, error) {
+	args := m.Called(ctx, nik, tenor, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LimitHistoryResponse), args.Error(1)
+}
+
+func setupLimitRouter(handler *LimitHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestLimitHandler_GetLimits_Success(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockLimitUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewLimitHandler(mockUsecase, mockValidate)
+
+	mockUsecase.On("GetLimits", mock.Anything, "3171010101900001").Return([]model.CustomerLimit{
+		{CustomerNIK: "3171010101900001", Tenor: 1, LimitAmount: 100000},
+		{CustomerNIK: "3171010101900001", Tenor: 2, LimitAmount: 200000},
+	}, nil)
+
+	// Execute
+	r := setupLimitRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/customers/3171010101900001/limits", nil)
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.CustomerLimitsResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data.Limits, 2)
+	assert.Equal(t, int64(200000), response.Data.Limits[1].LimitAmount)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestLimitHandler_SetLimits_RaiseRejected(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockLimitUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewLimitHandler(mockUsecase, mockValidate)
+
+	req := dto.SetLimitsRequest{
+		Mode:      dto.LimitModeRaise,
+		Limits:    []dto.LimitItem{{Tenor: 1, LimitAmount: 50000}},
+		ChangedBy: "credit.ops",
+		Reason:    "kenaikan limit",
+	}
+	mockValidate.On("ValidateSetLimitsRequest", &req).Return(nil)
+	mockUsecase.On("SetLimits", mock.Anything, "3171010101900001", &req).Return(nil, limitUsecase.ErrLimitDecrease)
+
+	// Execute
+	r := setupLimitRouter(handler)
+	w := httptest.NewRecorder()
+	jsonReq, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("PUT", "/api/customers/3171010101900001/limits", bytes.NewBuffer(jsonReq))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockValidate.AssertExpectations(t)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestLimitHandler_GetLimitHistory(t *testing.T) {
+	tests := []struct {
+		namaTest   string
+		path       string
+		setupMocks func(mockUsecase *MockLimitUsecase, mockValidate *MockValidateService)
+		statusCode int
+	}{
+		{
+			namaTest: "berhasil",
+			path:     "/api/customers/3171010101900001/limits/3/history?limit=10",
+			setupMocks: func(mockUsecase *MockLimitUsecase, mockValidate *MockValidateService) {
+				req := &dto.LimitHistoryRequest{Limit: 10}
+				mockValidate.On("ValidateLimitHistoryRequest", req).Return(nil)
+				mockUsecase.On("GetLimitHistory", mock.Anything, "3171010101900001", 3, req).Return(&dto.LimitHistoryResponse{
+					CustomerNIK: "3171010101900001",
+					Tenor:       3,
+					Balance:     500000,
+					Items:       []dto.LimitMovementResponse{{ID: 2, Delta: 500000, Balance: 500000, ReferenceType: model.LimitMovementOpening}},
+				}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			namaTest:   "tenor tidak valid",
+			path:       "/api/customers/3171010101900001/limits/abc/history",
+			setupMocks: func(mockUsecase *MockLimitUsecase, mockValidate *MockValidateService) {},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			namaTest: "limit tenor tidak ditemukan",
+			path:     "/api/customers/3171010101900001/limits/9/history",
+			setupMocks: func(mockUsecase *MockLimitUsecase, mockValidate *MockValidateService) {
+				mockValidate.On("ValidateLimitHistoryRequest", mock.Anything).Return(nil)
+				mockUsecase.On("GetLimitHistory", mock.Anything, "3171010101900001", 9, mock.Anything).Return(nil, limitUsecase.ErrLimitNotFound)
+			},
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			mockUsecase := new(MockLimitUsecase)
+			mockValidate := new(MockValidateService)
+			tt.setupMocks(mockUsecase, mockValidate)
+			handler := NewLimitHandler(mockUsecase, mockValidate)
+
+			r := setupLimitRouter(handler)
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", tt.path, nil)
+
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.statusCode, w.Code)
+			mockValidate.AssertExpectations(t)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLimitHandler_Permissions(t *testing.T) {
+	tests := []struct {
+		name           string
+		roles          []string
+		method         string
+		expectedStatus int
+	}{
+		{"customer service can read limits", []string{auth.RoleCustomerService}, "GET", http.StatusOK},
+		{"customer service cannot set limits", []string{auth.RoleCustomerService}, "PUT", http.StatusForbidden},
+		{"admin cannot set limits", []string{auth.RoleAdmin}, "PUT", http.StatusForbidden},
+		{"credit ops can set limits", []string{auth.RoleCreditOps}, "PUT", http.StatusOK},
+		{"partner cannot read limits", []string{auth.RolePartner}, "GET", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			mockUsecase := new(MockLimitUsecase)
+			mockValidate := new(MockValidateService)
+			handler := NewLimitHandler(mockUsecase, mockValidate)
+			mockUsecase.On("GetLimits", mock.Anything, mock.Anything).Return([]model.CustomerLimit{}, nil)
+			mockValidate.On("ValidateSetLimitsRequest", mock.Anything).Return(nil)
+			mockUsecase.On("SetLimits", mock.Anything, mock.Anything, mock.Anything).Return([]model.CustomerLimit{}, nil)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			handler.RegisterRoutes(r.Group("/api", withPrincipal(&auth.Principal{Type: auth.PrincipalStaff, ID: "x", Roles: tt.roles})))
+
+			// Execute
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest(tt.method, "/api/customers/3171010101900001/limits",
+				bytes.NewBufferString(`{"mode":"set","limits":[{"tenor":1,"limit_amount":1}],"changed_by":"x","reason":"y"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, httpReq)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				var response dto.Response
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, http.StatusForbidden, response.Code)
+				mockUsecase.AssertNotCalled(t, "SetLimits", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
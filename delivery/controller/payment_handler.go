@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/payment"
+)
+
+type PaymentHandler struct {
+	paymentUsecase  payment.PaymentUsecase
+	validateService service.ValidateService
+}
+
+func NewPaymentHandler(
+	paymentUsecase payment.PaymentUsecase,
+	validateService service.ValidateService,
+) *PaymentHandler {
+	return &PaymentHandler{
+		paymentUsecase:  paymentUsecase,
+		validateService: validateService,
+	}
+}
+
+func (h *PaymentHandler) RegisterRoutes(router *gin.RouterGroup) {
+	paymentGroup := router.Group("/transactions/:contract_number/payments")
+	{
+		paymentGroup.POST("", middleware.Require(auth.PermPaymentsWrite), h.PostPayment)
+		paymentGroup.GET("", middleware.Require(auth.PermPaymentsRead), h.ListPayments)
+	}
+
+	settlementGroup := router.Group("/transactions/:contract_number/settlement")
+	{
+		settlementGroup.GET("", middleware.Require(auth.PermPaymentsRead), h.QuoteSettlement)
+		settlementGroup.POST("", middleware.Require(auth.PermPaymentsWrite), h.SettleContract)
+	}
+}
+
+func (h *PaymentHandler) PostPayment(c *gin.Context) {
+	var req dto.CreatePaymentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateCreatePaymentRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	p, err := h.paymentUsecase.PostPayment(c.Request.Context(), c.Param("contract_number"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to post payment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(dto.NewPaymentResponse(p)))
+}
+
+func (h *PaymentHandler) ListPayments(c *gin.Context) {
+	payments, err := h.paymentUsecase.ListPayments(c.Request.Context(), c.Param("contract_number"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get payments")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewPaymentsResponse(payments)))
+}
+
+func (h *PaymentHandler) QuoteSettlement(c *gin.Context) {
+	var req dto.SettlementQuoteRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateSettlementQuoteRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, _ = time.Parse("2006-01-02", req.Date)
+	}
+
+	quote, err := h.paymentUsecase.QuoteSettlement(c.Request.Context(), c.Param("contract_number"), date)
+	if err != nil {
+		h.handleError(c, err, "Failed to quote settlement")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewSettlementResponse(quote)))
+}
+
+func (h *PaymentHandler) SettleContract(c *gin.Context) {
+	var req dto.SettleContractRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateSettleContractRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	settlement, err := h.paymentUsecase.SettleContract(c.Request.Context(), c.Param("contract_number"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to settle contract")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(dto.NewSettlementResponse(settlement)))
+}
+
+func (h *PaymentHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case payment.ErrTransactionNotFound:
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Transaction not found"))
+	case payment.ErrContractNotActive:
+		c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Contract is not active"))
+	case payment.ErrOverpayment:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Payment exceeds outstanding amount"))
+	case payment.ErrSettlementDateInPast:
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Settlement date is in the past"))
+	default:
+		if _, ok := err.(interface{ GetErrors() []dto.ValidationError }); ok {
+			respondValidationError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, fallback))
+	}
+}
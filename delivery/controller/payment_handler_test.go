@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	paymentUsecase "multifinance/usecase/payment"
+)
+
+// MockPaymentUsecase is a mock implementation of PaymentUsecase
+type MockPaymentUsecase struct {
+	mock.Mock
+}
+
+func (m *MockPaymentUsecase) PostPayment(ctx context.Context, contractNumber string, req *dto.CreatePaymentRequest) (*model.Payment, error) {
+	args := m.Called(ctx, contractNumber, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUsecase) ListPayments(ctx context.Context, contractNumber string) ([]model.Payment, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUsecase) QuoteSettlement(ctx context.Context, contractNumber string, date time.Time) (*model.Settlement, error) {
+	args := m.Called(ctx, contractNumber, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Settlement), args.Error(1)
+}
+
+func (m *MockPaymentUsecase) SettleContract(ctx context.Context, contractNumber string, req *dto.SettleContractRequest) (*model.Settlement, error) {
+	args := m.Called(ctx, contractNumber, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Settlement), args.Error(1)
+}
+
+func setupPaymentRouter(handler *PaymentHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestPaymentHandler_PostPayment_Success(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockPaymentUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewPaymentHandler(mockUsecase, mockValidate)
+
+	req := dto.CreatePaymentRequest{Amount: 400000, Reference: "VA-001"}
+	mockValidate.On("ValidateCreatePaymentRequest", &req).Return(nil)
+	mockUsecase.On("PostPayment", mock.Anything, "CON-1", &req).Return(&model.Payment{
+		ID:             1,
+		ContractNumber: "CON-1",
+		Amount:         400000,
+		PrincipalPaid:  350000,
+		InterestPaid:   50000,
+		Reference:      "VA-001",
+		Allocations:    []model.PaymentAllocation{{Period: 1, Principal: 350000, Interest: 50000}},
+	}, nil)
+
+	// Execute
+	r := setupPaymentRouter(handler)
+	w := httptest.NewRecorder()
+	body, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequest("POST", "/api/transactions/CON-1/payments", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data dto.PaymentResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(350000), response.Data.LimitRestored)
+	assert.Len(t, response.Data.Allocations, 1)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPaymentHandler_PostPayment_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "contract not found", err: paymentUsecase.ErrTransactionNotFound, expectedCode: http.StatusNotFound},
+		{name: "contract closed", err: paymentUsecase.ErrContractNotActive, expectedCode: http.StatusConflict},
+		{name: "overpayment", err: paymentUsecase.ErrOverpayment, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockPaymentUsecase)
+			mockValidate := new(MockValidateService)
+			handler := NewPaymentHandler(mockUsecase, mockValidate)
+
+			mockValidate.On("ValidateCreatePaymentRequest", mock.Anything).Return(nil)
+			mockUsecase.On("PostPayment", mock.Anything, "CON-1", mock.Anything).Return(nil, tt.err)
+
+			r := setupPaymentRouter(handler)
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/transactions/CON-1/payments", bytes.NewBufferString(`{"amount":100}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestPaymentHandler_QuoteSettlement(t *testing.T) {
+	mockUsecase := new(MockPaymentUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewPaymentHandler(mockUsecase, mockValidate)
+
+	date := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	mockValidate.On("ValidateSettlementQuoteRequest", &dto.SettlementQuoteRequest{Date: "2026-11-01"}).Return(nil)
+	mockUsecase.On("QuoteSettlement", mock.Anything, "CON-1", date).Return(&model.Settlement{
+		ContractNumber:     "CON-1",
+		SettlementDate:     date,
+		RemainingPrincipal: 1000000,
+		AccruedInterest:    25000,
+		Fee:                10000,
+		Total:              1035000,
+	}, nil)
+
+	r := setupPaymentRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/transactions/CON-1/settlement?date=2026-11-01", nil)
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.SettlementResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-11-01", response.Data.SettlementDate)
+	assert.Equal(t, int64(1035000), response.Data.Total)
+	assert.Equal(t, int64(1000000), response.Data.LimitRestored)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPaymentHandler_SettleContract_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "contract not found", err: paymentUsecase.ErrTransactionNotFound, expectedCode: http.StatusNotFound},
+		{name: "contract closed", err: paymentUsecase.ErrContractNotActive, expectedCode: http.StatusConflict},
+		{
+			name:         "amount differs from quote",
+			err:          dto.NewValidationError([]dto.ValidationError{{Field: "amount", Message: "amount must equal the settlement total of 1035000"}}),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockPaymentUsecase)
+			mockValidate := new(MockValidateService)
+			handler := NewPaymentHandler(mockUsecase, mockValidate)
+
+			mockValidate.On("ValidateSettleContractRequest", mock.Anything).Return(nil)
+			mockUsecase.On("SettleContract", mock.Anything, "CON-1", mock.Anything).Return(nil, tt.err)
+
+			r := setupPaymentRouter(handler)
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/transactions/CON-1/settlement", bytes.NewBufferString(`{"amount":100}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/delivery/dto"
+)
+
+// respondValidationError writes the 422 field-error envelope for validation
+// failures and falls back to a 500 for any other error.
+func respondValidationError(c *gin.Context, err error) {
+	if vErr, ok := err.(interface{ GetErrors() []dto.ValidationError }); ok {
+		c.JSON(http.StatusUnprocessableEntity, dto.Response{
+			Code:    http.StatusUnprocessableEntity,
+			Status:  http.StatusText(http.StatusUnprocessableEntity),
+			Message: "Validation failed",
+			Errors:  vErr.GetErrors(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Internal server error"))
+}
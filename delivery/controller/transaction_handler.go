@@ -5,11 +5,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"multifinance/auth"
 	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
 	"multifinance/service"
 	"multifinance/usecase/transaction"
 )
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type TransactionHandler struct {
 	transactionUsecase transaction.TransactionUsecase
 	validateService    service.ValidateService
@@ -28,8 +32,14 @@ func NewTransactionHandler(
 func (h *TransactionHandler) RegisterRoutes(router *gin.RouterGroup) {
 	transactionGroup := router.Group("/transactions")
 	{
-		transactionGroup.POST("", h.CreateTransaction)
+		transactionGroup.POST("", middleware.Require(auth.PermTransactionsWrite), h.CreateTransaction)
+		transactionGroup.GET("/:contract_number", middleware.Require(auth.PermTransactionsRead), h.GetTransaction)
+		transactionGroup.GET("/:contract_number/schedule", middleware.Require(auth.PermTransactionsRead), h.GetSchedule)
+		transactionGroup.POST("/:contract_number/cancel", middleware.Require(auth.PermTransactionsCancel), h.CancelTransaction)
 	}
+
+	// Listing spans every channel, so it needs customer access as well.
+	router.GET("/customers/:nik/transactions", middleware.Require(auth.PermCustomersRead, auth.PermTransactionsRead), h.ListCustomerTransactions)
 }
 
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
@@ -39,18 +49,10 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
 		return
 	}
+	req.IdempotencyKey = c.GetHeader(idempotencyKeyHeader)
 
 	if err := h.validateService.ValidateTransactionRequest(&req); err != nil {
-		if vErr, ok := err.(interface{ GetErrors() []dto.ValidationError }); ok {
-			c.JSON(http.StatusUnprocessableEntity, dto.Response{
-				Code:    http.StatusUnprocessableEntity,
-				Status:  http.StatusText(http.StatusUnprocessableEntity),
-				Message: "Validation failed",
-				Errors:  vErr.GetErrors(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Internal server error"))
+		respondValidationError(c, err)
 		return
 	}
 
@@ -61,7 +63,17 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Customer not found"))
 		case transaction.ErrLimitExceeded:
 			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Transaction amount exceeds available limit"))
+		case transaction.ErrTenorNotOffered:
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Tenor is not offered"))
+		case transaction.ErrIdempotencyKeyReused:
+			c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Idempotency-Key was already used with a different request"))
+		case transaction.ErrChannelForbidden:
+			c.JSON(http.StatusForbidden, dto.NewErrorResponse(http.StatusForbidden, "Partner cannot create transactions for another channel"))
 		default:
+			if _, ok := err.(interface{ GetErrors() []dto.ValidationError }); ok {
+				respondValidationError(c, err)
+				return
+			}
 			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to create transaction"))
 		}
 		return
@@ -69,3 +81,95 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, dto.SuccessResponse(tx))
 }
+
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	tx, err := h.transactionUsecase.GetTransaction(c.Request.Context(), c.Param("contract_number"))
+	if err != nil {
+		switch err {
+		case transaction.ErrTransactionNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Transaction not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to get transaction"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewTransactionResponse(tx)))
+}
+
+func (h *TransactionHandler) GetSchedule(c *gin.Context) {
+	schedule, err := h.transactionUsecase.GetSchedule(c.Request.Context(), c.Param("contract_number"))
+	if err != nil {
+		switch err {
+		case transaction.ErrTransactionNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Transaction not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to get installment schedule"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(schedule))
+}
+
+func (h *TransactionHandler) ListCustomerTransactions(c *gin.Context) {
+	var req dto.ListTransactionsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateListTransactionsRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.transactionUsecase.ListCustomerTransactions(c.Request.Context(), c.Param("nik"), &req)
+	if err != nil {
+		switch err {
+		case transaction.ErrCustomerNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Customer not found"))
+		case transaction.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid cursor"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to list transactions"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(resp))
+}
+
+func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
+	var req dto.CancelTransactionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateCancelTransactionRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	tx, err := h.transactionUsecase.CancelTransaction(c.Request.Context(), c.Param("contract_number"), &req)
+	if err != nil {
+		switch err {
+		case transaction.ErrTransactionNotFound:
+			c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Transaction not found"))
+		case transaction.ErrContractNotActive:
+			c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Contract is not active"))
+		case transaction.ErrCancellationWindowClosed:
+			c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Cancellation window has closed"))
+		case transaction.ErrContractHasPayments:
+			c.JSON(http.StatusConflict, dto.NewErrorResponse(http.StatusConflict, "Contract already has payments"))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to cancel transaction"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewTransactionResponse(tx)))
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"multifinance/auth"
 	"multifinance/delivery/dto"
 	"multifinance/model"
 	transactionUsecase "multifinance/usecase/transaction"
@@ -32,6 +33,75 @@ func (m *MockTransactionUsecase) CreateTransaction(ctx context.Context, req *dto
 	return args.Get(0).(*model.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionUsecase) GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) ListCustomerTransactions(ctx context.Context, nik string, req *dto.ListTransactionsRequest) (*dto.ListTransactionsResponse, error) {
+	args := m.Called(ctx, nik, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListTransactionsResponse), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) GetSchedule(ctx context.Context, contractNumber string) (*dto.ScheduleResponse, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ScheduleResponse), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) CancelTransaction(ctx context.Context, contractNumber string, req *dto.CancelTransactionRequest) (*model.Transaction, error) {
+	args := m.Called(ctx, contractNumber, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) CreateHold(ctx context.Context, req *dto.CreateHoldRequest) (*model.LimitHold, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LimitHold), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) GetHold(ctx context.Context, id int64) (*model.LimitHold, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LimitHold), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) ConfirmHold(ctx context.Context, id int64, req *dto.ConfirmHoldRequest) (*model.Transaction, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) ReleaseHold(ctx context.Context, id int64) (*model.LimitHold, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LimitHold), args.Error(1)
+}
+
+func (m *MockTransactionUsecase) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
 // MockValidateService is a mock implementation of ValidateService
 type MockValidateService struct {
 	mock.Mock
@@ -42,10 +112,110 @@ func (m *MockValidateService) ValidateTransactionRequest(req *dto.CreateTransact
 	return args.Error(0)
 }
 
+func (m *MockValidateService) ValidateCreateCustomerRequest(req *dto.CreateCustomerRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateUpdateCustomerRequest(nik string, req *dto.UpdateCustomerRequest) error {
+	args := m.Called(nik, req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateSetLimitsRequest(req *dto.SetLimitsRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateCreateHoldRequest(req *dto.CreateHoldRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateConfirmHoldRequest(req *dto.ConfirmHoldRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateRecomputeLimitsRequest(req *dto.RecomputeLimitsRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateLimitHistoryRequest(req *dto.LimitHistoryRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateListTransactionsRequest(req *dto.ListTransactionsRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateCreatePaymentRequest(req *dto.CreatePaymentRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateCancelTransactionRequest(req *dto.CancelTransactionRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateSettlementQuoteRequest(req *dto.SettlementQuoteRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateSettleContractRequest(req *dto.SettleContractRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateTrialBalanceRequest(req *dto.TrialBalanceRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateCreateWebhookSubscriptionRequest(req *dto.CreateWebhookSubscriptionRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateUpdateWebhookSubscriptionRequest(req *dto.UpdateWebhookSubscriptionRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateListWebhookDeliveriesRequest(req *dto.ListWebhookDeliveriesRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockValidateService) ValidateListAuditLogsRequest(req *dto.ListAuditLogsRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+// testStaff holds every staff role so handler tests get past the permission checks.
+var testStaff = &auth.Principal{
+	Type:  auth.PrincipalStaff,
+	ID:    "tester",
+	Roles: []string{auth.RoleCustomerService, auth.RoleCreditOps, auth.RoleFinance, auth.RoleAdmin},
+}
+
+// withPrincipal stands in for the authentication middleware in handler tests.
+func withPrincipal(p *auth.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
 func setupRouter(handler *TransactionHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
-	api := r.Group("/api")
+	api := r.Group("/api", withPrincipal(testStaff))
 	handler.RegisterRoutes(api)
 	return r
 }
@@ -301,3 +471,135 @@ func TestTransactionHandler_CreateTransaction_InternalServerError(t *testing.T)
 	mockValidate.AssertExpectations(t)
 	mockUsecase.AssertExpectations(t)
 }
+
+func TestTransactionHandler_CreateTransaction_IdempotencyKeyReused(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockTransactionUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewTransactionHandler(mockUsecase, mockValidate)
+
+	// The header must reach the usecase through the request struct
+	withKey := mock.MatchedBy(func(req *dto.CreateTransactionRequest) bool {
+		return req.IdempotencyKey == "retry-1"
+	})
+	mockValidate.On("ValidateTransactionRequest", withKey).Return(nil)
+	mockUsecase.On("CreateTransaction", mock.Anything, withKey).Return(nil, transactionUsecase.ErrIdempotencyKeyReused)
+
+	// Execute
+	r := setupRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/transactions", bytes.NewBufferString(`{"customer_nik":"1234567890123456","otr":1000000}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", "retry-1")
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestTransactionHandler_GetTransaction_NotFound(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockTransactionUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewTransactionHandler(mockUsecase, mockValidate)
+
+	mockUsecase.On("GetTransaction", mock.Anything, "CON-404").
+		Return(nil, transactionUsecase.ErrTransactionNotFound)
+
+	// Execute
+	r := setupRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/transactions/CON-404", nil)
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Transaction not found", response["message"])
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestTransactionHandler_ListCustomerTransactions_Success(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockTransactionUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewTransactionHandler(mockUsecase, mockValidate)
+
+	req := &dto.ListTransactionsRequest{From: "2025-01-01", To: "2025-01-31", Tenor: 3, AssetName: "Laptop", Limit: 10}
+	mockValidate.On("ValidateListTransactionsRequest", req).Return(nil)
+	mockUsecase.On("ListCustomerTransactions", mock.Anything, "1234567890123456", req).
+		Return(&dto.ListTransactionsResponse{
+			Items:      []dto.TransactionResponse{{ContractNumber: "CON-1", Tenor: 3}},
+			NextCursor: "abc",
+		}, nil)
+
+	// Execute
+	r := setupRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET",
+		"/api/customers/1234567890123456/transactions?from=2025-01-01&to=2025-01-31&tenor=3&asset_name=Laptop&limit=10", nil)
+
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.ListTransactionsResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", response.Data.NextCursor)
+	assert.Len(t, response.Data.Items, 1)
+
+	mockValidate.AssertExpectations(t)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestTransactionHandler_CancelTransaction(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "cancelled", expectedCode: http.StatusOK},
+		{name: "not found", err: transactionUsecase.ErrTransactionNotFound, expectedCode: http.StatusNotFound},
+		{name: "window closed", err: transactionUsecase.ErrCancellationWindowClosed, expectedCode: http.StatusConflict},
+		{name: "has payments", err: transactionUsecase.ErrContractHasPayments, expectedCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockTransactionUsecase)
+			mockValidate := new(MockValidateService)
+			handler := NewTransactionHandler(mockUsecase, mockValidate)
+
+			req := &dto.CancelTransactionRequest{Reason: "salah input aset"}
+			mockValidate.On("ValidateCancelTransactionRequest", req).Return(nil)
+			if tt.err != nil {
+				mockUsecase.On("CancelTransaction", mock.Anything, "CON-1", req).Return(nil, tt.err)
+			} else {
+				mockUsecase.On("CancelTransaction", mock.Anything, "CON-1", req).Return(&model.Transaction{
+					ContractNumber: "CON-1",
+					Status:         model.TransactionStatusCancelled,
+				}, nil)
+			}
+
+			r := setupRouter(handler)
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/transactions/CON-1/cancel", bytes.NewBufferString(`{"reason":"salah input aset"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
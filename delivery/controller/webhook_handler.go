@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/delivery/middleware"
+	"multifinance/service"
+	"multifinance/usecase/webhook"
+)
+
+type WebhookHandler struct {
+	webhookUsecase  webhook.WebhookUsecase
+	validateService service.ValidateService
+}
+
+func NewWebhookHandler(
+	webhookUsecase webhook.WebhookUsecase,
+	validateService service.ValidateService,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase:  webhookUsecase,
+		validateService: validateService,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router *gin.RouterGroup) {
+	subscriptionGroup := router.Group("/webhooks/subscriptions")
+	{
+		subscriptionGroup.POST("", middleware.Require(auth.PermWebhooksManage), h.CreateSubscription)
+		subscriptionGroup.GET("", middleware.Require(auth.PermWebhooksRead), h.ListSubscriptions)
+		subscriptionGroup.GET("/:id", middleware.Require(auth.PermWebhooksRead), h.GetSubscription)
+		subscriptionGroup.PUT("/:id", middleware.Require(auth.PermWebhooksManage), h.UpdateSubscription)
+		subscriptionGroup.GET("/:id/deliveries", middleware.Require(auth.PermWebhooksRead), h.ListDeliveries)
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req dto.CreateWebhookSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateCreateWebhookSubscriptionRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	sub, err := h.webhookUsecase.CreateSubscription(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(dto.NewWebhookSubscriptionResponse(sub, true)))
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.webhookUsecase.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhook subscriptions")
+		return
+	}
+
+	resp := make([]dto.WebhookSubscriptionResponse, 0, len(subs))
+	for i := range subs {
+		resp = append(resp, dto.NewWebhookSubscriptionResponse(&subs[i], false))
+	}
+	c.JSON(http.StatusOK, dto.SuccessResponse(resp))
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookUsecase.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewWebhookSubscriptionResponse(sub, false)))
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateUpdateWebhookSubscriptionRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	sub, err := h.webhookUsecase.UpdateSubscription(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.NewWebhookSubscriptionResponse(sub, req.RotateSecret)))
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ListWebhookDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid request"))
+		return
+	}
+
+	if err := h.validateService.ValidateListWebhookDeliveriesRequest(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	deliveries, err := h.webhookUsecase.ListDeliveries(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhook deliveries")
+		return
+	}
+
+	resp := make([]dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		resp = append(resp, dto.NewWebhookDeliveryResponse(&deliveries[i]))
+	}
+	c.JSON(http.StatusOK, dto.SuccessResponse(resp))
+}
+
+// subscriptionID parses the :id path parameter, answering 400 when it is not a number.
+func subscriptionID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, dto.NewErrorResponse(http.StatusBadRequest, "Invalid subscription id"))
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case webhook.ErrSubscriptionNotFound:
+		c.JSON(http.StatusNotFound, dto.NewErrorResponse(http.StatusNotFound, "Webhook subscription not found"))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, fallback))
+	}
+}
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	"multifinance/service"
+	"multifinance/usecase/webhook"
+)
+
+// MockWebhookUsecase is a mock implementation of WebhookUsecase
+type MockWebhookUsecase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUsecase) CreateSubscription(ctx context.Context, req *dto.CreateWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) UpdateSubscription(ctx context.Context, id int64, req *dto.UpdateWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) ListDeliveries(ctx context.Context, id int64, req *dto.ListWebhookDeliveriesRequest) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) Publish(ctx context.Context, event service.EventEnvelope) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) DeliverPending(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func setupWebhookRouter(handler *WebhookHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	api := r.Group("/api", withPrincipal(testStaff))
+	handler.RegisterRoutes(api)
+	return r
+}
+
+func TestWebhookHandler_CreateSubscription(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockWebhookUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewWebhookHandler(mockUsecase, mockValidate)
+
+	req := dto.CreateWebhookSubscriptionRequest{
+		Partner:    "tokopedia",
+		URL:        "https://partner.example/hooks",
+		EventTypes: []string{model.EventContractCreated},
+	}
+	mockValidate.On("ValidateCreateWebhookSubscriptionRequest", &req).Return(nil)
+	mockUsecase.On("CreateSubscription", mock.Anything, &req).Return(&model.WebhookSubscription{
+		ID: 1, Partner: "tokopedia", URL: req.URL, Secret: "whsec_abc", EventTypes: "contract.created", Active: true,
+	}, nil)
+
+	// Execute
+	r := setupWebhookRouter(handler)
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/webhooks/subscriptions", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data dto.WebhookSubscriptionResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "whsec_abc", response.Data.Secret)
+	assert.Equal(t, []string{"contract.created"}, response.Data.EventTypes)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestWebhookHandler_GetSubscription(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setupMocks     func(*MockWebhookUsecase)
+		expectedStatus int
+	}{
+		{
+			name: "secret is not revealed",
+			path: "/api/webhooks/subscriptions/1",
+			setupMocks: func(m *MockWebhookUsecase) {
+				m.On("GetSubscription", mock.Anything, int64(1)).
+					Return(&model.WebhookSubscription{ID: 1, Secret: "whsec_abc", EventTypes: "contract.created"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			path: "/api/webhooks/subscriptions/2",
+			setupMocks: func(m *MockWebhookUsecase) {
+				m.On("GetSubscription", mock.Anything, int64(2)).Return(nil, webhook.ErrSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			path:           "/api/webhooks/subscriptions/abc",
+			setupMocks:     func(m *MockWebhookUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			mockUsecase := new(MockWebhookUsecase)
+			tt.setupMocks(mockUsecase)
+			handler := NewWebhookHandler(mockUsecase, new(MockValidateService))
+
+			// Execute
+			r := setupWebhookRouter(handler)
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", tt.path, nil)
+			r.ServeHTTP(w, httpReq)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), "whsec_abc")
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	// Setup
+	mockUsecase := new(MockWebhookUsecase)
+	mockValidate := new(MockValidateService)
+	handler := NewWebhookHandler(mockUsecase, mockValidate)
+
+	code := 503
+	lastError := "webhook endpoint responded 503"
+	req := &dto.ListWebhookDeliveriesRequest{Status: model.WebhookDeliveryPending}
+	mockValidate.On("ValidateListWebhookDeliveriesRequest", req).Return(nil)
+	mockUsecase.On("ListDeliveries", mock.Anything, int64(1), req).Return([]model.WebhookDelivery{{
+		ID: 7, EventID: "evt-1", EventType: model.EventPaymentReceived, Payload: []byte(`{"id":"evt-1"}`),
+		Status: model.WebhookDeliveryPending, Attempts: 2, LastStatusCode: &code, LastError: &lastError,
+	}}, nil)
+
+	// Execute
+	r := setupWebhookRouter(handler)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/webhooks/subscriptions/1/deliveries?status=pending", nil)
+	r.ServeHTTP(w, httpReq)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []dto.WebhookDeliveryResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, 503, *response.Data[0].LastStatusCode)
+	assert.NotNil(t, response.Data[0].NextAttemptAt)
+	assert.JSONEq(t, `{"id":"evt-1"}`, string(response.Data[0].Payload))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestWebhookHandler_PartnerPermissions(t *testing.T) {
+	partner := &auth.Principal{Type: auth.PrincipalPartner, ID: "12", Partner: "tokopaedi", Roles: []string{auth.RolePartner}}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		usecaseErr   error
+		expectedCode int
+	}{
+		{name: "own delivery log", method: "GET", path: "/api/webhooks/subscriptions/1/deliveries", expectedCode: http.StatusOK},
+		{name: "another partner's delivery log", method: "GET", path: "/api/webhooks/subscriptions/2/deliveries", usecaseErr: webhook.ErrSubscriptionNotFound, expectedCode: http.StatusNotFound},
+		{name: "create subscription", method: "POST", path: "/api/webhooks/subscriptions", expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockWebhookUsecase)
+			mockValidate := new(MockValidateService)
+			handler := NewWebhookHandler(mockUsecase, mockValidate)
+
+			if tt.method == "GET" {
+				mockValidate.On("ValidateListWebhookDeliveriesRequest", mock.Anything).Return(nil)
+				mockUsecase.On("ListDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]model.WebhookDelivery{}, tt.usecaseErr)
+			}
+
+			gin.SetMode(gin.TestMode)
+			r := gin.Default()
+			handler.RegisterRoutes(r.Group("/api", withPrincipal(partner)))
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest(tt.method, tt.path, nil)
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"multifinance/model"
+)
+
+// ListAuditLogsRequest represents the query of the audit log. From and To are
+// inclusive dates in YYYY-MM-DD format.
+type ListAuditLogsRequest struct {
+	EntityType string `form:"entity_type"`
+	EntityID   string `form:"entity_id"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+	Cursor     string `form:"cursor"`
+	Limit      int    `form:"limit"`
+}
+
+// AuditLogResponse represents one audit log entry. Before is null for a created entity.
+type AuditLogResponse struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	RequestID  *string         `json:"request_id,omitempty"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	Reason     string          `json:"reason"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// ListAuditLogsResponse represents a page of audit log entries. NextCursor is empty on the last page.
+type ListAuditLogsResponse struct {
+	Items      []AuditLogResponse `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// NewAuditLogResponse maps an audit log entry to its response representation
+func NewAuditLogResponse(e *model.AuditLog) AuditLogResponse {
+	resp := AuditLogResponse{
+		ID:         e.ID,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Action:     e.Action,
+		Actor:      e.Actor,
+		RequestID:  e.RequestID,
+		Before:     json.RawMessage("null"),
+		After:      json.RawMessage("null"),
+		Reason:     e.Reason,
+		CreatedAt:  e.CreatedAt,
+	}
+	if len(e.Before) > 0 {
+		resp.Before = json.RawMessage(e.Before)
+	}
+	if len(e.After) > 0 {
+		resp.After = json.RawMessage(e.After)
+	}
+	return resp
+}
@@ -0,0 +1,78 @@
+package dto
+
+import "multifinance/model"
+
+// CreateCustomerRequest represents the request payload for onboarding a customer.
+type CreateCustomerRequest struct {
+	NIK         string `json:"nik"`
+	FullName    string `json:"full_name"`
+	LegalName   string `json:"legal_name"`
+	BirthPlace  string `json:"birth_place"`
+	BirthDate   string `json:"birth_date"`
+	Gender      string `json:"gender,omitempty"`
+	Salary      int64  `json:"salary"`
+	PhotoKTP    string `json:"photo_ktp"`
+	PhotoSelfie string `json:"photo_selfie"`
+}
+
+// UpdateCustomerRequest represents the request payload for updating a customer.
+// The NIK is taken from the URL and cannot be changed.
+type UpdateCustomerRequest struct {
+	FullName    string `json:"full_name"`
+	LegalName   string `json:"legal_name"`
+	BirthPlace  string `json:"birth_place"`
+	BirthDate   string `json:"birth_date"`
+	Salary      int64  `json:"salary"`
+	PhotoKTP    string `json:"photo_ktp"`
+	PhotoSelfie string `json:"photo_selfie"`
+}
+
+// ListCustomersRequest represents the query parameters for listing customers.
+type ListCustomersRequest struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
+}
+
+// CustomerResponse represents the customer returned to the client.
+type CustomerResponse struct {
+	NIK         string `json:"nik"`
+	FullName    string `json:"full_name"`
+	LegalName   string `json:"legal_name"`
+	BirthPlace  string `json:"birth_place"`
+	BirthDate   string `json:"birth_date"`
+	Gender      string `json:"gender"`
+	Salary      int64  `json:"salary"`
+	PhotoKTP    string `json:"photo_ktp"`
+	PhotoSelfie string `json:"photo_selfie"`
+}
+
+// ListCustomersResponse represents a page of customers.
+type ListCustomersResponse struct {
+	Items    []CustomerResponse `json:"items"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// NewCustomerResponse maps a customer model to its response representation
+func NewCustomerResponse(c *model.Customer) CustomerResponse {
+	return CustomerResponse{
+		NIK:         c.NIK,
+		FullName:    c.FullName,
+		LegalName:   c.LegalName,
+		BirthPlace:  c.BirthPlace,
+		BirthDate:   formatDate(c.BirthDate),
+		Gender:      c.Gender,
+		Salary:      c.Salary,
+		PhotoKTP:    c.PhotoKTP,
+		PhotoSelfie: c.PhotoSelfie,
+	}
+}
+
+// formatDate trims a DATE column that the driver returned as a full timestamp
+// (e.g. "1990-01-01T00:00:00Z") down to "1990-01-01".
+func formatDate(s string) string {
+	if len(s) > len("2006-01-02") {
+		return s[:len("2006-01-02")]
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"multifinance/model"
+)
+
+// DelinquencyResponse represents a contract's overdue position. Dates are in YYYY-MM-DD format.
+type DelinquencyResponse struct {
+	ContractNumber      string `json:"contract_number"`
+	AsOf                string `json:"as_of"`
+	DPD                 int    `json:"dpd"`
+	OverdueInstallments int    `json:"overdue_installments"`
+	OverdueAmount       int64  `json:"overdue_amount"`
+	PenaltyAmount       int64  `json:"penalty_amount"`
+	Collectibility      int    `json:"collectibility"`
+	CollectibilityLabel string `json:"collectibility_label"`
+	NextDueDate         string `json:"next_due_date,omitempty"`
+	MaturityDate        string `json:"maturity_date"`
+}
+
+// NewDelinquencyResponse maps a delinquency model and the label of its collectibility
+// bucket to the response representation
+func NewDelinquencyResponse(d *model.Delinquency, collectibilityLabel string) DelinquencyResponse {
+	resp := DelinquencyResponse{
+		ContractNumber:      d.ContractNumber,
+		AsOf:                d.AsOf.Format("2006-01-02"),
+		DPD:                 d.DPD,
+		OverdueInstallments: d.OverdueInstallments,
+		OverdueAmount:       d.OverdueAmount,
+		PenaltyAmount:       d.PenaltyAmount,
+		Collectibility:      d.Collectibility,
+		CollectibilityLabel: collectibilityLabel,
+		MaturityDate:        d.MaturityDate.Format("2006-01-02"),
+	}
+	if d.NextDueDate != nil {
+		resp.NextDueDate = d.NextDueDate.Format("2006-01-02")
+	}
+	return resp
+}
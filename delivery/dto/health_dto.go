@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"multifinance/model"
+)
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheckResponse represents the outcome of a single readiness check. The
+// probe is public, so the reason a check failed is logged rather than returned.
+type HealthCheckResponse struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// HealthResponse is returned by the probe endpoints as is, without the standard
+// response envelope, so load balancers only need to read the status code.
+type HealthResponse struct {
+	Status string                `json:"status"`
+	Checks []HealthCheckResponse `json:"checks,omitempty"`
+}
+
+func NewHealthResponse(r model.HealthReport) HealthResponse {
+	resp := HealthResponse{Status: HealthStatusOK, Checks: make([]HealthCheckResponse, 0, len(r.Checks))}
+	if !r.Ready() {
+		resp.Status = HealthStatusFail
+	}
+
+	for _, c := range r.Checks {
+		check := HealthCheckResponse{Name: c.Name, Status: HealthStatusOK, DurationMs: c.Duration.Milliseconds()}
+		if c.Err != nil {
+			check.Status = HealthStatusFail
+		}
+		resp.Checks = append(resp.Checks, check)
+	}
+	return resp
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"multifinance/model"
+)
+
+// CreateHoldRequest represents the request payload for reserving limit during a
+// checkout. Channel defaults to the calling partner, who cannot hold for another
+// channel.
+type CreateHoldRequest struct {
+	CustomerNIK string `json:"customer_nik"`
+	Tenor       int    `json:"tenor"`
+	Amount      int64  `json:"amount"`
+	Channel     string `json:"channel"`
+}
+
+// ConfirmHoldRequest represents the contract a hold is converted into. OTR plus
+// admin fee must not exceed the held amount; the rest returns to the limit.
+// Installment and Interest are optional and checked like on CreateTransactionRequest.
+type ConfirmHoldRequest struct {
+	OTR         int64  `json:"otr"`
+	AdminFee    int64  `json:"admin_fee"`
+	Installment int64  `json:"installment"`
+	Interest    int64  `json:"interest"`
+	AssetName   string `json:"asset_name"`
+}
+
+// HoldResponse represents a limit hold returned to the client.
+type HoldResponse struct {
+	ID             int64      `json:"id"`
+	CustomerNIK    string     `json:"customer_nik"`
+	Tenor          int        `json:"tenor"`
+	Amount         int64      `json:"amount"`
+	Channel        string     `json:"channel,omitempty"`
+	Status         string     `json:"status"`
+	ContractNumber *string    `json:"contract_number,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+func NewHoldResponse(h *model.LimitHold) HoldResponse {
+	return HoldResponse{
+		ID:             h.ID,
+		CustomerNIK:    h.CustomerNIK,
+		Tenor:          h.Tenor,
+		Amount:         h.Amount,
+		Channel:        h.Channel,
+		Status:         h.Status,
+		ContractNumber: h.ContractNumber,
+		ExpiresAt:      h.ExpiresAt,
+		CreatedAt:      h.CreatedAt,
+		ResolvedAt:     h.ResolvedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"multifinance/model"
+)
+
+// TrialBalanceRequest represents the query of the trial balance. AsOf is
+// YYYY-MM-DD and includes every entry posted on that day; it defaults to now.
+type TrialBalanceRequest struct {
+	AsOf string `form:"as_of"`
+}
+
+// TrialBalanceAccountResponse represents the postings of one ledger account.
+// Balance is signed on the account's normal side.
+type TrialBalanceAccountResponse struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	NormalBalance string `json:"normal_balance"`
+	Debit         int64  `json:"debit"`
+	Credit        int64  `json:"credit"`
+	Balance       int64  `json:"balance"`
+}
+
+// TrialBalanceResponse represents the trial balance. Balanced is false when total
+// debits and credits differ, which means the ledger needs investigation.
+type TrialBalanceResponse struct {
+	AsOf        time.Time                     `json:"as_of"`
+	Accounts    []TrialBalanceAccountResponse `json:"accounts"`
+	TotalDebit  int64                         `json:"total_debit"`
+	TotalCredit int64                         `json:"total_credit"`
+	Balanced    bool                          `json:"balanced"`
+}
+
+// NewTrialBalanceResponse maps trial balance lines to their response representation
+func NewTrialBalanceResponse(lines []model.TrialBalanceLine, asOf time.Time) TrialBalanceResponse {
+	resp := TrialBalanceResponse{AsOf: asOf, Accounts: make([]TrialBalanceAccountResponse, 0, len(lines))}
+	for _, l := range lines {
+		balance := l.Debit - l.Credit
+		if l.NormalBalance == "credit" {
+			balance = -balance
+		}
+		resp.Accounts = append(resp.Accounts, TrialBalanceAccountResponse{
+			Code:          l.AccountCode,
+			Name:          l.AccountName,
+			Type:          l.AccountType,
+			NormalBalance: l.NormalBalance,
+			Debit:         l.Debit,
+			Credit:        l.Credit,
+			Balance:       balance,
+		})
+		resp.TotalDebit += l.Debit
+		resp.TotalCredit += l.Credit
+	}
+	resp.Balanced = resp.TotalDebit == resp.TotalCredit
+	return resp
+}
@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"time"
+
+	"multifinance/model"
+)
+
+const (
+	LimitModeSet   = "set"
+	LimitModeRaise = "raise"
+)
+
+// LimitItem represents the limit of a single tenor.
+type LimitItem struct {
+	Tenor       int   `json:"tenor"`
+	LimitAmount int64 `json:"limit_amount"`
+}
+
+// SetLimitsRequest represents the request payload for changing one or more tenor limits.
+// Each amount is the new credit line of its tenor. In "set" mode it replaces the
+// current line; in "raise" mode an amount lower than the current line is rejected.
+// Either way the available amount moves by the change in the line.
+type SetLimitsRequest struct {
+	Mode      string      `json:"mode"`
+	Limits    []LimitItem `json:"limits"`
+	ChangedBy string      `json:"changed_by"`
+	Reason    string      `json:"reason"`
+}
+
+// LimitResponse represents the limit of a single tenor: the granted credit line
+// and the amount still available after active contracts and holds.
+type LimitResponse struct {
+	Tenor       int   `json:"tenor"`
+	CreditLine  int64 `json:"credit_line"`
+	LimitAmount int64 `json:"limit_amount"`
+}
+
+// CustomerLimitsResponse represents all tenor limits of a customer.
+type CustomerLimitsResponse struct {
+	CustomerNIK string          `json:"customer_nik"`
+	Limits      []LimitResponse `json:"limits"`
+}
+
+// NewCustomerLimitsResponse maps the limit rows of a customer to their response representation
+func NewCustomerLimitsResponse(nik string, limits []model.CustomerLimit) CustomerLimitsResponse {
+	items := make([]LimitResponse, 0, len(limits))
+	for _, l := range limits {
+		items = append(items, LimitResponse{Tenor: l.Tenor, CreditLine: l.CreditLine, LimitAmount: l.LimitAmount})
+	}
+	return CustomerLimitsResponse{
+		CustomerNIK: nik,
+		Limits:      items,
+	}
+}
+
+// RecomputeLimitsRequest represents the request payload for re-running the limit policy.
+type RecomputeLimitsRequest struct {
+	ChangedBy string `json:"changed_by"`
+	Reason    string `json:"reason"`
+}
+
+// LimitHistoryRequest represents the query of a tenor limit's movement history.
+type LimitHistoryRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// LimitMovementResponse represents one change to a tenor limit. Delta is negative
+// when limit was used and Balance is the limit after the change.
+type LimitMovementResponse struct {
+	ID            int64     `json:"id"`
+	Delta         int64     `json:"delta"`
+	Balance       int64     `json:"balance"`
+	ReferenceType string    `json:"reference_type"`
+	ReferenceID   string    `json:"reference_id,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LimitHistoryResponse represents a page of a tenor limit's movements, newest
+// first. NextCursor is empty on the last page.
+type LimitHistoryResponse struct {
+	CustomerNIK string                  `json:"customer_nik"`
+	Tenor       int                     `json:"tenor"`
+	Balance     int64                   `json:"balance"`
+	Items       []LimitMovementResponse `json:"items"`
+	NextCursor  string                  `json:"next_cursor,omitempty"`
+}
+
+// NewLimitMovementResponse maps a limit movement to its response representation
+func NewLimitMovementResponse(m *model.LimitMovement) LimitMovementResponse {
+	return LimitMovementResponse{
+		ID:            m.ID,
+		Delta:         m.Delta,
+		Balance:       m.Balance,
+		ReferenceType: m.ReferenceType,
+		ReferenceID:   m.ReferenceID,
+		Note:          m.Note,
+		CreatedAt:     m.CreatedAt,
+	}
+}
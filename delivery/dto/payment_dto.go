@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"time"
+
+	"multifinance/model"
+)
+
+// CreatePaymentRequest represents the request payload for posting a repayment.
+type CreatePaymentRequest struct {
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+}
+
+// PaymentAllocationResponse represents the part of a payment applied to one period.
+type PaymentAllocationResponse struct {
+	Period    int   `json:"period"`
+	Principal int64 `json:"principal"`
+	Interest  int64 `json:"interest"`
+}
+
+// PaymentResponse represents a posted payment. LimitRestored is the principal
+// returned to the customer's limit for the contract tenor.
+type PaymentResponse struct {
+	ID             int64                       `json:"id"`
+	ContractNumber string                      `json:"contract_number"`
+	Amount         int64                       `json:"amount"`
+	PrincipalPaid  int64                       `json:"principal_paid"`
+	InterestPaid   int64                       `json:"interest_paid"`
+	PenaltyPaid    int64                       `json:"penalty_paid"`
+	LimitRestored  int64                       `json:"limit_restored"`
+	Reference      string                      `json:"reference,omitempty"`
+	PaidAt         time.Time                   `json:"paid_at"`
+	Allocations    []PaymentAllocationResponse `json:"allocations,omitempty"`
+}
+
+// NewPaymentResponse maps a payment model to its response representation
+func NewPaymentResponse(p *model.Payment) PaymentResponse {
+	resp := PaymentResponse{
+		ID:             p.ID,
+		ContractNumber: p.ContractNumber,
+		Amount:         p.Amount,
+		PrincipalPaid:  p.PrincipalPaid,
+		InterestPaid:   p.InterestPaid,
+		PenaltyPaid:    p.PenaltyPaid,
+		LimitRestored:  p.PrincipalPaid,
+		Reference:      p.Reference,
+		PaidAt:         p.PaidAt,
+	}
+	for _, a := range p.Allocations {
+		resp.Allocations = append(resp.Allocations, PaymentAllocationResponse{
+			Period:    a.Period,
+			Principal: a.Principal,
+			Interest:  a.Interest,
+		})
+	}
+	return resp
+}
+
+// NewPaymentsResponse maps a list of payments to their response representation
+func NewPaymentsResponse(payments []model.Payment) []PaymentResponse {
+	items := make([]PaymentResponse, 0, len(payments))
+	for i := range payments {
+		items = append(items, NewPaymentResponse(&payments[i]))
+	}
+	return items
+}
+
+// SettlementQuoteRequest represents the query of a settlement quote. Date is
+// YYYY-MM-DD and defaults to today.
+type SettlementQuoteRequest struct {
+	Date string `form:"date"`
+}
+
+// SettleContractRequest represents the request payload for paying off a contract
+// early. Amount must equal today's quoted total.
+type SettleContractRequest struct {
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+}
+
+// SettlementResponse represents a settlement quote or an executed settlement.
+// PaymentID is only set once the settlement is executed.
+type SettlementResponse struct {
+	ContractNumber     string `json:"contract_number"`
+	SettlementDate     string `json:"settlement_date"`
+	RemainingPrincipal int64  `json:"remaining_principal"`
+	AccruedInterest    int64  `json:"accrued_interest"`
+	Discount           int64  `json:"discount"`
+	Penalty            int64  `json:"penalty"`
+	Fee                int64  `json:"fee"`
+	Total              int64  `json:"total"`
+	LimitRestored      int64  `json:"limit_restored"`
+	PaymentID          int64  `json:"payment_id,omitempty"`
+}
+
+// NewSettlementResponse maps a settlement model to its response representation
+func NewSettlementResponse(s *model.Settlement) SettlementResponse {
+	return SettlementResponse{
+		ContractNumber:     s.ContractNumber,
+		SettlementDate:     s.SettlementDate.Format("2006-01-02"),
+		RemainingPrincipal: s.RemainingPrincipal,
+		AccruedInterest:    s.AccruedInterest,
+		Discount:           s.Discount,
+		Penalty:            s.Penalty,
+		Fee:                s.Fee,
+		Total:              s.Total,
+		LimitRestored:      s.RemainingPrincipal,
+		PaymentID:          s.PaymentID,
+	}
+}
@@ -2,9 +2,14 @@ package dto
 
 import (
 	"fmt"
+	"time"
+
+	"multifinance/model"
 )
 
 // CreateTransactionRequest represents the request payload for creating a transaction.
+// Installment and Interest are optional; when sent they must match the server-side pricing.
+// Channel defaults to the calling partner, who cannot book for another channel.
 type CreateTransactionRequest struct {
 	CustomerNIK string `json:"customer_nik"`
 	OTR         int64  `json:"otr"`
@@ -13,6 +18,10 @@ type CreateTransactionRequest struct {
 	Interest    int64  `json:"interest"`
 	AssetName   string `json:"asset_name"`
 	Tenor       int    `json:"tenor"`
+	Channel     string `json:"channel"`
+
+	// IdempotencyKey is taken from the Idempotency-Key header, not the body.
+	IdempotencyKey string `json:"-"`
 }
 
 // Validate is a convenience method that delegates to the validation service
@@ -46,3 +55,104 @@ func (r *CreateTransactionResponse) ToString() string {
 		r.Tenor,
 	)
 }
+
+// ListTransactionsRequest represents the query parameters for listing a customer's transactions.
+// From and To are inclusive dates in YYYY-MM-DD format.
+type ListTransactionsRequest struct {
+	From      string `form:"from"`
+	To        string `form:"to"`
+	Tenor     int    `form:"tenor"`
+	AssetName string `form:"asset_name"`
+	Cursor    string `form:"cursor"`
+	Limit     int    `form:"limit"`
+}
+
+// CancelTransactionRequest represents the request payload for cancelling a contract.
+type CancelTransactionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// TransactionResponse represents a stored transaction returned to the client.
+type TransactionResponse struct {
+	ContractNumber string     `json:"contract_number"`
+	CustomerNIK    string     `json:"customer_nik"`
+	OTR            int64      `json:"otr"`
+	AdminFee       int64      `json:"admin_fee"`
+	Installment    int64      `json:"installment"`
+	Interest       int64      `json:"interest"`
+	AssetName      string     `json:"asset_name"`
+	Tenor          int        `json:"tenor"`
+	Channel        string     `json:"channel,omitempty"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	CancelReason   *string    `json:"cancel_reason,omitempty"`
+}
+
+// ListTransactionsResponse represents a page of transactions. NextCursor is empty on the last page.
+type ListTransactionsResponse struct {
+	Items      []TransactionResponse `json:"items"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// NewTransactionResponse maps a transaction model to its response representation
+func NewTransactionResponse(t *model.Transaction) TransactionResponse {
+	return TransactionResponse{
+		ContractNumber: t.ContractNumber,
+		CustomerNIK:    t.CustomerNIK,
+		OTR:            t.OTR,
+		AdminFee:       t.AdminFee,
+		Installment:    t.Installment,
+		Interest:       t.Interest,
+		AssetName:      t.AssetName,
+		Tenor:          t.Tenor,
+		Channel:        t.Channel,
+		Status:         t.Status,
+		CreatedAt:      t.CreatedAt,
+		CancelledAt:    t.CancelledAt,
+		CancelReason:   t.CancelReason,
+	}
+}
+
+// InstallmentResponse represents one period of a repayment schedule.
+type InstallmentResponse struct {
+	Period             int    `json:"period"`
+	DueDate            string `json:"due_date"`
+	Principal          int64  `json:"principal"`
+	Interest           int64  `json:"interest"`
+	Amount             int64  `json:"amount"`
+	OutstandingBalance int64  `json:"outstanding_balance"`
+	PaidPrincipal      int64  `json:"paid_principal"`
+	PaidInterest       int64  `json:"paid_interest"`
+	Status             string `json:"status"`
+}
+
+// ScheduleResponse represents the full repayment schedule of a contract.
+type ScheduleResponse struct {
+	ContractNumber string                `json:"contract_number"`
+	Tenor          int                   `json:"tenor"`
+	Items          []InstallmentResponse `json:"items"`
+}
+
+// NewScheduleResponse maps a contract and its installments to the schedule response
+func NewScheduleResponse(t *model.Transaction, installments []model.Installment) *ScheduleResponse {
+	items := make([]InstallmentResponse, 0, len(installments))
+	for _, i := range installments {
+		items = append(items, InstallmentResponse{
+			Period:             i.Period,
+			DueDate:            i.DueDate.Format("2006-01-02"),
+			Principal:          i.Principal,
+			Interest:           i.Interest,
+			Amount:             i.Amount,
+			OutstandingBalance: i.OutstandingBalance,
+			PaidPrincipal:      i.PaidPrincipal,
+			PaidInterest:       i.PaidInterest,
+			Status:             i.Status,
+		})
+	}
+	return &ScheduleResponse{
+		ContractNumber: t.ContractNumber,
+		Tenor:          t.Tenor,
+		Items:          items,
+	}
+}
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"multifinance/model"
+)
+
+// CreateWebhookSubscriptionRequest represents the request to subscribe a partner
+// endpoint to events. A secret is generated when none is given.
+type CreateWebhookSubscriptionRequest struct {
+	Partner    string   `json:"partner"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+// UpdateWebhookSubscriptionRequest represents a partial update of a subscription.
+// Fields left empty keep their value; RotateSecret issues a new secret.
+type UpdateWebhookSubscriptionRequest struct {
+	URL          string   `json:"url"`
+	EventTypes   []string `json:"event_types"`
+	Active       *bool    `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+// ListWebhookDeliveriesRequest represents the query of a subscription's delivery log.
+type ListWebhookDeliveriesRequest struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+}
+
+// WebhookSubscriptionResponse represents a subscription. Secret is only filled
+// when it was just created or rotated.
+type WebhookSubscriptionResponse struct {
+	ID         int64     `json:"id"`
+	Partner    string    `json:"partner"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NewWebhookSubscriptionResponse maps a subscription to its response representation,
+// revealing the secret only when withSecret is set
+func NewWebhookSubscriptionResponse(s *model.WebhookSubscription, withSecret bool) WebhookSubscriptionResponse {
+	resp := WebhookSubscriptionResponse{
+		ID:         s.ID,
+		Partner:    s.Partner,
+		URL:        s.URL,
+		EventTypes: strings.Split(s.EventTypes, ","),
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+	if withSecret {
+		resp.Secret = s.Secret
+	}
+	return resp
+}
+
+// WebhookDeliveryResponse represents one delivery and the outcome of its latest attempt.
+type WebhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// NewWebhookDeliveryResponse maps a delivery to its response representation
+func NewWebhookDeliveryResponse(d *model.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		Payload:        json.RawMessage(d.Payload),
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == model.WebhookDeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/service"
+	"multifinance/usecase/apikey"
+)
+
+// HeaderAPIKey carries a partner API key.
+const HeaderAPIKey = "X-API-Key"
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// Authenticate requires either a partner API key in X-API-Key or a staff JWT in
+// "Authorization: Bearer", and stores the caller in the request context, where
+// auth.FromContext finds it. Anything else is answered with 401.
+func Authenticate(apiKeys APIKeyAuthenticator, tokens service.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var principal *auth.Principal
+		var err error
+		if key := c.GetHeader(HeaderAPIKey); key != "" {
+			principal, err = apiKeys.Authenticate(c.Request.Context(), key)
+		} else if token, ok := bearerToken(c.GetHeader("Authorization")); ok {
+			principal, err = tokens.Verify(token, time.Now())
+		} else {
+			unauthorized(c, "Missing credentials")
+			return
+		}
+
+		if errors.Is(err, apikey.ErrInvalidAPIKey) || errors.Is(err, service.ErrInvalidToken) {
+			unauthorized(c, "Invalid credentials")
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.NewErrorResponse(http.StatusInternalServerError, "Failed to authenticate"))
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="multifinance"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewErrorResponse(http.StatusUnauthorized, message))
+}
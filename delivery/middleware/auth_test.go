@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"multifinance/auth"
+	"multifinance/service"
+	"multifinance/usecase/apikey"
+)
+
+type stubAPIKeys struct {
+	principal *auth.Principal
+	err       error
+}
+
+func (s *stubAPIKeys) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	return s.principal, s.err
+}
+
+type stubTokens struct {
+	principal *auth.Principal
+	err       error
+}
+
+func (s *stubTokens) Verify(token string, now time.Time) (*auth.Principal, error) {
+	return s.principal, s.err
+}
+
+func TestAuthenticate(t *testing.T) {
+	partner := &auth.Principal{Type: auth.PrincipalPartner, ID: "12", Partner: "tokopedia"}
+	staff := &auth.Principal{Type: auth.PrincipalStaff, ID: "budi"}
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		apiKeys        *stubAPIKeys
+		tokens         *stubTokens
+		expectedStatus int
+		expectedActor  string
+	}{
+		{
+			name:           "partner API key",
+			headers:        map[string]string{HeaderAPIKey: "mfk_x"},
+			apiKeys:        &stubAPIKeys{principal: partner},
+			tokens:         &stubTokens{},
+			expectedStatus: http.StatusOK,
+			expectedActor:  "partner:tokopedia/12",
+		},
+		{
+			name:           "staff bearer token",
+			headers:        map[string]string{"Authorization": "Bearer abc.def.ghi"},
+			apiKeys:        &stubAPIKeys{},
+			tokens:         &stubTokens{principal: staff},
+			expectedStatus: http.StatusOK,
+			expectedActor:  "staff:budi",
+		},
+		{
+			name:           "no credentials",
+			apiKeys:        &stubAPIKeys{},
+			tokens:         &stubTokens{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "basic auth is not accepted",
+			headers:        map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			apiKeys:        &stubAPIKeys{},
+			tokens:         &stubTokens{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "revoked API key",
+			headers:        map[string]string{HeaderAPIKey: "mfk_x"},
+			apiKeys:        &stubAPIKeys{err: apikey.ErrInvalidAPIKey},
+			tokens:         &stubTokens{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "expired token",
+			headers:        map[string]string{"Authorization": "Bearer abc.def.ghi"},
+			apiKeys:        &stubAPIKeys{},
+			tokens:         &stubTokens{err: service.ErrInvalidToken},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "key store unavailable",
+			headers:        map[string]string{HeaderAPIKey: "mfk_x"},
+			apiKeys:        &stubAPIKeys{err: errors.New("database error")},
+			tokens:         &stubTokens{},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/api/ping", Authenticate(tt.apiKeys, tt.tokens), func(c *gin.Context) {
+				c.String(http.StatusOK, auth.FromContext(c.Request.Context()).Actor())
+			})
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/ping", nil)
+			for k, v := range tt.headers {
+				httpReq.Header.Set(k, v)
+			}
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedActor != "" {
+				assert.Equal(t, tt.expectedActor, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Body.String(), `"code":401`)
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
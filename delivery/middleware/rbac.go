@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+)
+
+// Require answers 403 unless the authenticated caller holds every permission
+// in perms. It runs after Authenticate; a request without a principal gets 401.
+func Require(perms ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c.Request.Context())
+		if principal == nil {
+			unauthorized(c, "Missing credentials")
+			return
+		}
+		for _, perm := range perms {
+			if !principal.Can(perm) {
+				c.AbortWithStatusJSON(http.StatusForbidden, dto.NewErrorResponse(http.StatusForbidden, "Missing permission "+string(perm)))
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"multifinance/auth"
+)
+
+// HeaderRequestID carries the ID of a request in both directions.
+const HeaderRequestID = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID tags every request with an ID, reusing the caller's X-Request-ID
+// when it is well-formed, echoes it in the response and stores it in the
+// request context, where auth.RequestID finds it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(HeaderRequestID, id)
+		c.Request = c.Request.WithContext(auth.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b) // nolint:errcheck
+	return hex.EncodeToString(b)
+}
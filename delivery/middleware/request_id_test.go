@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"multifinance/auth"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{name: "reuses a well-formed caller ID", header: "checkout-42", reused: true},
+		{name: "generates an ID when missing"},
+		{name: "replaces a malformed caller ID", header: "bad id\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			var seen string
+			r.GET("/", RequestID(), func(c *gin.Context) {
+				seen = auth.RequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderRequestID, tt.header)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, seen, w.Header().Get(HeaderRequestID))
+			if tt.reused {
+				assert.Equal(t, tt.header, seen)
+			} else {
+				assert.Len(t, seen, 32)
+			}
+		})
+	}
+}
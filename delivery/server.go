@@ -1,22 +1,42 @@
 package delivery
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"multifinance/config"
+	"multifinance/database"
 	"multifinance/delivery/controller"
+	"multifinance/delivery/middleware"
 	"multifinance/repository"
 	"multifinance/service"
+	"multifinance/usecase/apikey"
+	"multifinance/usecase/audit"
+	"multifinance/usecase/customer"
+	"multifinance/usecase/delinquency"
+	"multifinance/usecase/health"
+	"multifinance/usecase/ledger"
+	"multifinance/usecase/limit"
+	"multifinance/usecase/outbox"
+	"multifinance/usecase/payment"
 	"multifinance/usecase/transaction"
+	"multifinance/usecase/webhook"
+	"multifinance/worker"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
 )
 
-// Run initializes all dependencies and starts the HTTP server.
+// Run initializes all dependencies and serves HTTP until SIGINT or SIGTERM, then
+// shuts down gracefully.
 func Run() {
 	// Set Gin to release mode in production
 	// gin.SetMode(gin.ReleaseMode)
@@ -26,39 +46,122 @@ func Run() {
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer sqlxDB.Close()
-
 	log.Printf("Successfully connected to database %s on %s:%s", cfg.DBName, cfg.Host, cfg.Port)
 
 	// Initialize repositories
 	customerRepo := repository.NewCustomerRepository(sqlxDB)
 	limitRepo := repository.NewLimitRepository(sqlxDB)
 	transactionRepo := repository.NewTransactionRepository(sqlxDB)
+	installmentRepo := repository.NewInstallmentRepository(sqlxDB)
+	paymentRepo := repository.NewPaymentRepository(sqlxDB)
+	idempotencyRepo := repository.NewIdempotencyRepository(sqlxDB)
+	sequenceRepo := repository.NewSequenceRepository(sqlxDB)
+	delinquencyRepo := repository.NewDelinquencyRepository(sqlxDB)
+	settlementRepo := repository.NewSettlementRepository(sqlxDB)
+	ledgerRepo := repository.NewLedgerRepository(sqlxDB)
+	outboxRepo := repository.NewOutboxRepository(sqlxDB)
+	webhookRepo := repository.NewWebhookRepository(sqlxDB)
+	apiKeyRepo := repository.NewAPIKeyRepository(sqlxDB)
+	auditRepo := repository.NewAuditRepository(sqlxDB)
+	holdRepo := repository.NewHoldRepository(sqlxDB)
 
 	// Initialize services
 	validateService := service.NewValidateService()
+	limitPolicy := service.NewDefaultLimitPolicy(cfg.LimitPolicyConfig)
+	pricingService := service.NewPricingService(cfg.PricingConfig)
+	contractNumberGenerator := service.NewContractNumberGenerator(cfg.ContractConfig, sequenceRepo)
+	delinquencyAssessor := service.NewDelinquencyAssessor(cfg.DelinquencyConfig)
+	settlementCalculator := service.NewSettlementCalculator(cfg.SettlementConfig, delinquencyAssessor)
+	tokenVerifier, err := service.NewJWTVerifier(cfg.AuthConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize token verifier: %v", err)
+	}
+	eventSink := service.NewFileEventSink(cfg.OutboxConfig.FilePath)
+	if cfg.OutboxConfig.Sink == service.EventSinkHTTP {
+		eventSink = service.NewHTTPEventSink(cfg.OutboxConfig.HTTPURL, cfg.OutboxConfig.HTTPTimeout)
+	}
 
 	// Initialize usecase
+	limitUsecase := limit.NewLimitUsecase(sqlxDB, customerRepo, limitRepo, outboxRepo, auditRepo, limitPolicy)
+	customerUsecase := customer.NewCustomerUsecase(sqlxDB, customerRepo, limitUsecase, auditRepo)
+
 	transactionUsecase := transaction.NewTransactionUsecase(
 		sqlxDB,
 		customerRepo,
 		limitRepo,
 		transactionRepo,
+		installmentRepo,
+		pricingService,
+		idempotencyRepo,
+		contractNumberGenerator,
+		paymentRepo,
+		ledgerRepo,
+		outboxRepo,
+		auditRepo,
+		holdRepo,
+		cfg.CancellationConfig.CoolingOff,
+		cfg.HoldConfig.TTL,
+	)
+	paymentUsecase := payment.NewPaymentUsecase(sqlxDB, transactionRepo, installmentRepo, limitRepo, paymentRepo, settlementRepo, ledgerRepo, outboxRepo, auditRepo, settlementCalculator, delinquencyAssessor)
+	delinquencyUsecase := delinquency.NewDelinquencyUsecase(sqlxDB, transactionRepo, installmentRepo, delinquencyRepo, ledgerRepo, delinquencyAssessor)
+	ledgerUsecase := ledger.NewLedgerUsecase(sqlxDB, transactionRepo, installmentRepo, ledgerRepo)
+	webhookUsecase := webhook.NewWebhookUsecase(
+		sqlxDB,
+		webhookRepo,
+		service.NewWebhookClient(cfg.WebhookConfig.Timeout),
+		service.Backoff{Base: cfg.WebhookConfig.RetryBase, Max: cfg.WebhookConfig.RetryMax},
+		relayOwner(),
+		cfg.WebhookConfig.ClaimTTL,
+		cfg.WebhookConfig.MaxAttempts,
+		cfg.WebhookConfig.BatchSize,
+	)
+	apiKeyUsecase := apikey.NewAPIKeyUsecase(apiKeyRepo)
+	auditUsecase := audit.NewAuditUsecase(auditRepo)
+	// Relayed events also fan out to the partner webhook subscriptions.
+	outboxUsecase := outbox.NewOutboxUsecase(
+		sqlxDB,
+		outboxRepo,
+		service.NewMultiEventSink(eventSink, webhookUsecase),
+		service.Backoff{Base: cfg.OutboxConfig.RetryBase, Max: cfg.OutboxConfig.RetryMax},
+		relayOwner(),
+		cfg.OutboxConfig.ClaimTTL,
+		cfg.OutboxConfig.MaxAttempts,
+		cfg.OutboxConfig.BatchSize,
 	)
 
+	// Start background workers
+	workers := worker.NewManager()
+	workers.Every(cfg.DelinquencyConfig.JobInterval, delinquency.NewRefreshJob(delinquencyUsecase))
+	workers.Every(cfg.LedgerConfig.AccrualJobInterval, ledger.NewAccrualJob(ledgerUsecase))
+	workers.Every(cfg.OutboxConfig.RelayInterval, outbox.NewRelayJob(outboxUsecase))
+	workers.Every(cfg.WebhookConfig.DeliveryInterval, webhook.NewDeliveryJob(webhookUsecase))
+	workers.Every(cfg.HoldConfig.SweepInterval, transaction.NewHoldExpiryJob(transactionUsecase))
+	workers.Start(context.Background())
+
+	migrator, err := database.NewMigrator(sqlxDB)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	healthUsecase := health.NewHealthUsecase(sqlxDB, migrator, workers, cfg.HealthConfig.CheckTimeout)
+
 	// Initialize Gin router
 	router := gin.Default()
+	router.Use(middleware.RequestID())
 
 	// Configure CORS
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
+		AllowOrigins:     cfg.CORSConfig.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Idempotency-Key", middleware.HeaderAPIKey, middleware.HeaderRequestID},
+		ExposeHeaders:    []string{"Content-Length", middleware.HeaderRequestID},
+		AllowCredentials: cfg.CORSConfig.AllowCredentials,
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// Liveness and readiness probes
+	healthHandler := controller.NewHealthHandler(healthUsecase)
+	healthHandler.RegisterRoutes(&router.RouterGroup)
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -67,21 +170,132 @@ func Run() {
 		})
 	})
 
-	// API v1 routes
-	v1 := router.Group("/api/v1")
+	// API v1 routes, all of them behind a partner API key or a staff JWT
+	v1 := router.Group("/api/v1", middleware.Authenticate(apiKeyUsecase, tokenVerifier))
 	{
 		transactionHandler := controller.NewTransactionHandler(
 			transactionUsecase,
 			validateService,
 		)
 		transactionHandler.RegisterRoutes(v1)
+
+		holdHandler := controller.NewHoldHandler(
+			transactionUsecase,
+			validateService,
+		)
+		holdHandler.RegisterRoutes(v1)
+
+		limitHandler := controller.NewLimitHandler(
+			limitUsecase,
+			validateService,
+		)
+		limitHandler.RegisterRoutes(v1)
+
+		customerHandler := controller.NewCustomerHandler(
+			customerUsecase,
+			validateService,
+		)
+		customerHandler.RegisterRoutes(v1)
+
+		paymentHandler := controller.NewPaymentHandler(
+			paymentUsecase,
+			validateService,
+		)
+		paymentHandler.RegisterRoutes(v1)
+
+		delinquencyHandler := controller.NewDelinquencyHandler(delinquencyUsecase)
+		delinquencyHandler.RegisterRoutes(v1)
+
+		ledgerHandler := controller.NewLedgerHandler(
+			ledgerUsecase,
+			validateService,
+		)
+		ledgerHandler.RegisterRoutes(v1)
+
+		webhookHandler := controller.NewWebhookHandler(
+			webhookUsecase,
+			validateService,
+		)
+		webhookHandler.RegisterRoutes(v1)
+
+		auditHandler := controller.NewAuditHandler(
+			auditUsecase,
+			validateService,
+		)
+		auditHandler.RegisterRoutes(v1)
 	}
 
 	// Start the server
-	serverAddr := ":" + cfg.APIConfig.ApiPort
-	log.Printf("Server is running on http://localhost%s", serverAddr)
+	srv := &http.Server{
+		Addr:              ":" + cfg.APIConfig.ApiPort,
+		Handler:           router,
+		ReadTimeout:       cfg.APIConfig.ReadTimeout,
+		ReadHeaderTimeout: cfg.APIConfig.ReadHeaderTimeout,
+		WriteTimeout:      cfg.APIConfig.WriteTimeout,
+		IdleTimeout:       cfg.APIConfig.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server is running on http://localhost%s", srv.Addr)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	// Wait for SIGINT or SIGTERM, or for the server to fail on its own
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	var runErr error
+	select {
+	case runErr = <-serverErr:
+	case <-signals.Done():
+		// Fail readiness while still serving, so load balancers move traffic away
+		// before the listener closes.
+		healthUsecase.Drain()
+		log.Printf("Shutting down in %s, then draining in-flight requests for up to %s", cfg.APIConfig.DrainDelay, cfg.APIConfig.ShutdownTimeout)
+	}
+	// A second signal kills the process without waiting for the drain.
+	stopSignals()
 
-	if err := router.Run(serverAddr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if runErr == nil {
+		time.Sleep(cfg.APIConfig.DrainDelay)
+	}
+
+	shutdown(srv, workers, sqlxDB, cfg.APIConfig.ShutdownTimeout)
+
+	if runErr != nil {
+		log.Fatalf("Failed to start server: %v", runErr)
+	}
+}
+
+// shutdown stops accepting requests and waits for the in-flight ones, then stops
+// the background jobs, then closes the database, all within timeout. Requests
+// still running at the deadline have their connections closed, which cancels
+// their context and rolls back their database transaction.
+func shutdown(srv *http.Server, workers *worker.Manager, db *sqlx.DB, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Error - HTTP server did not drain in time: %v", err)
+		srv.Close() // nolint:errcheck
+	}
+	if err := workers.Shutdown(ctx); err != nil {
+		log.Printf("Error - background jobs did not stop in time: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		log.Printf("Error - failed to close database: %v", err)
+	}
+
+	log.Println("Server stopped")
+}
+
+// relayOwner names this process in the outbox and webhook delivery claims it
+// takes, so a stuck claim can be traced to the replica holding it.
+func relayOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
 	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
 }
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Customer represents the customer entity.
 type Customer struct {
@@ -9,30 +12,232 @@ type Customer struct {
 	LegalName   string `db:"legal_name"`
 	BirthPlace  string `db:"birth_place"`
 	BirthDate   string `db:"birth_date"`
+	Gender      string `db:"gender"`
 	Salary      int64  `db:"salary"`
 	PhotoKTP    string `db:"photo_ktp"`
 	PhotoSelfie string `db:"photo_selfie"`
 }
 
+// ParseBirthDate returns BirthDate as a time. The column is a DATE, which the driver
+// hands back as a full RFC 3339 timestamp when parseTime is enabled.
+func (c *Customer) ParseBirthDate() (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", c.BirthDate); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, c.BirthDate)
+}
+
 // CustomerLimit represents the customer's credit limit for a specific tenor.
 type CustomerLimit struct {
 	CustomerNIK string `db:"customer_nik"`
 	Tenor       int    `db:"tenor"`
-	LimitAmount int64  `db:"limit_amount"`
+	// CreditLine is the limit granted for the tenor.
+	CreditLine int64 `db:"credit_line"`
+	// LimitAmount is what is still available: the credit line less what active
+	// contracts and holds use.
+	LimitAmount int64 `db:"limit_amount"`
+}
+
+// LimitAdjustment records a manual change to a customer's tenor limit.
+type LimitAdjustment struct {
+	ID             int64     `db:"id"`
+	CustomerNIK    string    `db:"customer_nik"`
+	Tenor          int       `db:"tenor"`
+	PreviousAmount int64     `db:"previous_amount"`
+	NewAmount      int64     `db:"new_amount"`
+	ChangedBy      string    `db:"changed_by"`
+	Reason         string    `db:"reason"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+const (
+	// LimitMovementOpening carries the balance a limit had before movements were recorded.
+	LimitMovementOpening      = "opening"
+	LimitMovementAdjustment   = "adjustment"
+	LimitMovementContract     = "contract"
+	LimitMovementCancellation = "cancellation"
+	LimitMovementPayment      = "payment"
+	LimitMovementHold         = "hold"
+	// LimitMovementHoldRelease returns a held amount when the hold is released,
+	// expires or is confirmed for less than it reserved.
+	LimitMovementHoldRelease = "hold_release"
+)
+
+// LimitMovement records one change to a tenor limit. Delta is signed and Balance
+// is the limit after the change, so the latest Balance equals the limit and the
+// deltas of a limit sum up to it. ReferenceID is the contract number or payment
+// ID that caused the change.
+type LimitMovement struct {
+	ID            int64     `db:"id"`
+	CustomerNIK   string    `db:"customer_nik"`
+	Tenor         int       `db:"tenor"`
+	Delta         int64     `db:"delta"`
+	Balance       int64     `db:"balance"`
+	ReferenceType string    `db:"reference_type"`
+	ReferenceID   string    `db:"reference_id"`
+	Note          string    `db:"note"`
+	CreatedAt     time.Time `db:"created_at"`
 }
 
-// Transaction represents a financial transaction.
+// LimitHold reserves Amount of a tenor limit for a checkout in progress. The
+// amount is deducted when the hold is placed and either booked as a contract on
+// confirmation or returned to the limit on release or expiry.
+type LimitHold struct {
+	ID             int64      `db:"id"`
+	CustomerNIK    string     `db:"customer_nik"`
+	Tenor          int        `db:"tenor"`
+	Amount         int64      `db:"amount"`
+	Channel        string     `db:"channel"`
+	Status         string     `db:"status"`
+	ContractNumber *string    `db:"contract_number"`
+	ExpiresAt      time.Time  `db:"expires_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ResolvedAt     *time.Time `db:"resolved_at"`
+}
+
+const (
+	LimitHoldActive    = "active"
+	LimitHoldConfirmed = "confirmed"
+	LimitHoldReleased  = "released"
+	LimitHoldExpired   = "expired"
+)
+
+// Transaction represents a financial transaction. Channel is the partner the
+// contract was booked through and is empty for staff bookings.
 type Transaction struct {
+	ContractNumber string     `db:"contract_number"`
+	CustomerNIK    string     `db:"customer_nik"`
+	OTR            int64      `db:"otr"`
+	AdminFee       int64      `db:"admin_fee"`
+	Installment    int64      `db:"installment"`
+	Interest       int64      `db:"interest"`
+	AssetName      string     `db:"asset_name"`
+	Tenor          int        `db:"tenor"`
+	Channel        string     `db:"channel"`
+	Status         string     `db:"status"`
+	CreatedAt      time.Time  `db:"created_at"`
+	CancelledAt    *time.Time `db:"cancelled_at"`
+	CancelReason   *string    `db:"cancel_reason"`
+}
+
+const (
+	TransactionStatusActive    = "active"
+	TransactionStatusPaidOff   = "paid_off"
+	TransactionStatusCancelled = "cancelled"
+)
+
+// IdempotencyKey records the first request made with a client-supplied key and the
+// response it produced, so retries of the same request can be answered from it.
+type IdempotencyKey struct {
+	// Channel scopes the key; the same key on another channel is a different key.
+	Channel        string    `db:"channel"`
+	Key            string    `db:"idempotency_key"`
+	RequestHash    string    `db:"request_hash"`
 	ContractNumber string    `db:"contract_number"`
-	CustomerNIK    string    `db:"customer_nik"`
-	OTR            int64     `db:"otr"`
-	AdminFee       int64     `db:"admin_fee"`
-	Installment    int64     `db:"installment"`
-	Interest       int64     `db:"interest"`
-	AssetName      string    `db:"asset_name"`
+	Response       []byte    `db:"response"`
 	CreatedAt      time.Time `db:"created_at"`
 }
 
+// Installment is one period of a contract's repayment schedule. OutstandingBalance
+// is the principal still owed after this period is paid.
+type Installment struct {
+	ContractNumber     string    `db:"contract_number"`
+	Period             int       `db:"period"`
+	DueDate            time.Time `db:"due_date"`
+	Principal          int64     `db:"principal"`
+	Interest           int64     `db:"interest"`
+	Amount             int64     `db:"amount"`
+	OutstandingBalance int64     `db:"outstanding_balance"`
+	PaidPrincipal      int64     `db:"paid_principal"`
+	PaidInterest       int64     `db:"paid_interest"`
+	// AccruedInterest is the part of Interest already recognised as income.
+	AccruedInterest int64      `db:"accrued_interest"`
+	Status          string     `db:"status"`
+	PaidAt          *time.Time `db:"paid_at"`
+}
+
+const (
+	InstallmentStatusUnpaid    = "unpaid"
+	InstallmentStatusPartial   = "partial"
+	InstallmentStatusPaid      = "paid"
+	InstallmentStatusCancelled = "cancelled"
+	// InstallmentStatusSettled marks a period closed by early settlement; its
+	// remaining interest was waived.
+	InstallmentStatusSettled = "settled"
+)
+
+// Due returns what is still owed on this period.
+func (i Installment) Due() (principal, interest int64) {
+	return i.Principal - i.PaidPrincipal, i.Interest - i.PaidInterest
+}
+
+// Delinquency is a contract's overdue position as of a date. DPD counts the days
+// since the oldest unpaid due date, and Collectibility is the OJK bucket 1-5.
+type Delinquency struct {
+	ContractNumber      string     `db:"contract_number"`
+	DPD                 int        `db:"dpd"`
+	OverdueInstallments int        `db:"overdue_installments"`
+	OverdueAmount       int64      `db:"overdue_amount"`
+	PenaltyAmount       int64      `db:"penalty_amount"`
+	Collectibility      int        `db:"collectibility"`
+	NextDueDate         *time.Time `db:"next_due_date"`
+	MaturityDate        time.Time  `db:"maturity_date"`
+	AsOf                time.Time  `db:"as_of"`
+}
+
+// Settlement is an early payoff of a contract. Before execution it is a quote and
+// has no ID or PaymentID. Total is RemainingPrincipal + AccruedInterest - Discount
+// + Penalty + Fee.
+type Settlement struct {
+	ID                 int64     `db:"id"`
+	ContractNumber     string    `db:"contract_number"`
+	PaymentID          int64     `db:"payment_id"`
+	SettlementDate     time.Time `db:"settlement_date"`
+	RemainingPrincipal int64     `db:"remaining_principal"`
+	AccruedInterest    int64     `db:"accrued_interest"`
+	Discount           int64     `db:"discount"`
+	Penalty            int64     `db:"penalty"`
+	Fee                int64     `db:"fee"`
+	Total              int64     `db:"total"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+// Payment is a repayment posted against a contract, split into the principal and
+// interest it settled.
+type Payment struct {
+	ID             int64     `db:"id"`
+	ContractNumber string    `db:"contract_number"`
+	Amount         int64     `db:"amount"`
+	PrincipalPaid  int64     `db:"principal_paid"`
+	InterestPaid   int64     `db:"interest_paid"`
+	PenaltyPaid    int64     `db:"penalty_paid"`
+	Reference      string    `db:"reference"`
+	PaidAt         time.Time `db:"paid_at"`
+
+	Allocations []PaymentAllocation `db:"-"`
+}
+
+// PaymentAllocation is the part of a payment applied to one schedule period.
+type PaymentAllocation struct {
+	Period    int
+	Principal int64
+	Interest  int64
+}
+
+// TransactionFilter narrows down a customer's transaction list. Results are ordered
+// newest first; when AfterContractNumber is set, only rows that sort after
+// (AfterCreatedAt, AfterContractNumber) in that order are returned.
+type TransactionFilter struct {
+	CustomerNIK         string
+	From                *time.Time
+	To                  *time.Time
+	Tenor               int
+	AssetName           string
+	AfterCreatedAt      time.Time
+	AfterContractNumber string
+	Limit               int
+}
+
 // CreateTransactionDTO represents the data transfer object for creating a transaction.
 type CreateTransactionDTO struct {
 	CustomerNIK string `json:"customer_nik"`
@@ -43,3 +248,217 @@ type CreateTransactionDTO struct {
 	AssetName   string `json:"asset_name"`
 	Tenor       int    `json:"tenor"`
 }
+
+// Ledger account codes of the chart of accounts seeded by the ledger migration.
+const (
+	AccountCash                = "1101"
+	AccountLoanReceivable      = "1201"
+	AccountInterestReceivable  = "1202"
+	AccountPenaltyReceivable   = "1203"
+	AccountInterestIncome      = "4101"
+	AccountAdminFeeIncome      = "4102"
+	AccountPenaltyIncome       = "4103"
+	AccountSettlementFeeIncome = "4104"
+	AccountInterestRebate      = "5101"
+)
+
+const (
+	JournalEntryDisbursement    = "disbursement"
+	JournalEntryAdminFee        = "admin_fee"
+	JournalEntryInterestAccrual = "interest_accrual"
+	JournalEntryRepayment       = "repayment"
+	JournalEntryPenalty         = "penalty"
+	JournalEntryPenaltyAccrual  = "penalty_accrual"
+	JournalEntrySettlementFee   = "settlement_fee"
+	JournalEntryInterestRebate  = "interest_rebate"
+	JournalEntryCancellation    = "cancellation"
+)
+
+// JournalEntry is one balanced posting to the ledger for a contract.
+type JournalEntry struct {
+	ID             int64         `db:"id"`
+	EntryType      string        `db:"entry_type"`
+	ContractNumber string        `db:"contract_number"`
+	Reference      string        `db:"reference"`
+	PostedAt       time.Time     `db:"posted_at"`
+	Lines          []JournalLine `db:"-"`
+}
+
+// JournalLine debits or credits one account; exactly one of Debit and Credit is set.
+type JournalLine struct {
+	ID          int64  `db:"id"`
+	EntryID     int64  `db:"entry_id"`
+	AccountCode string `db:"account_code"`
+	Debit       int64  `db:"debit"`
+	Credit      int64  `db:"credit"`
+}
+
+// Balanced reports whether the entry has lines and its debits equal its credits.
+func (e *JournalEntry) Balanced() bool {
+	var debit, credit int64
+	for _, l := range e.Lines {
+		debit += l.Debit
+		credit += l.Credit
+	}
+	return len(e.Lines) > 0 && debit == credit
+}
+
+// TrialBalanceLine is the total debits and credits posted to one account.
+type TrialBalanceLine struct {
+	AccountCode   string `db:"account_code"`
+	AccountName   string `db:"account_name"`
+	AccountType   string `db:"account_type"`
+	NormalBalance string `db:"normal_balance"`
+	Debit         int64  `db:"debit"`
+	Credit        int64  `db:"credit"`
+}
+
+const (
+	EventContractCreated   = "contract.created"
+	EventContractCancelled = "contract.cancelled"
+	EventContractPaidOff   = "contract.paid_off"
+	EventPaymentReceived   = "payment.received"
+	EventLimitChanged      = "limit.changed"
+)
+
+// OutboxEvent is a domain event stored in the same database transaction as the
+// change it describes, waiting to be relayed to the event sink. Channel is the
+// partner whose contract the event concerns, empty for events of no partner.
+type OutboxEvent struct {
+	ID            int64     `db:"id"`
+	EventID       string    `db:"event_id"`
+	EventType     string    `db:"event_type"`
+	AggregateID   string    `db:"aggregate_id"`
+	Channel       string    `db:"channel"`
+	Payload       []byte    `db:"payload"`
+	OccurredAt    time.Time `db:"occurred_at"`
+	Attempts      int       `db:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	// ClaimedBy and ClaimedUntil lease the event to one relay while it publishes.
+	ClaimedBy    *string    `db:"claimed_by"`
+	ClaimedUntil *time.Time `db:"claimed_until"`
+	LastError    *string    `db:"last_error"`
+	PublishedAt  *time.Time `db:"published_at"`
+	// FailedAt is set when the event ran out of attempts; it is no longer relayed.
+	FailedAt *time.Time `db:"failed_at"`
+}
+
+// WebhookSubscription is a partner endpoint that receives the events listed in
+// EventTypes, a comma-separated list.
+type WebhookSubscription struct {
+	ID         int64     `db:"id"`
+	Partner    string    `db:"partner"`
+	URL        string    `db:"url"`
+	Secret     string    `db:"secret"`
+	EventTypes string    `db:"event_types"`
+	Active     bool      `db:"active"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// Subscribes reports whether the subscription wants events of eventType.
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range strings.Split(s.EventTypes, ",") {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed marks a delivery that ran out of attempts.
+	WebhookDeliveryFailed = "failed"
+)
+
+// WebhookDelivery is one event queued for one subscription, with the outcome of
+// its latest attempt.
+type WebhookDelivery struct {
+	ID             int64     `db:"id"`
+	SubscriptionID int64     `db:"subscription_id"`
+	EventID        string    `db:"event_id"`
+	EventType      string    `db:"event_type"`
+	Payload        []byte    `db:"payload"`
+	Status         string    `db:"status"`
+	Attempts       int       `db:"attempts"`
+	NextAttemptAt  time.Time `db:"next_attempt_at"`
+	// ClaimedBy and ClaimedUntil lease the delivery to one sender while it is sent.
+	ClaimedBy      *string    `db:"claimed_by"`
+	ClaimedUntil   *time.Time `db:"claimed_until"`
+	LastStatusCode *int       `db:"last_status_code"`
+	LastError      *string    `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+}
+
+// APIKey is a credential issued to a partner system. Only the SHA-256 hash of
+// the key is stored; KeyPrefix is kept to tell keys apart.
+type APIKey struct {
+	ID         int64      `db:"id"`
+	Partner    string     `db:"partner"`
+	Name       string     `db:"name"`
+	KeyPrefix  string     `db:"key_prefix"`
+	KeyHash    string     `db:"key_hash"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+const (
+	AuditEntityCustomer      = "customer"
+	AuditEntityCustomerLimit = "customer_limit"
+	AuditEntityTransaction   = "transaction"
+
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+)
+
+// AuditLog is an append-only record of one mutation of an audited entity.
+// Before is nil for a created entity. Actor is "system" for changes made by
+// the service itself.
+type AuditLog struct {
+	ID         int64     `db:"id"`
+	EntityType string    `db:"entity_type"`
+	EntityID   string    `db:"entity_id"`
+	Action     string    `db:"action"`
+	Actor      string    `db:"actor"`
+	RequestID  *string   `db:"request_id"`
+	Before     []byte    `db:"before_data"`
+	After      []byte    `db:"after_data"`
+	Reason     string    `db:"reason"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// AuditFilter selects audit log entries. Entries newer than or equal to From and
+// older than To are returned, newest first, with an ID below BeforeID when set.
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+// HealthCheck is the outcome of one readiness check. Err is nil when it passed.
+type HealthCheck struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+type HealthReport struct {
+	Checks []HealthCheck
+}
+
+// Ready reports whether every check passed.
+func (r HealthReport) Ready() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
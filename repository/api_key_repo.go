@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// CreateAPIKey inserts a key and sets its generated ID.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, k *model.APIKey) error {
+	query := `
+		INSERT INTO api_keys (partner, name, key_prefix, key_hash, created_at)
+		VALUES (?, ?, ?, ?, ?)`
+
+	res, err := r.db.ExecContext(ctx, query, k.Partner, k.Name, k.KeyPrefix, k.KeyHash, k.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	k.ID = id
+	return nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var k model.APIKey
+	err := r.db.GetContext(ctx, &k, "SELECT * FROM api_keys WHERE key_hash = ?", keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", at, id)
+	return err
+}
+
+// RevokeAPIKey revokes a key and reports whether an active key with that ID existed.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", at, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
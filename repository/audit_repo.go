@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// CreateEntries appends audit log entries. Write them in the transaction of the
+// mutation they describe so a change is never stored without its audit trail.
+func (r *AuditRepository) CreateEntries(ctx context.Context, tx DBTx, entries []model.AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO audit_log (entity_type, entity_id, action, actor, request_id, before_data, after_data, reason, created_at)
+		VALUES (:entity_type, :entity_id, :action, :actor, :request_id, :before_data, :after_data, :reason, :created_at)`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, entries)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, entries)
+	}
+
+	return err
+}
+
+// ListEntries returns audit log entries newest first, applying the optional
+// filters and keyset cursor in f.
+func (r *AuditRepository) ListEntries(ctx context.Context, f model.AuditFilter) ([]model.AuditLog, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	if f.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, f.EntityType)
+	}
+	if f.EntityID != "" {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, f.EntityID)
+	}
+	if f.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *f.To)
+	}
+	if f.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, f.BeforeID)
+	}
+
+	query := "SELECT * FROM audit_log WHERE " + strings.Join(conditions, " AND ") + " ORDER BY id DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	entries := []model.AuditLog{}
+	err := r.db.SelectContext(ctx, &entries, query, args...)
+	return entries, err
+}
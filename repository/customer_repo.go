@@ -26,10 +26,60 @@ func (r *CustomerRepository) GetCustomer(ctx context.Context, nik string) (*mode
 	return &customer, err
 }
 
-func (r *CustomerRepository) CreateCustomer(ctx context.Context, customer *model.Customer) error {
-	_, err := r.db.NamedExecContext(ctx, `
-		INSERT INTO customers (nik, full_name, legal_name, birth_place, birth_date, salary, photo_ktp, photo_selfie)
-		VALUES (:nik, :full_name, :legal_name, :birth_place, :birth_date, :salary, :photo_ktp, :photo_selfie)
-	`, customer)
+// GetCustomerForUpdate reads a customer inside tx and locks the row until tx ends.
+func (r *CustomerRepository) GetCustomerForUpdate(ctx context.Context, tx DBTx, nik string) (*model.Customer, error) {
+	var customer model.Customer
+	err := tx.GetContext(ctx, &customer, "SELECT * FROM customers WHERE nik = ? FOR UPDATE", nik)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// CreateCustomer inserts a customer. It returns ErrDuplicateKey when a customer
+// with the same NIK already exists.
+func (r *CustomerRepository) CreateCustomer(ctx context.Context, tx DBTx, customer *model.Customer) error {
+	query := `
+		INSERT INTO customers (nik, full_name, legal_name, birth_place, birth_date, gender, salary, photo_ktp, photo_selfie)
+		VALUES (:nik, :full_name, :legal_name, :birth_place, :birth_date, :gender, :salary, :photo_ktp, :photo_selfie)
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, customer)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, customer)
+	}
+	if isDuplicateKey(err) {
+		return ErrDuplicateKey
+	}
+
 	return err
 }
+
+func (r *CustomerRepository) UpdateCustomer(ctx context.Context, tx DBTx, customer *model.Customer) error {
+	query := `
+		UPDATE customers
+		SET full_name = :full_name, legal_name = :legal_name, birth_place = :birth_place, birth_date = :birth_date,
+			salary = :salary, photo_ktp = :photo_ktp, photo_selfie = :photo_selfie
+		WHERE nik = :nik
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, customer)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, customer)
+	}
+
+	return err
+}
+
+func (r *CustomerRepository) ListCustomers(ctx context.Context, limit, offset int) ([]model.Customer, error) {
+	customers := []model.Customer{}
+	err := r.db.SelectContext(ctx, &customers, "SELECT * FROM customers ORDER BY nik LIMIT ? OFFSET ?", limit, offset)
+	return customers, err
+}
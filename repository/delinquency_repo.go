@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DelinquencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewDelinquencyRepository(db *sqlx.DB) *DelinquencyRepository {
+	return &DelinquencyRepository{db: db}
+}
+
+// UpsertDelinquency stores the latest delinquency snapshot of a contract.
+func (r *DelinquencyRepository) UpsertDelinquency(ctx context.Context, tx DBTx, d *model.Delinquency) error {
+	query := `
+		INSERT INTO contract_delinquency
+			(contract_number, dpd, overdue_installments, overdue_amount, penalty_amount, collectibility, next_due_date, maturity_date, as_of)
+		VALUES
+			(:contract_number, :dpd, :overdue_installments, :overdue_amount, :penalty_amount, :collectibility, :next_due_date, :maturity_date, :as_of)
+		ON DUPLICATE KEY UPDATE
+			dpd = VALUES(dpd),
+			overdue_installments = VALUES(overdue_installments),
+			overdue_amount = VALUES(overdue_amount),
+			penalty_amount = VALUES(penalty_amount),
+			collectibility = VALUES(collectibility),
+			next_due_date = VALUES(next_due_date),
+			maturity_date = VALUES(maturity_date),
+			as_of = VALUES(as_of)`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, d)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, d)
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type HoldRepository struct {
+	db *sqlx.DB
+}
+
+func NewHoldRepository(db *sqlx.DB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+// CreateHold inserts a limit hold and sets its generated ID.
+func (r *HoldRepository) CreateHold(ctx context.Context, tx DBTx, h *model.LimitHold) error {
+	query := `
+		INSERT INTO limit_holds (customer_nik, tenor, amount, channel, status, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{h.CustomerNIK, h.Tenor, h.Amount, h.Channel, h.Status, h.ExpiresAt, h.CreatedAt}
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	h.ID = id
+	return nil
+}
+
+func (r *HoldRepository) GetHold(ctx context.Context, id int64) (*model.LimitHold, error) {
+	var h model.LimitHold
+	err := r.db.GetContext(ctx, &h, "SELECT * FROM limit_holds WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// GetHoldForUpdate reads a hold inside tx and locks its row until tx ends, so a
+// confirmation cannot race with a release or the expiry sweeper.
+func (r *HoldRepository) GetHoldForUpdate(ctx context.Context, tx DBTx, id int64) (*model.LimitHold, error) {
+	var h model.LimitHold
+	err := tx.GetContext(ctx, &h, "SELECT * FROM limit_holds WHERE id = ? FOR UPDATE", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ResolveHold moves a hold out of the active status. contractNumber is set for a
+// confirmed hold and nil otherwise.
+func (r *HoldRepository) ResolveHold(ctx context.Context, tx DBTx, id int64, status string, contractNumber *string, at time.Time) error {
+	query := "UPDATE limit_holds SET status = ?, contract_number = ?, resolved_at = ? WHERE id = ?"
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, status, contractNumber, at, id)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, status, contractNumber, at, id)
+	}
+
+	return err
+}
+
+// ListExpiredHoldIDs returns up to limit IDs of active holds that expired at or
+// before now and sort after afterID, oldest first.
+func (r *HoldRepository) ListExpiredHoldIDs(ctx context.Context, now time.Time, afterID int64, limit int) ([]int64, error) {
+	ids := []int64{}
+	err := r.db.SelectContext(ctx, &ids,
+		"SELECT id FROM limit_holds WHERE status = ? AND expires_at <= ? AND id > ? ORDER BY id LIMIT ?",
+		model.LimitHoldActive, now, afterID, limit)
+	return ids, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"multifinance/model"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrDuplicateKey is returned when an insert violates a primary or unique key.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+const mysqlErrDuplicateEntry = 1062
+
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// GetIdempotencyKey returns the key stored for channel, or nil when it is unused there.
+func (r *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, channel, key string) (*model.IdempotencyKey, error) {
+	var k model.IdempotencyKey
+	err := r.db.GetContext(ctx, &k, "SELECT * FROM idempotency_keys WHERE channel = ? AND idempotency_key = ?", channel, key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// CreateIdempotencyKey stores a key inside tx. It returns ErrDuplicateKey when the
+// key was already stored for its channel, including by a concurrent transaction that committed first.
+func (r *IdempotencyRepository) CreateIdempotencyKey(ctx context.Context, tx DBTx, k *model.IdempotencyKey) error {
+	query := `
+		INSERT INTO idempotency_keys (channel, idempotency_key, request_hash, contract_number, response, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	_, err := exec.ExecContext(ctx, query, k.Channel, k.Key, k.RequestHash, k.ContractNumber, k.Response, k.CreatedAt)
+	if isDuplicateKey(err) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type InstallmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewInstallmentRepository(db *sqlx.DB) *InstallmentRepository {
+	return &InstallmentRepository{db: db}
+}
+
+func (r *InstallmentRepository) CreateInstallments(ctx context.Context, tx DBTx, installments []model.Installment) error {
+	if len(installments) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO installments (contract_number, period, due_date, principal, interest, amount, outstanding_balance, status)
+		VALUES (:contract_number, :period, :due_date, :principal, :interest, :amount, :outstanding_balance, :status)`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, installments)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, installments)
+	}
+
+	return err
+}
+
+func (r *InstallmentRepository) ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error) {
+	installments := []model.Installment{}
+	err := r.db.SelectContext(ctx, &installments, "SELECT * FROM installments WHERE contract_number = ? ORDER BY period", contractNumber)
+	return installments, err
+}
+
+// ListInstallmentsForUpdate reads a contract's schedule inside tx and locks its rows,
+// so concurrent payments on the same contract are allocated one after another.
+func (r *InstallmentRepository) ListInstallmentsForUpdate(ctx context.Context, tx DBTx, contractNumber string) ([]model.Installment, error) {
+	installments := []model.Installment{}
+	err := tx.SelectContext(ctx, &installments, "SELECT * FROM installments WHERE contract_number = ? ORDER BY period FOR UPDATE", contractNumber)
+	return installments, err
+}
+
+// UpdateInstallmentPayments stores the paid and accrued amounts, status and paid_at of the given periods.
+func (r *InstallmentRepository) UpdateInstallmentPayments(ctx context.Context, tx DBTx, installments []model.Installment) error {
+	query := `
+		UPDATE installments
+		SET paid_principal = ?, paid_interest = ?, accrued_interest = ?, status = ?, paid_at = ?
+		WHERE contract_number = ? AND period = ?`
+
+	for _, i := range installments {
+		if _, err := tx.ExecContext(ctx, query, i.PaidPrincipal, i.PaidInterest, i.AccruedInterest, i.Status, i.PaidAt, i.ContractNumber, i.Period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelInstallments marks every unpaid period of a contract as cancelled.
+func (r *InstallmentRepository) CancelInstallments(ctx context.Context, tx DBTx, contractNumber string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE installments SET status = ? WHERE contract_number = ? AND status = ?",
+		model.InstallmentStatusCancelled, contractNumber, model.InstallmentStatusUnpaid)
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrUnbalancedEntry is returned when a journal entry has no lines or its debits
+// and credits differ.
+var ErrUnbalancedEntry = errors.New("journal entry is not balanced")
+
+type LedgerRepository struct {
+	db *sqlx.DB
+}
+
+func NewLedgerRepository(db *sqlx.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// PostEntries writes journal entries and their lines, setting the generated IDs.
+// Every entry is checked before anything is written.
+func (r *LedgerRepository) PostEntries(ctx context.Context, tx DBTx, entries []model.JournalEntry) error {
+	for i := range entries {
+		if !entries[i].Balanced() {
+			return ErrUnbalancedEntry
+		}
+	}
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		res, err := exec.ExecContext(ctx,
+			"INSERT INTO journal_entries (entry_type, contract_number, reference, posted_at) VALUES (?, ?, ?, ?)",
+			e.EntryType, e.ContractNumber, e.Reference, e.PostedAt)
+		if err != nil {
+			return err
+		}
+		if e.ID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+
+		for j := range e.Lines {
+			e.Lines[j].EntryID = e.ID
+		}
+		query := `
+			INSERT INTO journal_lines (entry_id, account_code, debit, credit)
+			VALUES (:entry_id, :account_code, :debit, :credit)`
+		if _, err := exec.NamedExecContext(ctx, query, e.Lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContractBalance returns the debits minus the credits posted to account for one
+// contract. Pass the transaction that locks the contract so the balance cannot
+// move before it commits.
+func (r *LedgerRepository) ContractBalance(ctx context.Context, tx DBTx, contractNumber, account string) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(l.debit - l.credit), 0)
+		FROM journal_lines l
+		JOIN journal_entries e ON e.id = l.entry_id
+		WHERE e.contract_number = ? AND l.account_code = ?`
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	var balance int64
+	err := exec.GetContext(ctx, &balance, query, contractNumber, account)
+	return balance, err
+}
+
+// TrialBalance sums the debits and credits posted to every account before asOf.
+func (r *LedgerRepository) TrialBalance(ctx context.Context, asOf time.Time) ([]model.TrialBalanceLine, error) {
+	query := `
+		SELECT a.code AS account_code, a.name AS account_name, a.type AS account_type, a.normal_balance,
+			COALESCE(SUM(CASE WHEN e.posted_at < ? THEN l.debit END), 0) AS debit,
+			COALESCE(SUM(CASE WHEN e.posted_at < ? THEN l.credit END), 0) AS credit
+		FROM ledger_accounts a
+		LEFT JOIN journal_lines l ON l.account_code = a.code
+		LEFT JOIN journal_entries e ON e.id = l.entry_id
+		GROUP BY a.code, a.name, a.type, a.normal_balance
+		ORDER BY a.code`
+
+	lines := []model.TrialBalanceLine{}
+	err := r.db.SelectContext(ctx, &lines, query, asOf, asOf)
+	return lines, err
+}
@@ -38,6 +38,50 @@ func (r *LimitRepository) GetLimit(ctx context.Context, nik string, tenor int) (
 	return &limit, nil
 }
 
+// GetLimitForUpdate reads a tenor limit inside tx and locks the row until tx ends,
+// so a read-modify-write through UpdateLimit cannot race with other writers.
+func (r *LimitRepository) GetLimitForUpdate(ctx context.Context, tx DBTx, nik string, tenor int) (*model.CustomerLimit, error) {
+	var limit model.CustomerLimit
+	query := "SELECT * FROM customer_limits WHERE customer_nik = ? AND tenor = ? FOR UPDATE"
+
+	err := tx.GetContext(ctx, &limit, query, nik, tenor)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// DeductLimit atomically subtracts amount from a tenor limit if enough limit is
+// available. It reports false when the row is missing or the limit is insufficient.
+func (r *LimitRepository) DeductLimit(ctx context.Context, tx DBTx, nik string, tenor int, amount int64) (bool, error) {
+	query := `
+		UPDATE customer_limits
+		SET limit_amount = limit_amount - ?
+		WHERE customer_nik = ? AND tenor = ? AND limit_amount >= ?`
+
+	var (
+		res sql.Result
+		err error
+	)
+	if tx != nil {
+		res, err = tx.ExecContext(ctx, query, amount, nik, tenor, amount)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, amount, nik, tenor, amount)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
 func (r *LimitRepository) UpdateLimit(ctx context.Context, tx DBTx, nik string, tenor int, amount int64) error {
 	query := `
 		UPDATE customer_limits 
@@ -53,3 +97,94 @@ func (r *LimitRepository) UpdateLimit(ctx context.Context, tx DBTx, nik string,
 
 	return err
 }
+
+func (r *LimitRepository) ListLimits(ctx context.Context, nik string) ([]model.CustomerLimit, error) {
+	limits := []model.CustomerLimit{}
+	err := r.db.SelectContext(ctx, &limits, "SELECT * FROM customer_limits WHERE customer_nik = ? ORDER BY tenor", nik)
+	return limits, err
+}
+
+// ListLimitsForUpdate reads all tenor limits of a customer and locks the rows until tx ends.
+func (r *LimitRepository) ListLimitsForUpdate(ctx context.Context, tx DBTx, nik string) ([]model.CustomerLimit, error) {
+	limits := []model.CustomerLimit{}
+	err := tx.SelectContext(ctx, &limits, "SELECT * FROM customer_limits WHERE customer_nik = ? ORDER BY tenor FOR UPDATE", nik)
+	return limits, err
+}
+
+// UpsertLimits inserts or overwrites the credit line and available amount of every
+// given tenor in a single statement.
+func (r *LimitRepository) UpsertLimits(ctx context.Context, tx DBTx, limits []model.CustomerLimit) error {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO customer_limits (customer_nik, tenor, credit_line, limit_amount)
+		VALUES (:customer_nik, :tenor, :credit_line, :limit_amount)
+		ON DUPLICATE KEY UPDATE credit_line = VALUES(credit_line), limit_amount = VALUES(limit_amount)`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, limits)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, limits)
+	}
+
+	return err
+}
+
+func (r *LimitRepository) CreateLimitAdjustments(ctx context.Context, tx DBTx, adjustments []model.LimitAdjustment) error {
+	if len(adjustments) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO limit_adjustments (customer_nik, tenor, previous_amount, new_amount, changed_by, reason, created_at)
+		VALUES (:customer_nik, :tenor, :previous_amount, :new_amount, :changed_by, :reason, :created_at)`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, adjustments)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, adjustments)
+	}
+
+	return err
+}
+
+// CreateLimitMovements appends movements to the history of their tenor limits.
+func (r *LimitRepository) CreateLimitMovements(ctx context.Context, tx DBTx, movements []model.LimitMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO limit_movements (customer_nik, tenor, delta, balance, reference_type, reference_id, note, created_at)
+		VALUES (:customer_nik, :tenor, :delta, :balance, :reference_type, :reference_id, :note, :created_at)`
+
+	var err error
+	if tx != nil {
+		_, err = tx.NamedExecContext(ctx, query, movements)
+	} else {
+		_, err = r.db.NamedExecContext(ctx, query, movements)
+	}
+
+	return err
+}
+
+// ListLimitMovements returns up to limit movements of a tenor limit, newest first,
+// with an ID below beforeID when it is set.
+func (r *LimitRepository) ListLimitMovements(ctx context.Context, nik string, tenor int, beforeID int64, limit int) ([]model.LimitMovement, error) {
+	query := "SELECT * FROM limit_movements WHERE customer_nik = ? AND tenor = ?"
+	args := []interface{}{nik, tenor}
+	if beforeID > 0 {
+		query += " AND id < ?"
+		args = append(args, beforeID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	movements := []model.LimitMovement{}
+	err := r.db.SelectContext(ctx, &movements, query, args...)
+	return movements, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitRepository_DeductLimit(t *testing.T) {
+	// The guard on limit_amount is what keeps concurrent purchases from
+	// overspending; the row lock taken by the UPDATE serializes them.
+	query := regexp.QuoteMeta(`
+		UPDATE customer_limits
+		SET limit_amount = limit_amount - ?
+		WHERE customer_nik = ? AND tenor = ? AND limit_amount >= ?`)
+
+	tests := []struct {
+		namaTest           string
+		setupMocks         func(sqlMock sqlmock.Sqlmock)
+		berhasilDiharapkan bool
+		erorDiharapkan     bool
+	}{
+		{
+			namaTest: "limit mencukupi",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectExec(query).WithArgs(int64(1000000), "3171010101900001", 6, int64(1000000)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			berhasilDiharapkan: true,
+		},
+		{
+			namaTest: "limit tidak mencukupi",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectExec(query).WithArgs(int64(1000000), "3171010101900001", 6, int64(1000000)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			namaTest: "database error",
+			setupMocks: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectExec(query).WillReturnError(errors.New("lock wait timeout"))
+			},
+			erorDiharapkan: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			tt.setupMocks(sqlMock)
+
+			r := NewLimitRepository(sqlx.NewDb(db, "sqlmock"))
+			deducted, err := r.DeductLimit(context.Background(), nil, "3171010101900001", 6, 1000000)
+
+			assert.Equal(t, tt.erorDiharapkan, err != nil, "%v", err)
+			assert.Equal(t, tt.berhasilDiharapkan, deducted)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// CreateEvent stores an event and sets its generated ID. Pass the transaction of
+// the change the event describes so both commit or roll back together.
+func (r *OutboxRepository) CreateEvent(ctx context.Context, tx DBTx, e *model.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox_events (event_id, event_type, aggregate_id, channel, payload, occurred_at, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{e.EventID, e.EventType, e.AggregateID, e.Channel, e.Payload, e.OccurredAt, e.NextAttemptAt}
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// ListClaimableEvents locks up to limit events due at now that no relay holds a
+// live claim on, oldest first. Only the oldest open event of an aggregate is
+// returned, so consumers see each contract's events in order even when several
+// relays run; events that ran out of attempts no longer hold the others back.
+// Rows another relay is claiming are skipped rather than waited for.
+func (r *OutboxRepository) ListClaimableEvents(ctx context.Context, tx DBTx, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	query := `
+		SELECT o.* FROM outbox_events o
+		WHERE o.published_at IS NULL AND o.failed_at IS NULL AND o.next_attempt_at <= ?
+			AND (o.claimed_until IS NULL OR o.claimed_until <= ?)
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.aggregate_id = o.aggregate_id AND p.published_at IS NULL AND p.failed_at IS NULL AND p.id < o.id
+			)
+		ORDER BY o.id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`
+
+	events := []model.OutboxEvent{}
+	err := tx.SelectContext(ctx, &events, query, now, now, limit)
+	return events, err
+}
+
+// ClaimEvents leases the events to owner until the given time. Call it in the
+// transaction that locked them with ListClaimableEvents.
+func (r *OutboxRepository) ClaimEvents(ctx context.Context, tx DBTx, ids []int64, owner string, until time.Time) error {
+	query, args, err := sqlx.In("UPDATE outbox_events SET claimed_by = ?, claimed_until = ? WHERE id IN (?)", owner, until, ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *OutboxRepository) MarkEventPublished(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, published_at = ?, last_error = NULL, claimed_by = NULL, claimed_until = NULL
+		WHERE id = ?`, at, id)
+	return err
+}
+
+// MarkEventFailed records a failed delivery and when to try again.
+func (r *OutboxRepository) MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?, claimed_by = NULL, claimed_until = NULL
+		WHERE id = ?`, nextAttemptAt, truncateError(lastError), id)
+	return err
+}
+
+// MarkEventDead records the last failed delivery of an event that ran out of
+// attempts. It stays in the table for inspection until it is retried.
+func (r *OutboxRepository) MarkEventDead(ctx context.Context, id int64, at time.Time, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, failed_at = ?, last_error = ?, claimed_by = NULL, claimed_until = NULL
+		WHERE id = ?`, at, truncateError(lastError), id)
+	return err
+}
+
+// ListFailedEvents returns up to limit events that ran out of attempts, latest
+// failure first.
+func (r *OutboxRepository) ListFailedEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	events := []model.OutboxEvent{}
+	err := r.db.SelectContext(ctx, &events,
+		"SELECT * FROM outbox_events WHERE failed_at IS NOT NULL ORDER BY failed_at DESC, id DESC LIMIT ?", limit)
+	return events, err
+}
+
+// RetryFailedEvent queues a failed event again with a fresh set of attempts. It
+// reports false when no failed event has that event ID.
+func (r *OutboxRepository) RetryFailedEvent(ctx context.Context, eventID string, now time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE outbox_events SET failed_at = NULL, attempts = 0, next_attempt_at = ? WHERE event_id = ? AND failed_at IS NOT NULL",
+		now, eventID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// truncateError fits an error message into the last_error column.
+func truncateError(message string) string {
+	if len(message) > 500 {
+		return message[:500]
+	}
+	return message
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PaymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// CreatePayment inserts a payment and sets its generated ID.
+func (r *PaymentRepository) CreatePayment(ctx context.Context, tx DBTx, p *model.Payment) error {
+	query := `
+		INSERT INTO payments (contract_number, amount, principal_paid, interest_paid, penalty_paid, reference, paid_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{p.ContractNumber, p.Amount, p.PrincipalPaid, p.InterestPaid, p.PenaltyPaid, p.Reference, p.PaidAt}
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+func (r *PaymentRepository) ListPayments(ctx context.Context, contractNumber string) ([]model.Payment, error) {
+	payments := []model.Payment{}
+	err := r.db.SelectContext(ctx, &payments, "SELECT * FROM payments WHERE contract_number = ? ORDER BY paid_at, id", contractNumber)
+	return payments, err
+}
+
+// CountPayments counts the payments of a contract inside tx.
+func (r *PaymentRepository) CountPayments(ctx context.Context, tx DBTx, contractNumber string) (int, error) {
+	var count int
+	err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM payments WHERE contract_number = ?", contractNumber)
+	return count, err
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SequenceRepository struct {
+	db *sqlx.DB
+}
+
+func NewSequenceRepository(db *sqlx.DB) *SequenceRepository {
+	return &SequenceRepository{db: db}
+}
+
+// NextValue increments the counter of scope inside tx and returns the new value.
+// The row stays locked until tx ends, and a rollback gives the value back, so
+// committed values of a scope have no gaps.
+func (r *SequenceRepository) NextValue(ctx context.Context, tx DBTx, scope string) (int64, error) {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO contract_sequences (scope, last_value) VALUES (?, 1)
+		ON DUPLICATE KEY UPDATE last_value = last_value + 1`, scope)
+	if err != nil {
+		return 0, err
+	}
+
+	var value int64
+	err = tx.GetContext(ctx, &value, "SELECT last_value FROM contract_sequences WHERE scope = ?", scope)
+	return value, err
+}
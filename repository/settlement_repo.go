@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SettlementRepository struct {
+	db *sqlx.DB
+}
+
+func NewSettlementRepository(db *sqlx.DB) *SettlementRepository {
+	return &SettlementRepository{db: db}
+}
+
+// CreateSettlement inserts an executed settlement and sets its generated ID.
+func (r *SettlementRepository) CreateSettlement(ctx context.Context, tx DBTx, s *model.Settlement) error {
+	query := `
+		INSERT INTO settlements (contract_number, payment_id, settlement_date, remaining_principal, accrued_interest,
+			discount, penalty, fee, total, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		s.ContractNumber, s.PaymentID, s.SettlementDate, s.RemainingPrincipal, s.AccruedInterest,
+		s.Discount, s.Penalty, s.Fee, s.Total, s.CreatedAt,
+	}
+
+	var exec DBTx = r.db
+	if tx != nil {
+		exec = tx
+	}
+
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	return nil
+}
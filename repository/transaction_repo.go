@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"strings"
+	"time"
 
 	"multifinance/model"
 
@@ -18,8 +21,8 @@ func NewTransactionRepository(db *sqlx.DB) *TransactionRepository {
 
 func (r *TransactionRepository) CreateTransaction(ctx context.Context, tx DBTx, t *model.Transaction) error {
 	query := `
-		INSERT INTO transactions (contract_number, customer_nik, otr, admin_fee, installment, interest, asset_name, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO transactions (contract_number, customer_nik, otr, admin_fee, installment, interest, asset_name, tenor, channel, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	args := []interface{}{
 		t.ContractNumber,
@@ -29,6 +32,9 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, tx DBTx,
 		t.Installment,
 		t.Interest,
 		t.AssetName,
+		t.Tenor,
+		t.Channel,
+		t.Status,
 		t.CreatedAt,
 	}
 
@@ -43,3 +49,92 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, tx DBTx,
 
 	return err
 }
+
+func (r *TransactionRepository) GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error) {
+	var t model.Transaction
+	err := r.db.GetContext(ctx, &t, "SELECT * FROM transactions WHERE contract_number = ?", contractNumber)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTransactionForUpdate reads a contract inside tx and locks its row until tx ends.
+func (r *TransactionRepository) GetTransactionForUpdate(ctx context.Context, tx DBTx, contractNumber string) (*model.Transaction, error) {
+	var t model.Transaction
+	err := tx.GetContext(ctx, &t, "SELECT * FROM transactions WHERE contract_number = ? FOR UPDATE", contractNumber)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListContractNumbersByStatus returns up to limit contract numbers with the given
+// status that sort after the given one, for batch jobs that walk all contracts.
+func (r *TransactionRepository) ListContractNumbersByStatus(ctx context.Context, status, after string, limit int) ([]string, error) {
+	numbers := []string{}
+	err := r.db.SelectContext(ctx, &numbers,
+		"SELECT contract_number FROM transactions WHERE status = ? AND contract_number > ? ORDER BY contract_number LIMIT ?",
+		status, after, limit)
+	return numbers, err
+}
+
+// CancelTransaction marks a contract cancelled with the time and reason.
+func (r *TransactionRepository) CancelTransaction(ctx context.Context, tx DBTx, contractNumber, reason string, at time.Time) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE transactions SET status = ?, cancelled_at = ?, cancel_reason = ? WHERE contract_number = ?",
+		model.TransactionStatusCancelled, at, reason, contractNumber)
+	return err
+}
+
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, tx DBTx, contractNumber, status string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE transactions SET status = ? WHERE contract_number = ?", status, contractNumber)
+	return err
+}
+
+// ListTransactions returns a customer's transactions newest first, applying the
+// optional filters and keyset cursor in f.
+func (r *TransactionRepository) ListTransactions(ctx context.Context, f model.TransactionFilter) ([]model.Transaction, error) {
+	conditions := []string{"customer_nik = ?"}
+	args := []interface{}{f.CustomerNIK}
+
+	if f.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *f.To)
+	}
+	if f.Tenor > 0 {
+		conditions = append(conditions, "tenor = ?")
+		args = append(args, f.Tenor)
+	}
+	if f.AssetName != "" {
+		conditions = append(conditions, "asset_name LIKE ?")
+		args = append(args, "%"+escapeLike(f.AssetName)+"%")
+	}
+	if f.AfterContractNumber != "" {
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND contract_number < ?))")
+		args = append(args, f.AfterCreatedAt, f.AfterCreatedAt, f.AfterContractNumber)
+	}
+
+	query := "SELECT * FROM transactions WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY created_at DESC, contract_number DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	transactions := []model.Transaction{}
+	err := r.db.SelectContext(ctx, &transactions, query, args...)
+	return transactions, err
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied search term.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
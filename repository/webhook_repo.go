@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateSubscription inserts a subscription and sets its generated ID.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, s *model.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (partner, url, secret, event_types, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.ExecContext(ctx, query, s.Partner, s.URL, s.Secret, s.EventTypes, s.Active, s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	return nil
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	var s model.WebhookSubscription
+	err := r.db.GetContext(ctx, &s, "SELECT * FROM webhook_subscriptions WHERE id = ?", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSubscriptions returns every subscription, newest first.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	subs := []model.WebhookSubscription{}
+	err := r.db.SelectContext(ctx, &subs, "SELECT * FROM webhook_subscriptions ORDER BY id DESC")
+	return subs, err
+}
+
+func (r *WebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	subs := []model.WebhookSubscription{}
+	err := r.db.SelectContext(ctx, &subs, "SELECT * FROM webhook_subscriptions WHERE active = TRUE ORDER BY id")
+	return subs, err
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, s *model.WebhookSubscription) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE webhook_subscriptions SET url = ?, secret = ?, event_types = ?, active = ?, updated_at = ? WHERE id = ?",
+		s.URL, s.Secret, s.EventTypes, s.Active, s.UpdatedAt, s.ID)
+	return err
+}
+
+// CreateDeliveries queues deliveries. A delivery already queued for the same
+// subscription and event is skipped, so relaying an event twice is harmless.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	query := `
+		INSERT IGNORE INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES (:subscription_id, :event_id, :event_type, :payload, :status, :next_attempt_at, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, query, deliveries)
+	return err
+}
+
+// ListClaimableDeliveries locks up to limit pending deliveries due at now that no
+// sender holds a live claim on, oldest first. Rows another sender is claiming
+// are skipped rather than waited for.
+func (r *WebhookRepository) ListClaimableDeliveries(ctx context.Context, tx DBTx, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+			AND (claimed_until IS NULL OR claimed_until <= ?)
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`
+
+	deliveries := []model.WebhookDelivery{}
+	err := tx.SelectContext(ctx, &deliveries, query, model.WebhookDeliveryPending, now, now, limit)
+	return deliveries, err
+}
+
+// ClaimDeliveries leases the deliveries to owner until the given time. Call it
+// in the transaction that locked them with ListClaimableDeliveries.
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, tx DBTx, ids []int64, owner string, until time.Time) error {
+	query, args, err := sqlx.In("UPDATE webhook_deliveries SET claimed_by = ?, claimed_until = ? WHERE id IN (?)", owner, until, ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt and releases its claim.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	if d.LastError != nil && len(*d.LastError) > 500 {
+		truncated := (*d.LastError)[:500]
+		d.LastError = &truncated
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, delivered_at = ?,
+			claimed_by = NULL, claimed_until = NULL
+		WHERE id = ?`,
+		d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.ID)
+	return err
+}
+
+// ListDeliveries returns the latest deliveries of a subscription, newest first,
+// optionally only those with the given status.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit int) ([]model.WebhookDelivery, error) {
+	query := "SELECT * FROM webhook_deliveries WHERE subscription_id = ?"
+	args := []interface{}{subscriptionID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	deliveries := []model.WebhookDelivery{}
+	err := r.db.SelectContext(ctx, &deliveries, query, args...)
+	return deliveries, err
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/model"
+)
+
+// SystemActor is recorded as the actor of changes made without a caller, such
+// as those of background jobs.
+const SystemActor = "system"
+
+// CustomerAuditData is the audited snapshot of a customer.
+type CustomerAuditData struct {
+	NIK         string `json:"nik"`
+	FullName    string `json:"full_name"`
+	LegalName   string `json:"legal_name"`
+	BirthPlace  string `json:"birth_place"`
+	BirthDate   string `json:"birth_date"`
+	Gender      string `json:"gender"`
+	Salary      int64  `json:"salary"`
+	PhotoKTP    string `json:"photo_ktp"`
+	PhotoSelfie string `json:"photo_selfie"`
+}
+
+// LimitAuditData is the audited snapshot of one tenor limit.
+type LimitAuditData struct {
+	CustomerNIK string `json:"customer_nik"`
+	Tenor       int    `json:"tenor"`
+	LimitAmount int64  `json:"limit_amount"`
+}
+
+// NewCustomerAudit builds the audit entry of a customer change. before is nil
+// for a new customer.
+func NewCustomerAudit(ctx context.Context, before, after *model.Customer, reason string, at time.Time) (model.AuditLog, error) {
+	action, beforeData := model.AuditActionCreate, interface{}(nil)
+	if before != nil {
+		action, beforeData = model.AuditActionUpdate, customerAuditData(before)
+	}
+	return NewAuditEntry(ctx, model.AuditEntityCustomer, after.NIK, action, beforeData, customerAuditData(after), reason, at)
+}
+
+// NewLimitAudit builds the audit entry of a tenor limit moving from previous to
+// current. previous is nil for a tenor the customer had no limit for.
+func NewLimitAudit(ctx context.Context, nik string, tenor int, previous *int64, current int64, reason string, at time.Time) (model.AuditLog, error) {
+	action, beforeData := model.AuditActionCreate, interface{}(nil)
+	if previous != nil {
+		action, beforeData = model.AuditActionUpdate, LimitAuditData{CustomerNIK: nik, Tenor: tenor, LimitAmount: *previous}
+	}
+	after := LimitAuditData{CustomerNIK: nik, Tenor: tenor, LimitAmount: current}
+	return NewAuditEntry(ctx, model.AuditEntityCustomerLimit, LimitAuditID(nik, tenor), action, beforeData, after, reason, at)
+}
+
+// NewContractAudit builds the audit entry of a contract change. before is nil
+// for a new contract.
+func NewContractAudit(ctx context.Context, before, after *model.Transaction, reason string, at time.Time) (model.AuditLog, error) {
+	action, beforeData := model.AuditActionCreate, interface{}(nil)
+	if before != nil {
+		action, beforeData = model.AuditActionUpdate, contractEventData(before)
+	}
+	return NewAuditEntry(ctx, model.AuditEntityTransaction, after.ContractNumber, action, beforeData, contractEventData(after), reason, at)
+}
+
+// LimitAuditID is the audit entity ID of a tenor limit, e.g. "3171010101900001/6".
+func LimitAuditID(nik string, tenor int) string {
+	return nik + "/" + strconv.Itoa(tenor)
+}
+
+// NewAuditEntry builds an audit entry with before and after stored as JSON. The
+// actor and request ID are taken from ctx; without a caller the actor is
+// SystemActor.
+func NewAuditEntry(ctx context.Context, entityType, entityID, action string, before, after interface{}, reason string, at time.Time) (model.AuditLog, error) {
+	entry := model.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      SystemActor,
+		Reason:     reason,
+		CreatedAt:  at,
+	}
+	if p := auth.FromContext(ctx); p != nil {
+		entry.Actor = p.Actor()
+	}
+	if id := auth.RequestID(ctx); id != "" {
+		entry.RequestID = &id
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return model.AuditLog{}, err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return model.AuditLog{}, err
+		}
+	}
+	return entry, nil
+}
+
+func customerAuditData(c *model.Customer) CustomerAuditData {
+	return CustomerAuditData{
+		NIK:         c.NIK,
+		FullName:    c.FullName,
+		LegalName:   c.LegalName,
+		BirthPlace:  c.BirthPlace,
+		BirthDate:   c.BirthDate,
+		Gender:      c.Gender,
+		Salary:      c.Salary,
+		PhotoKTP:    c.PhotoKTP,
+		PhotoSelfie: c.PhotoSelfie,
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import "time"
+
+// Backoff spaces out retries exponentially: Base after the first failure,
+// doubling after each further one, never more than Max.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	delay := b.Base
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= b.Max || delay <= 0 {
+			return b.Max
+		}
+	}
+	if delay > b.Max {
+		return b.Max
+	}
+	return delay
+}
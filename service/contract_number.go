@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"multifinance/config"
+	"multifinance/model"
+	"multifinance/repository"
+)
+
+// ContractNumberGenerator assigns the number of a new contract inside the
+// transaction that books it.
+type ContractNumberGenerator interface {
+	Next(ctx context.Context, tx repository.DBTx, transaction *model.Transaction) (string, error)
+}
+
+type SequenceRepository interface {
+	NextValue(ctx context.Context, tx repository.DBTx, scope string) (int64, error)
+}
+
+// sequenceContractNumberGenerator formats numbers as
+// BRANCH-PRODUCT<tenor>-YYMMDD-<sequence><check digit>, e.g. JKT-MF06-261016-0000427.
+// The sequence restarts every day per branch and product.
+type sequenceContractNumberGenerator struct {
+	cfg       config.ContractConfig
+	sequences SequenceRepository
+}
+
+func NewContractNumberGenerator(cfg config.ContractConfig, sequences SequenceRepository) ContractNumberGenerator {
+	return &sequenceContractNumberGenerator{cfg: cfg, sequences: sequences}
+}
+
+func (g *sequenceContractNumberGenerator) Next(ctx context.Context, tx repository.DBTx, transaction *model.Transaction) (string, error) {
+	product := fmt.Sprintf("%s%02d", g.cfg.ProductCode, transaction.Tenor)
+	date := transaction.CreatedAt.Format("060102")
+
+	seq, err := g.sequences.NextValue(ctx, tx, g.cfg.BranchCode+"-"+product+"-"+date)
+	if err != nil {
+		return "", err
+	}
+
+	digits := fmt.Sprintf("%s%06d", date, seq)
+	return fmt.Sprintf("%s-%s-%s-%06d%d", g.cfg.BranchCode, product, date, seq, LuhnCheckDigit(digits)), nil
+}
+
+// LuhnCheckDigit returns the digit that makes digits+check pass the Luhn check,
+// so a single mistyped digit or swapped neighbours are caught when read back.
+func LuhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+	"multifinance/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSequenceRepository struct {
+	values map[string]int64
+	err    error
+}
+
+func (f *fakeSequenceRepository) NextValue(ctx context.Context, tx repository.DBTx, scope string) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.values[scope]++
+	return f.values[scope], nil
+}
+
+func TestContractNumberGenerator_Next(t *testing.T) {
+	sequences := &fakeSequenceRepository{values: map[string]int64{}}
+	gen := NewContractNumberGenerator(config.ContractConfig{BranchCode: "JKT", ProductCode: "MF"}, sequences)
+	created := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	first, err := gen.Next(context.Background(), nil, &model.Transaction{Tenor: 6, CreatedAt: created})
+	assert.NoError(t, err)
+	second, err := gen.Next(context.Background(), nil, &model.Transaction{Tenor: 6, CreatedAt: created})
+	assert.NoError(t, err)
+	otherTenor, err := gen.Next(context.Background(), nil, &model.Transaction{Tenor: 12, CreatedAt: created})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "JKT-MF06-261016-0000018", first)
+	assert.Equal(t, "JKT-MF06-261016-0000026", second)
+	// Each branch, product and day has its own sequence.
+	assert.Equal(t, "JKT-MF12-261016-0000018", otherTenor)
+}
+
+func TestContractNumberGenerator_SequenceError(t *testing.T) {
+	gen := NewContractNumberGenerator(config.ContractConfig{BranchCode: "JKT", ProductCode: "MF"}, &fakeSequenceRepository{err: errors.New("db error")})
+
+	_, err := gen.Next(context.Background(), nil, &model.Transaction{Tenor: 3, CreatedAt: time.Now()})
+
+	assert.Error(t, err)
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	// 7992739871 is the textbook Luhn example with check digit 3.
+	assert.Equal(t, 3, LuhnCheckDigit("7992739871"))
+	assert.Equal(t, 0, LuhnCheckDigit(""))
+	// A transposition of neighbouring digits changes the check digit.
+	assert.NotEqual(t, LuhnCheckDigit("261016000012"), LuhnCheckDigit("261016000021"))
+}
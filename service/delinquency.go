@@ -0,0 +1,132 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+)
+
+const (
+	PenaltyMethodFlatPerDay    = "flat_per_day"
+	PenaltyMethodPercentPerDay = "percent_per_day"
+)
+
+// collectibilityLabels are the OJK quality classes for financing receivables.
+var collectibilityLabels = map[int]string{
+	1: "Lancar",
+	2: "Dalam Perhatian Khusus",
+	3: "Kurang Lancar",
+	4: "Diragukan",
+	5: "Macet",
+}
+
+// Collectibility maps days past due to the OJK collectibility bucket for
+// multifinance receivables: 0 is Kol 1, 1-90 Kol 2, 91-120 Kol 3, 121-180 Kol 4
+// and anything later Kol 5.
+func Collectibility(dpd int) int {
+	switch {
+	case dpd <= 0:
+		return 1
+	case dpd <= 90:
+		return 2
+	case dpd <= 120:
+		return 3
+	case dpd <= 180:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func CollectibilityLabel(kol int) string {
+	return collectibilityLabels[kol]
+}
+
+type DelinquencyAssessor interface {
+	// Assess computes a contract's delinquency as of a date from its schedule. The
+	// result depends only on the schedule and the date, so re-running it is safe.
+	Assess(contractNumber string, installments []model.Installment, asOf time.Time) model.Delinquency
+}
+
+type delinquencyAssessor struct {
+	cfg config.DelinquencyConfig
+}
+
+func NewDelinquencyAssessor(cfg config.DelinquencyConfig) DelinquencyAssessor {
+	return &delinquencyAssessor{cfg: cfg}
+}
+
+func (a *delinquencyAssessor) Assess(contractNumber string, installments []model.Installment, asOf time.Time) model.Delinquency {
+	today := dateOf(asOf)
+	result := model.Delinquency{ContractNumber: contractNumber, AsOf: today}
+
+	for _, i := range installments {
+		if i.DueDate.After(result.MaturityDate) {
+			result.MaturityDate = dateOf(i.DueDate)
+		}
+		if i.Status == model.InstallmentStatusCancelled {
+			continue
+		}
+
+		due := dateOf(i.DueDate)
+		principal, interest := i.Due()
+		unpaid := principal + interest
+
+		// A late payment keeps the penalty it earned up to the day it was paid.
+		lateUntil := today
+		if unpaid == 0 {
+			if i.PaidAt == nil {
+				continue
+			}
+			lateUntil = dateOf(*i.PaidAt)
+		}
+		daysLate := int(lateUntil.Sub(due).Hours() / 24)
+		result.PenaltyAmount += a.penalty(i.Amount, daysLate)
+
+		if unpaid == 0 {
+			continue
+		}
+		if daysLate > 0 {
+			result.OverdueInstallments++
+			result.OverdueAmount += unpaid
+			if daysLate > result.DPD {
+				result.DPD = daysLate
+			}
+		} else if result.NextDueDate == nil || due.Before(*result.NextDueDate) {
+			next := due
+			result.NextDueDate = &next
+		}
+	}
+
+	result.Collectibility = Collectibility(result.DPD)
+	return result
+}
+
+func (a *delinquencyAssessor) penalty(amount int64, daysLate int) int64 {
+	days := daysLate - a.cfg.GraceDays
+	if days <= 0 {
+		return 0
+	}
+
+	var penalty int64
+	switch a.cfg.PenaltyMethod {
+	case PenaltyMethodFlatPerDay:
+		penalty = a.cfg.PenaltyFlatPerDay * int64(days)
+	default:
+		penalty = int64(math.Round(float64(amount) * a.cfg.PenaltyRatePerDay * float64(days)))
+	}
+
+	if a.cfg.PenaltyCapRate > 0 {
+		if limit := int64(math.Round(float64(amount) * a.cfg.PenaltyCapRate)); penalty > limit {
+			penalty = limit
+		}
+	}
+	return penalty
+}
+
+// dateOf drops the time of day so day differences are not skewed by it.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectibility(t *testing.T) {
+	cases := map[int]int{0: 1, 1: 2, 90: 2, 91: 3, 120: 3, 121: 4, 180: 4, 181: 5, 400: 5}
+	for dpd, kol := range cases {
+		assert.Equal(t, kol, Collectibility(dpd), "dpd %d", dpd)
+	}
+}
+
+func TestDelinquencyAssessor_Assess(t *testing.T) {
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	schedule := BuildFlatSchedule("CON-1", 3000000, 300000, 3, start)
+	// Period 1 (due 10 Feb) was paid 5 days late, period 2 (due 10 Mar) is unpaid.
+	paidAt := time.Date(2026, 2, 15, 14, 0, 0, 0, time.UTC)
+	schedule[0].PaidPrincipal = schedule[0].Principal
+	schedule[0].PaidInterest = schedule[0].Interest
+	schedule[0].Status = model.InstallmentStatusPaid
+	schedule[0].PaidAt = &paidAt
+	asOf := time.Date(2026, 3, 30, 9, 0, 0, 0, time.UTC)
+
+	t.Run("percent per day with cap", func(t *testing.T) {
+		assessor := NewDelinquencyAssessor(config.DelinquencyConfig{
+			PenaltyMethod:     PenaltyMethodPercentPerDay,
+			PenaltyRatePerDay: 0.001,
+			PenaltyCapRate:    0.015,
+		})
+
+		d := assessor.Assess("CON-1", schedule, asOf)
+
+		assert.Equal(t, 20, d.DPD)
+		assert.Equal(t, 2, d.Collectibility)
+		assert.Equal(t, 1, d.OverdueInstallments)
+		assert.Equal(t, schedule[1].Amount, d.OverdueAmount)
+		// 5 days on period 1 (5500) plus 20 days on period 2 capped at 1.5% (16500).
+		assert.Equal(t, int64(5500+16500), d.PenaltyAmount)
+		assert.Equal(t, schedule[2].DueDate, *d.NextDueDate)
+		assert.Equal(t, schedule[2].DueDate, d.MaturityDate)
+	})
+
+	t.Run("flat per day after grace days", func(t *testing.T) {
+		assessor := NewDelinquencyAssessor(config.DelinquencyConfig{
+			PenaltyMethod:     PenaltyMethodFlatPerDay,
+			PenaltyFlatPerDay: 5000,
+			GraceDays:         3,
+		})
+
+		d := assessor.Assess("CON-1", schedule, asOf)
+
+		assert.Equal(t, int64((5-3)*5000+(20-3)*5000), d.PenaltyAmount)
+	})
+
+	t.Run("nothing overdue", func(t *testing.T) {
+		assessor := NewDelinquencyAssessor(config.DelinquencyConfig{PenaltyMethod: PenaltyMethodFlatPerDay, PenaltyFlatPerDay: 5000})
+
+		d := assessor.Assess("CON-1", schedule[1:], time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, 0, d.DPD)
+		assert.Equal(t, 1, d.Collectibility)
+		assert.Equal(t, int64(0), d.PenaltyAmount)
+	})
+}
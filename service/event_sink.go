@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	EventSinkHTTP = "http"
+	EventSinkFile = "file"
+)
+
+// EventSink publishes relayed events to consumers outside the service. Publish
+// may be called again for an event it already accepted, so consumers must
+// deduplicate on the envelope ID.
+type EventSink interface {
+	Publish(ctx context.Context, event EventEnvelope) error
+}
+
+type httpEventSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPEventSink posts each event as JSON to url. Any response other than 2xx
+// is a failed delivery.
+func NewHTTPEventSink(url string, timeout time.Duration) EventSink {
+	return &httpEventSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *httpEventSink) Publish(ctx context.Context, event EventEnvelope) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) // nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("event sink responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fileEventSink appends events as JSON lines, each tagged with a NATS-style
+// subject, as a local stand-in for a message broker.
+type fileEventSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileEventSink(path string) EventSink {
+	return &fileEventSink{path: path}
+}
+
+func (s *fileEventSink) Publish(ctx context.Context, event EventEnvelope) error {
+	line, err := json.Marshal(struct {
+		Subject string `json:"subject"`
+		EventEnvelope
+	}{Subject: "multifinance." + event.Type, EventEnvelope: event})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: 10 * time.Second, Max: time.Minute}
+	assert.Equal(t, time.Duration(0), b.Delay(0))
+	assert.Equal(t, 10*time.Second, b.Delay(1))
+	assert.Equal(t, 20*time.Second, b.Delay(2))
+	assert.Equal(t, 40*time.Second, b.Delay(3))
+	assert.Equal(t, time.Minute, b.Delay(4))
+	assert.Equal(t, time.Minute, b.Delay(100))
+}
+
+func TestHTTPEventSink_Publish(t *testing.T) {
+	var received EventEnvelope
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "evt-1", r.Header.Get("X-Event-ID"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPEventSink(server.URL, time.Second)
+	event := EventEnvelope{ID: "evt-1", Type: "contract.created", AggregateID: "CON-1", Data: json.RawMessage(`{"tenor":6}`)}
+
+	assert.NoError(t, sink.Publish(context.Background(), event))
+	assert.Equal(t, "CON-1", received.AggregateID)
+	assert.JSONEq(t, `{"tenor":6}`, string(received.Data))
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, sink.Publish(context.Background(), event), "event sink responded 503")
+}
+
+func TestFileEventSink_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := NewFileEventSink(path)
+
+	assert.NoError(t, sink.Publish(context.Background(), EventEnvelope{ID: "evt-1", Type: "contract.created", Data: json.RawMessage(`{}`)}))
+	assert.NoError(t, sink.Publish(context.Background(), EventEnvelope{ID: "evt-2", Type: "contract.paid_off", Data: json.RawMessage(`{}`)}))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+
+	var line struct {
+		Subject string `json:"subject"`
+		ID      string `json:"id"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+	assert.Equal(t, "multifinance.contract.paid_off", line.Subject)
+	assert.Equal(t, "evt-2", line.ID)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"multifinance/model"
+)
+
+// ContractEventData is the payload of the contract lifecycle events.
+type ContractEventData struct {
+	ContractNumber string     `json:"contract_number"`
+	CustomerNIK    string     `json:"customer_nik"`
+	OTR            int64      `json:"otr"`
+	AdminFee       int64      `json:"admin_fee"`
+	Installment    int64      `json:"installment"`
+	Interest       int64      `json:"interest"`
+	AssetName      string     `json:"asset_name"`
+	Tenor          int        `json:"tenor"`
+	Channel        string     `json:"channel,omitempty"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	CancelReason   *string    `json:"cancel_reason,omitempty"`
+}
+
+// PaymentEventData is the payload of payment.received.
+type PaymentEventData struct {
+	PaymentID       int64     `json:"payment_id"`
+	ContractNumber  string    `json:"contract_number"`
+	Amount          int64     `json:"amount"`
+	PrincipalPaid   int64     `json:"principal_paid"`
+	InterestPaid    int64     `json:"interest_paid"`
+	PenaltyPaid     int64     `json:"penalty_paid"`
+	Reference       string    `json:"reference,omitempty"`
+	EarlySettlement bool      `json:"early_settlement"`
+	PaidAt          time.Time `json:"paid_at"`
+}
+
+// LimitChangeData is one tenor of a limit.changed payload.
+type LimitChangeData struct {
+	Tenor          int   `json:"tenor"`
+	PreviousAmount int64 `json:"previous_amount"`
+	NewAmount      int64 `json:"new_amount"`
+}
+
+// LimitEventData is the payload of limit.changed.
+type LimitEventData struct {
+	CustomerNIK string            `json:"customer_nik"`
+	ChangedBy   string            `json:"changed_by"`
+	Reason      string            `json:"reason"`
+	Limits      []LimitChangeData `json:"limits"`
+}
+
+// EventEnvelope is the wire format of an event handed to a sink. ID is stable
+// across redeliveries, so consumers can drop duplicates.
+type EventEnvelope struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Channel     string          `json:"channel,omitempty"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// NewContractEvent builds the outbox event of a contract lifecycle change, with a
+// snapshot of the contract as its payload.
+func NewContractEvent(eventType string, t *model.Transaction, at time.Time) (*model.OutboxEvent, error) {
+	event, err := NewEvent(eventType, t.ContractNumber, contractEventData(t), at)
+	if err != nil {
+		return nil, err
+	}
+	event.Channel = t.Channel
+	return event, nil
+}
+
+func contractEventData(t *model.Transaction) ContractEventData {
+	return ContractEventData{
+		ContractNumber: t.ContractNumber,
+		CustomerNIK:    t.CustomerNIK,
+		OTR:            t.OTR,
+		AdminFee:       t.AdminFee,
+		Installment:    t.Installment,
+		Interest:       t.Interest,
+		AssetName:      t.AssetName,
+		Tenor:          t.Tenor,
+		Channel:        t.Channel,
+		Status:         t.Status,
+		CreatedAt:      t.CreatedAt,
+		CancelledAt:    t.CancelledAt,
+		CancelReason:   t.CancelReason,
+	}
+}
+
+// NewPaymentEvent builds the payment.received event of a posted payment on a
+// contract booked through channel.
+func NewPaymentEvent(p *model.Payment, channel string, earlySettlement bool) (*model.OutboxEvent, error) {
+	event, err := NewEvent(model.EventPaymentReceived, p.ContractNumber, PaymentEventData{
+		PaymentID:       p.ID,
+		ContractNumber:  p.ContractNumber,
+		Amount:          p.Amount,
+		PrincipalPaid:   p.PrincipalPaid,
+		InterestPaid:    p.InterestPaid,
+		PenaltyPaid:     p.PenaltyPaid,
+		Reference:       p.Reference,
+		EarlySettlement: earlySettlement,
+		PaidAt:          p.PaidAt,
+	}, p.PaidAt)
+	if err != nil {
+		return nil, err
+	}
+	event.Channel = channel
+	return event, nil
+}
+
+// NewLimitEvent builds the limit.changed event of one set of limit adjustments
+// to a customer. A limit belongs to no partner, so the event has no channel.
+func NewLimitEvent(nik string, adjustments []model.LimitAdjustment, at time.Time) (*model.OutboxEvent, error) {
+	data := LimitEventData{CustomerNIK: nik, Limits: make([]LimitChangeData, 0, len(adjustments))}
+	for _, a := range adjustments {
+		data.ChangedBy, data.Reason = a.ChangedBy, a.Reason
+		data.Limits = append(data.Limits, LimitChangeData{Tenor: a.Tenor, PreviousAmount: a.PreviousAmount, NewAmount: a.NewAmount})
+	}
+	return NewEvent(model.EventLimitChanged, nik, data, at)
+}
+
+// NewEvent builds an outbox event with a fresh event ID that is due for relay
+// immediately.
+func NewEvent(eventType, aggregateID string, data interface{}, at time.Time) (*model.OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newEventID()
+	if err != nil {
+		return nil, err
+	}
+	return &model.OutboxEvent{
+		EventID:       id,
+		EventType:     eventType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		OccurredAt:    at,
+		NextAttemptAt: at,
+	}, nil
+}
+
+func NewEventEnvelope(e *model.OutboxEvent) EventEnvelope {
+	return EventEnvelope{
+		ID:          e.EventID,
+		Type:        e.EventType,
+		AggregateID: e.AggregateID,
+		Channel:     e.Channel,
+		OccurredAt:  e.OccurredAt,
+		Data:        json.RawMessage(e.Payload),
+	}
+}
+
+// newEventID returns a random (version 4) UUID.
+func newEventID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
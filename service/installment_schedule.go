@@ -0,0 +1,91 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"multifinance/model"
+)
+
+// BuildFlatSchedule spreads principal and interest evenly over tenor monthly
+// periods. Rounding remainders go to the last period so the totals add up exactly.
+func BuildFlatSchedule(contractNumber string, principal, interest int64, tenor int, start time.Time) []model.Installment {
+	if tenor <= 0 {
+		return nil
+	}
+
+	principalPerPeriod := principal / int64(tenor)
+	interestPerPeriod := interest / int64(tenor)
+
+	schedule := make([]model.Installment, 0, tenor)
+	outstanding := principal
+	for period := 1; period <= tenor; period++ {
+		p, i := principalPerPeriod, interestPerPeriod
+		if period == tenor {
+			p = outstanding
+			i = interest - interestPerPeriod*int64(tenor-1)
+		}
+		outstanding -= p
+
+		schedule = append(schedule, model.Installment{
+			ContractNumber:     contractNumber,
+			Period:             period,
+			DueDate:            AddMonths(start, period),
+			Principal:          p,
+			Interest:           i,
+			Amount:             p + i,
+			OutstandingBalance: outstanding,
+			Status:             model.InstallmentStatusUnpaid,
+		})
+	}
+	return schedule
+}
+
+// BuildAnnuitySchedule builds an effective-rate schedule with a constant monthly
+// installment. Each period's interest is charged on the outstanding principal; the
+// last period settles whatever principal remains after rounding.
+func BuildAnnuitySchedule(contractNumber string, principal int64, monthlyRate float64, tenor int, start time.Time) []model.Installment {
+	if tenor <= 0 {
+		return nil
+	}
+	if monthlyRate <= 0 {
+		return BuildFlatSchedule(contractNumber, principal, 0, tenor, start)
+	}
+
+	installment := int64(math.Ceil(float64(principal) * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(tenor)))))
+
+	schedule := make([]model.Installment, 0, tenor)
+	outstanding := principal
+	for period := 1; period <= tenor; period++ {
+		i := int64(math.Round(float64(outstanding) * monthlyRate))
+		p := installment - i
+		if period == tenor || p > outstanding {
+			p = outstanding
+		}
+		outstanding -= p
+
+		schedule = append(schedule, model.Installment{
+			ContractNumber:     contractNumber,
+			Period:             period,
+			DueDate:            AddMonths(start, period),
+			Principal:          p,
+			Interest:           i,
+			Amount:             p + i,
+			OutstandingBalance: outstanding,
+			Status:             model.InstallmentStatusUnpaid,
+		})
+	}
+	return schedule
+}
+
+// AddMonths returns the same day n months later, clamped to the end of the month
+// (e.g. 31 January + 1 month is 28/29 February), truncated to a date.
+func AddMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	firstOfTarget := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, 0, 0, 0, 0, t.Location())
+}
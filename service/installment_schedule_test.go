@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFlatSchedule(t *testing.T) {
+	start := time.Date(2025, 1, 31, 14, 30, 0, 0, time.UTC)
+	schedule := BuildFlatSchedule("CON-1", 1050000, 100000, 3, start)
+
+	assert.Len(t, schedule, 3)
+
+	var totalPrincipal, totalInterest int64
+	for _, item := range schedule {
+		totalPrincipal += item.Principal
+		totalInterest += item.Interest
+		assert.Equal(t, item.Principal+item.Interest, item.Amount)
+		assert.Equal(t, "CON-1", item.ContractNumber)
+	}
+	assert.Equal(t, int64(1050000), totalPrincipal)
+	assert.Equal(t, int64(100000), totalInterest)
+
+	assert.Equal(t, int64(33333), schedule[0].Interest)
+	assert.Equal(t, int64(33334), schedule[2].Interest)
+	assert.Equal(t, int64(700000), schedule[0].OutstandingBalance)
+	assert.Equal(t, int64(0), schedule[2].OutstandingBalance)
+
+	// Due dates are clamped to the end of shorter months.
+	assert.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), schedule[1].DueDate)
+	assert.Equal(t, time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), schedule[2].DueDate)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/config"
+)
+
+// ErrInvalidToken is returned for a bearer token that is malformed, badly
+// signed, expired or meant for another issuer or audience.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenVerifier authenticates staff bearer tokens.
+type TokenVerifier interface {
+	Verify(token string, now time.Time) (*auth.Principal, error)
+}
+
+type jwtVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+	leeway    time.Duration
+}
+
+// NewJWTVerifier verifies HS256 tokens against the configured secret and RS256
+// tokens against the configured public key. An algorithm without a configured
+// key is rejected, so a token cannot pick a weaker check than intended.
+func NewJWTVerifier(cfg config.AuthConfig) (TokenVerifier, error) {
+	v := &jwtVerifier{
+		secret:   []byte(cfg.JWTSecret),
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+		leeway:   cfg.JWTLeeway,
+	}
+	if cfg.JWTPublicKeyFile != "" {
+		pemBytes, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		if v.publicKey, err = parseRSAPublicKey(pemBytes); err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+	}
+	return v, nil
+}
+
+func parseRSAPublicKey(pemBytes []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not RSA")
+	}
+	return key, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Name      string          `json:"name"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Roles     []string        `json:"roles"`
+}
+
+func (v *jwtVerifier) Verify(token string, now time.Time) (*auth.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !v.verifySignature(header.Alg, parts[0]+"."+parts[1], signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(v.leeway)) {
+		return nil, ErrInvalidToken
+	}
+	if claims.NotBefore != nil && now.Add(v.leeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return nil, ErrInvalidToken
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, ErrInvalidToken
+	}
+	if v.audience != "" && !audienceContains(claims.Audience, v.audience) {
+		return nil, ErrInvalidToken
+	}
+
+	return &auth.Principal{
+		Type:  auth.PrincipalStaff,
+		ID:    claims.Subject,
+		Name:  claims.Name,
+		Roles: claims.Roles,
+	}, nil
+}
+
+func (v *jwtVerifier) verifySignature(alg, signingInput string, signature []byte) bool {
+	switch alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signingInput)) // nolint:errcheck
+		return hmac.Equal(signature, mac.Sum(nil))
+	case "RS256":
+		if v.publicKey == nil {
+			return false
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// audienceContains checks the aud claim, which may be a string or a list.
+func audienceContains(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return false
+	}
+	for _, a := range list {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
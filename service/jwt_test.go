@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testJWTSecret = "rahasia-jwt-yang-panjangnya-32-karakter"
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	raw, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	verifier, err := NewJWTVerifier(config.AuthConfig{JWTSecret: testJWTSecret, JWTIssuer: "sso", JWTAudience: "multifinance", JWTLeeway: 30 * time.Second})
+	assert.NoError(t, err)
+
+	valid := map[string]interface{}{
+		"sub": "budi", "name": "Budi", "iss": "sso", "aud": []string{"multifinance", "other"},
+		"exp": now.Add(time.Hour).Unix(), "roles": []string{"credit-ops"},
+	}
+	p, err := verifier.Verify(signHS256(t, testJWTSecret, valid), now)
+	assert.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Type: auth.PrincipalStaff, ID: "budi", Name: "Budi", Roles: []string{"credit-ops"}}, p)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong secret", signHS256(t, "secret-lain-yang-juga-32-karakter-xx", valid)},
+		{"expired beyond leeway", signHS256(t, testJWTSecret, map[string]interface{}{"sub": "budi", "iss": "sso", "aud": "multifinance", "exp": now.Add(-time.Minute).Unix()})},
+		{"not yet valid", signHS256(t, testJWTSecret, map[string]interface{}{"sub": "budi", "iss": "sso", "aud": "multifinance", "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Hour).Unix()})},
+		{"missing exp", signHS256(t, testJWTSecret, map[string]interface{}{"sub": "budi", "iss": "sso", "aud": "multifinance"})},
+		{"wrong issuer", signHS256(t, testJWTSecret, map[string]interface{}{"sub": "budi", "iss": "lain", "aud": "multifinance", "exp": now.Add(time.Hour).Unix()})},
+		{"wrong audience", signHS256(t, testJWTSecret, map[string]interface{}{"sub": "budi", "iss": "sso", "aud": "lain", "exp": now.Add(time.Hour).Unix()})},
+		{"alg none", encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, valid) + "."},
+		{"malformed", "bukan-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.token, now)
+			assert.Equal(t, ErrInvalidToken, err)
+		})
+	}
+}
+
+func TestJWTVerifier_RS256(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pub")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	verifier, err := NewJWTVerifier(config.AuthConfig{JWTPublicKeyFile: path})
+	assert.NoError(t, err)
+
+	claims := map[string]interface{}{"sub": "sari", "exp": now.Add(time.Hour).Unix(), "roles": []string{"finance"}}
+	p, err := verifier.Verify(signRS256(t, key, claims), now)
+	assert.NoError(t, err)
+	assert.Equal(t, "sari", p.ID)
+	assert.True(t, p.HasRole("finance"))
+
+	// HS256 is not configured, so a token cannot downgrade to it.
+	_, err = verifier.Verify(signHS256(t, "", claims), now)
+	assert.Equal(t, ErrInvalidToken, err)
+}
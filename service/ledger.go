@@ -0,0 +1,130 @@
+package service
+
+import (
+	"time"
+
+	"multifinance/model"
+)
+
+// The functions below turn each money movement into balanced journal entries.
+// Amounts that are zero are left out, so an event with nothing to post yields
+// no entry.
+
+// DisbursementEntries books a new contract: OTR paid out to the dealer and the
+// admin fee recognised as income, both financed by the customer.
+func DisbursementEntries(t *model.Transaction, at time.Time) []model.JournalEntry {
+	return compact(
+		entry(model.JournalEntryDisbursement, t.ContractNumber, "", at,
+			debit(model.AccountLoanReceivable, t.OTR), credit(model.AccountCash, t.OTR)),
+		entry(model.JournalEntryAdminFee, t.ContractNumber, "", at,
+			debit(model.AccountLoanReceivable, t.AdminFee), credit(model.AccountAdminFeeIncome, t.AdminFee)),
+	)
+}
+
+// CancellationEntries reverses DisbursementEntries for a voided contract.
+func CancellationEntries(t *model.Transaction, at time.Time) []model.JournalEntry {
+	return compact(entry(model.JournalEntryCancellation, t.ContractNumber, "", at,
+		debit(model.AccountCash, t.OTR),
+		debit(model.AccountAdminFeeIncome, t.AdminFee),
+		credit(model.AccountLoanReceivable, t.OTR+t.AdminFee),
+	))
+}
+
+// InterestAccrualEntries recognises interest income that has become receivable.
+func InterestAccrualEntries(contractNumber string, amount int64, at time.Time) []model.JournalEntry {
+	return compact(entry(model.JournalEntryInterestAccrual, contractNumber, "", at,
+		debit(model.AccountInterestReceivable, amount), credit(model.AccountInterestIncome, amount)))
+}
+
+// PenaltyAccrualEntries moves the penalty receivable of a contract by delta: a
+// positive delta recognises late penalty income as it is assessed, a negative
+// one reverses penalty that is no longer owed.
+func PenaltyAccrualEntries(contractNumber string, delta int64, at time.Time) []model.JournalEntry {
+	if delta < 0 {
+		return compact(entry(model.JournalEntryPenaltyAccrual, contractNumber, "", at,
+			debit(model.AccountPenaltyIncome, -delta), credit(model.AccountPenaltyReceivable, -delta)))
+	}
+	return compact(entry(model.JournalEntryPenaltyAccrual, contractNumber, "", at,
+		debit(model.AccountPenaltyReceivable, delta), credit(model.AccountPenaltyIncome, delta)))
+}
+
+// RepaymentEntries books cash received against principal and accrued interest,
+// and separately the collection of the penalty receivable. The penalty must have
+// been accrued with PenaltyAccrualEntries first.
+func RepaymentEntries(p *model.Payment) []model.JournalEntry {
+	return compact(
+		entry(model.JournalEntryRepayment, p.ContractNumber, p.Reference, p.PaidAt,
+			debit(model.AccountCash, p.PrincipalPaid+p.InterestPaid),
+			credit(model.AccountLoanReceivable, p.PrincipalPaid),
+			credit(model.AccountInterestReceivable, p.InterestPaid),
+		),
+		entry(model.JournalEntryPenalty, p.ContractNumber, p.Reference, p.PaidAt,
+			debit(model.AccountCash, p.PenaltyPaid), credit(model.AccountPenaltyReceivable, p.PenaltyPaid)),
+	)
+}
+
+// SettlementEntries books an early payoff: the repayment itself, including the
+// penalty it collects, the waived interest, and the fee, which is recognised
+// when collected.
+func SettlementEntries(s *model.Settlement, p *model.Payment) []model.JournalEntry {
+	entries := RepaymentEntries(p)
+	return append(entries, compact(
+		entry(model.JournalEntryInterestRebate, s.ContractNumber, p.Reference, p.PaidAt,
+			debit(model.AccountInterestRebate, s.Discount), credit(model.AccountInterestReceivable, s.Discount)),
+		entry(model.JournalEntrySettlementFee, s.ContractNumber, p.Reference, p.PaidAt,
+			debit(model.AccountCash, s.Fee), credit(model.AccountSettlementFeeIncome, s.Fee)),
+	)...)
+}
+
+// AccrueInterest raises AccruedInterest of each period to what is now earned and
+// returns the total newly accrued. A period's interest is earned in full once it
+// falls due, or as far as it has been paid ahead of time. Interest waived by
+// cancellation or early settlement is never earned.
+func AccrueInterest(installments []model.Installment, asOf time.Time) int64 {
+	today := dateOf(asOf)
+	var total int64
+	for n := range installments {
+		i := &installments[n]
+		if i.Status == model.InstallmentStatusCancelled {
+			continue
+		}
+		earned := i.PaidInterest
+		if i.Status != model.InstallmentStatusSettled && !dateOf(i.DueDate).After(today) {
+			earned = i.Interest
+		}
+		if earned > i.AccruedInterest {
+			total += earned - i.AccruedInterest
+			i.AccruedInterest = earned
+		}
+	}
+	return total
+}
+
+func entry(entryType, contractNumber, reference string, at time.Time, lines ...model.JournalLine) model.JournalEntry {
+	e := model.JournalEntry{EntryType: entryType, ContractNumber: contractNumber, Reference: reference, PostedAt: at}
+	for _, l := range lines {
+		if l.Debit != 0 || l.Credit != 0 {
+			e.Lines = append(e.Lines, l)
+		}
+	}
+	return e
+}
+
+func debit(account string, amount int64) model.JournalLine {
+	return model.JournalLine{AccountCode: account, Debit: amount}
+}
+
+func credit(account string, amount int64) model.JournalLine {
+	return model.JournalLine{AccountCode: account, Credit: amount}
+}
+
+// compact drops entries left without lines.
+func compact(entries ...model.JournalEntry) []model.JournalEntry {
+	result := make([]model.JournalEntry, 0, len(entries))
+	for _, e := range entries {
+		if len(e.Lines) > 0 {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalEntries_Balanced(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	contract := &model.Transaction{ContractNumber: "CON-1", OTR: 10000000, AdminFee: 500000}
+	payment := &model.Payment{ContractNumber: "CON-1", Amount: 1035000, PrincipalPaid: 900000, InterestPaid: 120000, PenaltyPaid: 15000, PaidAt: at}
+	settlement := &model.Settlement{ContractNumber: "CON-1", Discount: 30000, Penalty: 15000, Fee: 0}
+
+	cases := map[string][]model.JournalEntry{
+		"disbursement": DisbursementEntries(contract, at),
+		"cancellation": CancellationEntries(contract, at),
+		"accrual":      InterestAccrualEntries("CON-1", 120000, at),
+		"penalty":      PenaltyAccrualEntries("CON-1", 15000, at),
+		"reversal":     PenaltyAccrualEntries("CON-1", -5000, at),
+		"repayment":    RepaymentEntries(payment),
+		"settlement":   SettlementEntries(settlement, payment),
+	}
+	for name, entries := range cases {
+		assert.NotEmpty(t, entries, name)
+		for _, e := range entries {
+			assert.True(t, e.Balanced(), "%s: %s", name, e.EntryType)
+			assert.Equal(t, "CON-1", e.ContractNumber)
+		}
+	}
+
+	// A zero fee posts nothing.
+	var types []string
+	for _, e := range cases["settlement"] {
+		types = append(types, e.EntryType)
+	}
+	assert.Equal(t, []string{model.JournalEntryRepayment, model.JournalEntryPenalty, model.JournalEntryInterestRebate}, types)
+	assert.Empty(t, InterestAccrualEntries("CON-1", 0, at))
+}
+
+func TestPenaltyAccrual_ClearedBySettlement(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	payment := &model.Payment{ContractNumber: "CON-1", Amount: 1015000, PrincipalPaid: 1000000, PenaltyPaid: 15000, PaidAt: at}
+	settlement := &model.Settlement{ContractNumber: "CON-1", Penalty: 15000}
+
+	// Accrued on two days, one of them corrected down, then collected.
+	var entries []model.JournalEntry
+	entries = append(entries, PenaltyAccrualEntries("CON-1", 10000, at)...)
+	entries = append(entries, PenaltyAccrualEntries("CON-1", 8000, at)...)
+	entries = append(entries, PenaltyAccrualEntries("CON-1", -3000, at)...)
+	entries = append(entries, SettlementEntries(settlement, payment)...)
+
+	balances := map[string]int64{}
+	for _, e := range entries {
+		assert.True(t, e.Balanced(), e.EntryType)
+		for _, l := range e.Lines {
+			balances[l.AccountCode] += l.Debit - l.Credit
+		}
+	}
+	assert.Equal(t, int64(0), balances[model.AccountPenaltyReceivable])
+	assert.Equal(t, int64(-15000), balances[model.AccountPenaltyIncome])
+	assert.Empty(t, PenaltyAccrualEntries("CON-1", 0, at))
+}
+
+func TestAccrueInterest(t *testing.T) {
+	asOf := time.Date(2026, 3, 15, 18, 0, 0, 0, time.UTC)
+	installments := []model.Installment{
+		// due and already accrued by an earlier run
+		{Period: 1, DueDate: time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC), Interest: 50000, AccruedInterest: 50000, Status: model.InstallmentStatusPaid},
+		// falls due today
+		{Period: 2, DueDate: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), Interest: 50000, Status: model.InstallmentStatusUnpaid},
+		// paid ahead in part
+		{Period: 3, DueDate: time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC), Interest: 50000, PaidInterest: 20000, Status: model.InstallmentStatusPartial},
+		// not due yet
+		{Period: 4, DueDate: time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC), Interest: 50000, Status: model.InstallmentStatusUnpaid},
+	}
+
+	assert.Equal(t, int64(70000), AccrueInterest(installments, asOf))
+	assert.Equal(t, []int64{50000, 50000, 20000, 0}, []int64{
+		installments[0].AccruedInterest, installments[1].AccruedInterest, installments[2].AccruedInterest, installments[3].AccruedInterest,
+	})
+	// A second run accrues nothing new.
+	assert.Equal(t, int64(0), AccrueInterest(installments, asOf))
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+)
+
+// LimitPolicy computes the per-tenor credit lines a customer is entitled to.
+// Implementations fill CreditLine only; the available amount is derived from
+// the change in the line when it is applied. They must return one row per tenor
+// they support so that a recompute can also lower or zero out limits.
+type LimitPolicy interface {
+	Compute(customer *model.Customer, now time.Time) ([]model.CustomerLimit, error)
+}
+
+// DefaultLimitPolicy grants a multiple of the monthly salary per tenor, capped
+// at MaxLimit and rounded down. Customers outside the allowed age range get
+// zero limits.
+type DefaultLimitPolicy struct {
+	cfg config.LimitPolicyConfig
+}
+
+func NewDefaultLimitPolicy(cfg config.LimitPolicyConfig) LimitPolicy {
+	return &DefaultLimitPolicy{cfg: cfg}
+}
+
+func (p *DefaultLimitPolicy) Compute(customer *model.Customer, now time.Time) ([]model.CustomerLimit, error) {
+	birthDate, err := customer.ParseBirthDate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid birth date %q: %w", customer.BirthDate, err)
+	}
+
+	age := ageAt(birthDate, now)
+	eligible := age >= p.cfg.MinAge && (p.cfg.MaxAge <= 0 || age <= p.cfg.MaxAge)
+
+	tenors := make([]int, 0, len(p.cfg.SalaryMultipliers))
+	for tenor := range p.cfg.SalaryMultipliers {
+		tenors = append(tenors, tenor)
+	}
+	sort.Ints(tenors)
+
+	limits := make([]model.CustomerLimit, 0, len(tenors))
+	for _, tenor := range tenors {
+		var amount int64
+		if eligible {
+			amount = int64(math.Floor(float64(customer.Salary) * p.cfg.SalaryMultipliers[tenor]))
+			if p.cfg.MaxLimit > 0 && amount > p.cfg.MaxLimit {
+				amount = p.cfg.MaxLimit
+			}
+			if p.cfg.LimitRounding > 0 {
+				amount -= amount % p.cfg.LimitRounding
+			}
+		}
+		limits = append(limits, model.CustomerLimit{
+			CustomerNIK: customer.NIK,
+			Tenor:       tenor,
+			CreditLine:  amount,
+		})
+	}
+
+	return limits, nil
+}
+
+// ageAt returns the age in completed years on the given date.
+func ageAt(birthDate, now time.Time) int {
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultLimitPolicy_Compute(t *testing.T) {
+	policy := NewDefaultLimitPolicy(config.LimitPolicyConfig{
+		SalaryMultipliers: map[int]float64{3: 1.5, 1: 0.5, 12: 20},
+		MaxLimit:          100000000,
+		LimitRounding:     10000,
+		MinAge:            21,
+		MaxAge:            60,
+	})
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		customer model.Customer
+		expected []int64
+	}{
+		{
+			name:     "multiplier, rounding and cap",
+			customer: model.Customer{NIK: "3171010101900001", BirthDate: "1990-01-01", Salary: 7333333},
+			expected: []int64{3660000, 10990000, 100000000},
+		},
+		{
+			name:     "timestamp birth date from driver",
+			customer: model.Customer{NIK: "3171010101900001", BirthDate: "1990-01-01T00:00:00Z", Salary: 1000000},
+			expected: []int64{500000, 1500000, 20000000},
+		},
+		{
+			name:     "under minimum age the day before birthday",
+			customer: model.Customer{NIK: "3171010206050001", BirthDate: "2005-06-02", Salary: 10000000},
+			expected: []int64{0, 0, 0},
+		},
+		{
+			name:     "above maximum age",
+			customer: model.Customer{NIK: "3171010101600001", BirthDate: "1960-01-01", Salary: 10000000},
+			expected: []int64{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := policy.Compute(&tt.customer, now)
+			assert.NoError(t, err)
+
+			amounts := make([]int64, 0, len(limits))
+			for _, l := range limits {
+				assert.Equal(t, tt.customer.NIK, l.CustomerNIK)
+				assert.Zero(t, l.LimitAmount)
+				amounts = append(amounts, l.CreditLine)
+			}
+			assert.Equal(t, tt.expected, amounts)
+			assert.Equal(t, []int{1, 3, 12}, []int{limits[0].Tenor, limits[1].Tenor, limits[2].Tenor})
+		})
+	}
+}
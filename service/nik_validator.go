@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	GenderMale   = "M"
+	GenderFemale = "F"
+
+	nikLength = 16
+	// femaleDayOffset is added to the birth day segment of a woman's NIK.
+	femaleDayOffset = 40
+)
+
+// provinceCodes lists the province codes issued by Kemendagri.
+var provinceCodes = map[string]bool{
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true,
+	"21": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "36": true,
+	"51": true, "52": true, "53": true,
+	"61": true, "62": true, "63": true, "64": true, "65": true,
+	"71": true, "72": true, "73": true, "74": true, "75": true, "76": true,
+	"81": true, "82": true,
+	"91": true, "92": true, "93": true, "94": true, "95": true, "96": true,
+}
+
+// NIK is the decoded form of a 16-digit Nomor Induk Kependudukan:
+// PPRRDD-DDMMYY-SSSS (province, regency, district, birth date, serial).
+type NIK struct {
+	ProvinceCode string
+	RegencyCode  string
+	DistrictCode string
+	BirthDay     int
+	BirthMonth   int
+	BirthYear    int // two-digit year as encoded in the NIK
+	Gender       string
+	Serial       string
+}
+
+// NIKError describes which segment of a NIK failed validation.
+type NIKError struct {
+	Segment string
+	Message string
+}
+
+func (e *NIKError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Segment, e.Message)
+}
+
+// ParseNIK checks the structure of a NIK and decodes its segments.
+func ParseNIK(nik string) (*NIK, error) {
+	if len(nik) != nikLength {
+		return nil, &NIKError{Segment: "format", Message: "nik must be exactly 16 digits"}
+	}
+	for _, r := range nik {
+		if r < '0' || r > '9' {
+			return nil, &NIKError{Segment: "format", Message: "nik must contain digits only"}
+		}
+	}
+
+	n := &NIK{
+		ProvinceCode: nik[0:2],
+		RegencyCode:  nik[2:4],
+		DistrictCode: nik[4:6],
+		Serial:       nik[12:16],
+		Gender:       GenderMale,
+	}
+
+	if !provinceCodes[n.ProvinceCode] {
+		return nil, &NIKError{Segment: "province code (digits 1-2)", Message: n.ProvinceCode + " is not a known province"}
+	}
+	if n.RegencyCode == "00" {
+		return nil, &NIKError{Segment: "regency code (digits 3-4)", Message: "regency code cannot be 00"}
+	}
+	if n.DistrictCode == "00" {
+		return nil, &NIKError{Segment: "district code (digits 5-6)", Message: "district code cannot be 00"}
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+	year, _ := strconv.Atoi(nik[10:12])
+	if day > femaleDayOffset {
+		day -= femaleDayOffset
+		n.Gender = GenderFemale
+	}
+	if day < 1 || day > 31 {
+		return nil, &NIKError{Segment: "birth day (digits 7-8)", Message: "must be 01-31 for men or 41-71 for women"}
+	}
+	if month < 1 || month > 12 {
+		return nil, &NIKError{Segment: "birth month (digits 9-10)", Message: "must be 01-12"}
+	}
+	if !validDayInMonth(day, month, year) {
+		return nil, &NIKError{Segment: "birth date (digits 7-12)", Message: fmt.Sprintf("day %02d does not exist in month %02d", day, month)}
+	}
+	n.BirthDay, n.BirthMonth, n.BirthYear = day, month, year
+
+	if n.Serial == "0000" {
+		return nil, &NIKError{Segment: "serial number (digits 13-16)", Message: "serial number cannot be 0000"}
+	}
+
+	return n, nil
+}
+
+// MatchesBirthDate reports whether the birth date encoded in the NIK matches t.
+func (n *NIK) MatchesBirthDate(t time.Time) bool {
+	return n.BirthDay == t.Day() && n.BirthMonth == int(t.Month()) && n.BirthYear == t.Year()%100
+}
+
+// validDayInMonth checks the day against the month length. The century is not
+// encoded, so 29 February is accepted if the year is a leap year in either century.
+func validDayInMonth(day, month, yy int) bool {
+	for _, century := range []int{1900, 2000} {
+		t := time.Date(century+yy, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if t.Day() == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNIK(t *testing.T) {
+	tests := []struct {
+		name        string
+		nik         string
+		segment     string
+		expectedDay int
+		expectedSex string
+	}{
+		{name: "valid male", nik: "3171010101900001", expectedDay: 1, expectedSex: GenderMale},
+		{name: "valid female", nik: "3273014505950002", expectedDay: 5, expectedSex: GenderFemale},
+		{name: "leap day", nik: "3171012902000003", expectedDay: 29, expectedSex: GenderMale},
+		{name: "too short", nik: "317101010190001", segment: "format"},
+		{name: "non digit", nik: "31710101019000A1", segment: "format"},
+		{name: "unknown province", nik: "9971010101900001", segment: "province code (digits 1-2)"},
+		{name: "zero regency", nik: "3100010101900001", segment: "regency code (digits 3-4)"},
+		{name: "zero district", nik: "3171000101900001", segment: "district code (digits 5-6)"},
+		{name: "birth day out of range", nik: "3171013501900001", segment: "birth day (digits 7-8)"},
+		{name: "birth month out of range", nik: "3171010113900001", segment: "birth month (digits 9-10)"},
+		{name: "non existent date", nik: "3171013102900001", segment: "birth date (digits 7-12)"},
+		{name: "zero serial", nik: "3171010101900000", segment: "serial number (digits 13-16)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nik, err := ParseNIK(tt.nik)
+			if tt.segment != "" {
+				nikErr, ok := err.(*NIKError)
+				if assert.True(t, ok, "expected *NIKError, got %v", err) {
+					assert.Equal(t, tt.segment, nikErr.Segment)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDay, nik.BirthDay)
+			assert.Equal(t, tt.expectedSex, nik.Gender)
+		})
+	}
+}
+
+func TestNIK_MatchesBirthDate(t *testing.T) {
+	nik, err := ParseNIK("3273014505950002")
+	assert.NoError(t, err)
+
+	assert.True(t, nik.MatchesBirthDate(time.Date(1995, 5, 5, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, nik.MatchesBirthDate(time.Date(1995, 5, 6, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestValidateCreateCustomerRequest_NIKCrossCheck(t *testing.T) {
+	svc := NewValidateService()
+	base := dto.CreateCustomerRequest{
+		NIK:         "3273014505950002",
+		FullName:    "Annisa",
+		LegalName:   "Annisa Rahma",
+		BirthPlace:  "Bandung",
+		BirthDate:   "1995-05-05",
+		Salary:      15000000,
+		PhotoKTP:    "ktp_annisa.jpg",
+		PhotoSelfie: "selfie_annisa.jpg",
+	}
+
+	valid := base
+	assert.NoError(t, svc.ValidateCreateCustomerRequest(&valid))
+
+	wrongDate := base
+	wrongDate.BirthDate = "1995-05-06"
+	err := svc.ValidateCreateCustomerRequest(&wrongDate)
+	if assert.Error(t, err) {
+		errs := err.(interface{ GetErrors() []dto.ValidationError }).GetErrors()
+		assert.Equal(t, "nik", errs[0].Field)
+		assert.Contains(t, errs[0].Message, "birth date (digits 7-12)")
+	}
+
+	wrongGender := base
+	wrongGender.Gender = GenderMale
+	assert.Error(t, svc.ValidateCreateCustomerRequest(&wrongGender))
+}
+
+func TestValidateCustomerNIK(t *testing.T) {
+	tests := []struct {
+		name     string
+		customer model.Customer
+		valid    bool
+	}{
+		{
+			name:     "matches the stored data",
+			customer: model.Customer{NIK: "3273014505950002", BirthDate: "1995-05-05", Gender: GenderFemale},
+			valid:    true,
+		},
+		{
+			name:     "birth date read back as a timestamp",
+			customer: model.Customer{NIK: "3273014505950002", BirthDate: "1995-05-05T00:00:00Z"},
+			valid:    true,
+		},
+		{
+			name:     "birth date does not match",
+			customer: model.Customer{NIK: "3273014505950002", BirthDate: "1995-05-06T00:00:00Z"},
+		},
+		{
+			name:     "gender does not match",
+			customer: model.Customer{NIK: "3273014505950002", BirthDate: "1995-05-05", Gender: GenderMale},
+		},
+		{
+			name:     "malformed NIK saved before validation existed",
+			customer: model.Customer{NIK: "1234567890123456", BirthDate: "1995-05-05"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCustomerNIK(&tt.customer)
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				errs := err.(interface{ GetErrors() []dto.ValidationError }).GetErrors()
+				assert.Equal(t, "customer_nik", errs[0].Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"multifinance/config"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+)
+
+const (
+	PricingMethodFlat    = "flat"
+	PricingMethodAnnuity = "annuity"
+
+	mismatchPolicyOverride = "override"
+)
+
+var ErrTenorNotPriced = errors.New("no product rate configured for tenor")
+
+// Quote is the server-side price of a transaction. Principal is the financed
+// amount (OTR plus admin fee) and Installment is the regular monthly amount.
+type Quote struct {
+	Method      string
+	MonthlyRate float64
+	Principal   int64
+	Interest    int64
+	Installment int64
+	Schedule    []model.Installment
+}
+
+type PricingService interface {
+	Quote(otr, adminFee int64, tenor int, start time.Time) (*Quote, error)
+	// Reconcile compares client-supplied amounts with the quote. A zero amount means
+	// the client left it to the server. Depending on the configured policy a mismatch
+	// is either returned as a validation error or silently overridden.
+	Reconcile(quote *Quote, installment, interest int64) error
+}
+
+type pricingService struct {
+	cfg config.PricingConfig
+}
+
+func NewPricingService(cfg config.PricingConfig) PricingService {
+	return &pricingService{cfg: cfg}
+}
+
+func (s *pricingService) Quote(otr, adminFee int64, tenor int, start time.Time) (*Quote, error) {
+	rate, ok := s.cfg.MonthlyRates[tenor]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrTenorNotPriced, tenor)
+	}
+
+	principal := otr + adminFee
+	quote := &Quote{
+		Method:      s.cfg.Method,
+		MonthlyRate: rate,
+		Principal:   principal,
+	}
+
+	switch s.cfg.Method {
+	case PricingMethodAnnuity:
+		quote.Schedule = BuildAnnuitySchedule("", principal, rate, tenor, start)
+		for _, item := range quote.Schedule {
+			quote.Interest += item.Interest
+		}
+	default:
+		quote.Interest = int64(math.Round(float64(principal) * rate * float64(tenor)))
+		quote.Schedule = BuildFlatSchedule("", principal, quote.Interest, tenor, start)
+	}
+	quote.Installment = quote.Schedule[0].Amount
+
+	return quote, nil
+}
+
+func (s *pricingService) Reconcile(quote *Quote, installment, interest int64) error {
+	if s.cfg.MismatchPolicy == mismatchPolicyOverride {
+		return nil
+	}
+
+	var validationErrs []dto.ValidationError
+	if installment != 0 && abs(installment-quote.Installment) > s.cfg.Tolerance {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "installment",
+			Message: fmt.Sprintf("installment does not match server-side pricing (expected %d)", quote.Installment),
+		})
+	}
+	if interest != 0 && abs(interest-quote.Interest) > s.cfg.Tolerance {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "interest",
+			Message: fmt.Sprintf("interest does not match server-side pricing (expected %d)", quote.Interest),
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+	return nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
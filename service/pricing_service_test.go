@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"multifinance/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingService_Quote(t *testing.T) {
+	start := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	rates := map[int]float64{3: 0.022, 12: 0.018}
+
+	t.Run("flat", func(t *testing.T) {
+		svc := NewPricingService(config.PricingConfig{Method: PricingMethodFlat, MonthlyRates: rates})
+
+		quote, err := svc.Quote(1000000, 50000, 3, start)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1050000), quote.Principal)
+		assert.Equal(t, int64(69300), quote.Interest)
+		assert.Equal(t, int64(373100), quote.Installment)
+		assert.Len(t, quote.Schedule, 3)
+	})
+
+	t.Run("annuity", func(t *testing.T) {
+		svc := NewPricingService(config.PricingConfig{Method: PricingMethodAnnuity, MonthlyRates: rates})
+
+		quote, err := svc.Quote(12000000, 0, 12, start)
+
+		assert.NoError(t, err)
+		assert.Len(t, quote.Schedule, 12)
+		var principal, interest int64
+		for _, item := range quote.Schedule {
+			principal += item.Principal
+			interest += item.Interest
+		}
+		assert.Equal(t, int64(12000000), principal)
+		assert.Equal(t, quote.Interest, interest)
+		assert.Equal(t, int64(0), quote.Schedule[11].OutstandingBalance)
+		// Annuity interest on a declining balance is below the flat equivalent.
+		assert.Less(t, quote.Interest, int64(12000000*0.018*12))
+	})
+
+	t.Run("tenor not priced", func(t *testing.T) {
+		svc := NewPricingService(config.PricingConfig{Method: PricingMethodFlat, MonthlyRates: rates})
+
+		_, err := svc.Quote(1000000, 0, 6, start)
+
+		assert.ErrorIs(t, err, ErrTenorNotPriced)
+	})
+}
+
+func TestPricingService_Reconcile(t *testing.T) {
+	quote := &Quote{Installment: 373100, Interest: 69300}
+
+	reject := NewPricingService(config.PricingConfig{MismatchPolicy: "reject", Tolerance: 100})
+	assert.NoError(t, reject.Reconcile(quote, 0, 0))
+	assert.NoError(t, reject.Reconcile(quote, 373150, 69300))
+	assert.Error(t, reject.Reconcile(quote, 3, 100000))
+
+	override := NewPricingService(config.PricingConfig{MismatchPolicy: "override"})
+	assert.NoError(t, override.Reconcile(quote, 3, 100000))
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+)
+
+type SettlementCalculator interface {
+	// Quote prices paying off a contract on date: the principal still owed, the
+	// interest accrued up to that date, late penalties, and the configured
+	// termination fee and interest rebate. Interest of later periods is waived.
+	Quote(transaction *model.Transaction, installments []model.Installment, date time.Time) model.Settlement
+	// Close marks every open period as settled with its accrued interest paid, and
+	// returns the periods that changed.
+	Close(quote model.Settlement, transaction *model.Transaction, installments []model.Installment, paidAt time.Time) []model.Installment
+}
+
+type settlementCalculator struct {
+	cfg      config.SettlementConfig
+	assessor DelinquencyAssessor
+}
+
+func NewSettlementCalculator(cfg config.SettlementConfig, assessor DelinquencyAssessor) SettlementCalculator {
+	return &settlementCalculator{cfg: cfg, assessor: assessor}
+}
+
+func (c *settlementCalculator) Quote(transaction *model.Transaction, installments []model.Installment, date time.Time) model.Settlement {
+	day := dateOf(date)
+	quote := model.Settlement{ContractNumber: transaction.ContractNumber, SettlementDate: day}
+
+	for _, accrual := range c.accruals(transaction, installments, day) {
+		quote.RemainingPrincipal += accrual.principal
+		quote.AccruedInterest += accrual.interest
+	}
+
+	quote.Penalty = c.assessor.Assess(transaction.ContractNumber, installments, day).PenaltyAmount
+	quote.Discount = int64(math.Round(float64(quote.AccruedInterest) * c.cfg.InterestRebateRate))
+	quote.Fee = int64(math.Round(float64(quote.RemainingPrincipal) * c.cfg.FeeRate))
+	quote.Total = quote.RemainingPrincipal + quote.AccruedInterest - quote.Discount + quote.Penalty + quote.Fee
+	return quote
+}
+
+func (c *settlementCalculator) Close(quote model.Settlement, transaction *model.Transaction, installments []model.Installment, paidAt time.Time) []model.Installment {
+	var closed []model.Installment
+	for _, accrual := range c.accruals(transaction, installments, quote.SettlementDate) {
+		i := accrual.installment
+		i.PaidPrincipal = i.Principal
+		i.PaidInterest += accrual.interest
+		i.Status = model.InstallmentStatusSettled
+		at := paidAt
+		i.PaidAt = &at
+		closed = append(closed, i)
+	}
+	return closed
+}
+
+type accrual struct {
+	installment model.Installment
+	principal   int64
+	interest    int64
+}
+
+// accruals lists the open periods with the principal still owed and the unpaid
+// interest accrued by day. A period accrues its interest linearly from the
+// previous due date (or the contract date) to its own due date.
+func (c *settlementCalculator) accruals(transaction *model.Transaction, installments []model.Installment, day time.Time) []accrual {
+	var result []accrual
+	periodStart := dateOf(transaction.CreatedAt)
+	for _, i := range installments {
+		due := dateOf(i.DueDate)
+		start := periodStart
+		periodStart = due
+
+		if i.Status == model.InstallmentStatusCancelled {
+			continue
+		}
+		principal, interest := i.Due()
+		if principal+interest == 0 {
+			continue
+		}
+
+		earned := i.Interest
+		if day.Before(due) {
+			earned = 0
+			if elapsed := day.Sub(start); elapsed > 0 {
+				earned = int64(math.Round(float64(i.Interest) * elapsed.Hours() / due.Sub(start).Hours()))
+			}
+		}
+		unpaidInterest := earned - i.PaidInterest
+		if unpaidInterest < 0 {
+			unpaidInterest = 0
+		}
+
+		result = append(result, accrual{installment: i, principal: principal, interest: unpaidInterest})
+	}
+	return result
+}
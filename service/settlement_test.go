@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"multifinance/config"
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettlementCalculator_Quote(t *testing.T) {
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	transaction := &model.Transaction{ContractNumber: "CON-1", CreatedAt: start}
+	schedule := BuildFlatSchedule("CON-1", 3000000, 300000, 3, start)
+	calculator := NewSettlementCalculator(
+		config.SettlementConfig{FeeRate: 0.02, InterestRebateRate: 0.5},
+		NewDelinquencyAssessor(config.DelinquencyConfig{PenaltyMethod: PenaltyMethodFlatPerDay, PenaltyFlatPerDay: 5000}),
+	)
+
+	// Period 1 (due 10 Feb) is 15 days late; period 2 has run 15 of its 28 days.
+	quote := calculator.Quote(transaction, schedule, time.Date(2026, 2, 25, 16, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, time.Date(2026, 2, 25, 0, 0, 0, 0, time.UTC), quote.SettlementDate)
+	assert.Equal(t, int64(3000000), quote.RemainingPrincipal)
+	assert.Equal(t, int64(100000+53571), quote.AccruedInterest)
+	assert.Equal(t, int64(76786), quote.Discount)
+	assert.Equal(t, int64(75000), quote.Penalty)
+	assert.Equal(t, int64(60000), quote.Fee)
+	assert.Equal(t, int64(3000000+153571-76786+75000+60000), quote.Total)
+}
+
+func TestSettlementCalculator_Close(t *testing.T) {
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	transaction := &model.Transaction{ContractNumber: "CON-1", CreatedAt: start}
+	schedule := BuildFlatSchedule("CON-1", 3000000, 300000, 3, start)
+	schedule[0].PaidPrincipal = schedule[0].Principal
+	schedule[0].PaidInterest = schedule[0].Interest
+	schedule[0].Status = model.InstallmentStatusPaid
+	schedule[1].PaidInterest = 100000
+	schedule[1].Status = model.InstallmentStatusPartial
+	calculator := NewSettlementCalculator(config.SettlementConfig{}, NewDelinquencyAssessor(config.DelinquencyConfig{}))
+
+	at := time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)
+	quote := calculator.Quote(transaction, schedule, at)
+	closed := calculator.Close(quote, transaction, schedule, at)
+
+	// Interest already paid ahead is kept, and nothing more accrues on period 2.
+	assert.Equal(t, int64(2000000), quote.RemainingPrincipal)
+	assert.Equal(t, int64(0), quote.AccruedInterest)
+	assert.Len(t, closed, 2)
+	for _, i := range closed {
+		assert.Equal(t, model.InstallmentStatusSettled, i.Status)
+		assert.Equal(t, i.Principal, i.PaidPrincipal)
+		assert.Equal(t, &at, i.PaidAt)
+	}
+	assert.Equal(t, int64(100000), closed[0].PaidInterest)
+	assert.Equal(t, int64(0), closed[1].PaidInterest)
+}
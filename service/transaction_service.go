@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"sync"
 
 	"multifinance/model"
 	"multifinance/repository"
@@ -22,7 +21,6 @@ type TransactionServiceImpl struct {
 	limitRepo       repository.LimitRepository
 	transactionRepo repository.TransactionRepository
 	db              *sqlx.DB
-	mutex           sync.Mutex
 }
 
 // NewTransactionService creates a new TransactionServiceImpl.
@@ -43,9 +41,6 @@ func NewTransactionService(
 // CreateTransaction handles the business logic for creating a new financial transaction.
 // It ensures the operation is atomic by using a database transaction.
 func (s *TransactionServiceImpl) CreateTransaction(ctx context.Context, transaction *model.Transaction, tenor int) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
 	// Begin a new database transaction.
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -54,21 +49,16 @@ func (s *TransactionServiceImpl) CreateTransaction(ctx context.Context, transact
 	// Defer a rollback in case of an error. The rollback will be ignored if the transaction is committed.
 	defer tx.Rollback() // nolint:errcheck
 
-	// Check if the customer has sufficient limit for the transaction.
-	limit, err := s.limitRepo.GetLimit(ctx, transaction.CustomerNIK, tenor)
+	// Deduct the limit with a conditional update so the check and the write are
+	// atomic in the database, across every replica.
+	deducted, err := s.limitRepo.DeductLimit(ctx, tx, transaction.CustomerNIK, tenor, transaction.OTR+transaction.AdminFee)
 	if err != nil {
-		return fmt.Errorf("failed to get customer limit: %w", err)
+		return fmt.Errorf("failed to update customer limit: %w", err)
 	}
-	if limit == nil || limit.LimitAmount < transaction.OTR+transaction.AdminFee {
+	if !deducted {
 		return fmt.Errorf("insufficient limit")
 	}
 
-	// Calculate the new limit and update it.
-	newLimit := limit.LimitAmount - (transaction.OTR + transaction.AdminFee)
-	if err := s.limitRepo.UpdateLimit(ctx, tx, transaction.CustomerNIK, tenor, newLimit); err != nil {
-		return fmt.Errorf("failed to update customer limit: %w", err)
-	}
-
 	// Create the transaction record.
 	if err := s.transactionRepo.CreateTransaction(ctx, tx, transaction); err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
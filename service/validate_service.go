@@ -1,12 +1,35 @@
 package service
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
 	"multifinance/delivery/dto"
+	"multifinance/model"
 )
 
 type ValidateService interface {
 	ValidateTransactionRequest(req *dto.CreateTransactionRequest) error
+	ValidateCreateCustomerRequest(req *dto.CreateCustomerRequest) error
+	ValidateUpdateCustomerRequest(nik string, req *dto.UpdateCustomerRequest) error
+	ValidateSetLimitsRequest(req *dto.SetLimitsRequest) error
+	ValidateRecomputeLimitsRequest(req *dto.RecomputeLimitsRequest) error
+	ValidateLimitHistoryRequest(req *dto.LimitHistoryRequest) error
+	ValidateListTransactionsRequest(req *dto.ListTransactionsRequest) error
+	ValidateCreatePaymentRequest(req *dto.CreatePaymentRequest) error
+	ValidateCancelTransactionRequest(req *dto.CancelTransactionRequest) error
+	ValidateCreateHoldRequest(req *dto.CreateHoldRequest) error
+	ValidateConfirmHoldRequest(req *dto.ConfirmHoldRequest) error
+	ValidateSettlementQuoteRequest(req *dto.SettlementQuoteRequest) error
+	ValidateSettleContractRequest(req *dto.SettleContractRequest) error
+	ValidateTrialBalanceRequest(req *dto.TrialBalanceRequest) error
+	ValidateCreateWebhookSubscriptionRequest(req *dto.CreateWebhookSubscriptionRequest) error
+	ValidateUpdateWebhookSubscriptionRequest(req *dto.UpdateWebhookSubscriptionRequest) error
+	ValidateListWebhookDeliveriesRequest(req *dto.ListWebhookDeliveriesRequest) error
+	ValidateListAuditLogsRequest(req *dto.ListAuditLogsRequest) error
 }
 
 type ValidateServiceImpl struct{}
@@ -23,6 +46,11 @@ func (s *ValidateServiceImpl) ValidateTransactionRequest(req *dto.CreateTransact
 			Field:   "customer_nik",
 			Message: "customer_nik is required",
 		})
+	} else if _, err := ParseNIK(req.CustomerNIK); err != nil {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "customer_nik",
+			Message: err.Error(),
+		})
 	}
 
 	if req.OTR <= 0 {
@@ -39,10 +67,17 @@ func (s *ValidateServiceImpl) ValidateTransactionRequest(req *dto.CreateTransact
 		})
 	}
 
-	if req.Installment <= 0 {
+	if len(req.IdempotencyKey) > 255 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "Idempotency-Key",
+			Message: "Idempotency-Key must be at most 255 characters",
+		})
+	}
+
+	if req.Installment < 0 {
 		validationErrs = append(validationErrs, dto.ValidationError{
 			Field:   "installment",
-			Message: "installment must be greater than 0",
+			Message: "installment cannot be negative",
 		})
 	}
 
@@ -67,6 +102,405 @@ func (s *ValidateServiceImpl) ValidateTransactionRequest(req *dto.CreateTransact
 		})
 	}
 
+	if len(req.Channel) > 100 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "channel",
+			Message: "channel must be at most 100 characters",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateCreateCustomerRequest(req *dto.CreateCustomerRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if req.NIK == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "nik",
+			Message: "nik is required",
+		})
+	}
+
+	validationErrs = append(validationErrs, validateCustomerFields(
+		req.FullName, req.LegalName, req.BirthPlace, req.BirthDate, req.Salary, req.PhotoKTP, req.PhotoSelfie,
+	)...)
+
+	if req.Gender != "" && req.Gender != GenderMale && req.Gender != GenderFemale {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "gender",
+			Message: "gender must be M or F",
+		})
+	}
+
+	if req.NIK != "" {
+		validationErrs = append(validationErrs, crossCheckNIK(req.NIK, req.BirthDate, req.Gender)...)
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateUpdateCustomerRequest(nik string, req *dto.UpdateCustomerRequest) error {
+	validationErrs := validateCustomerFields(
+		req.FullName, req.LegalName, req.BirthPlace, req.BirthDate, req.Salary, req.PhotoKTP, req.PhotoSelfie,
+	)
+	validationErrs = append(validationErrs, crossCheckNIK(nik, req.BirthDate, "")...)
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+// validateCustomerFields checks the customer attributes shared by the create and update payloads.
+func validateCustomerFields(fullName, legalName, birthPlace, birthDate string, salary int64, photoKTP, photoSelfie string) []dto.ValidationError {
+	var validationErrs []dto.ValidationError
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"full_name", fullName},
+		{"legal_name", legalName},
+		{"birth_place", birthPlace},
+		{"photo_ktp", photoKTP},
+		{"photo_selfie", photoSelfie},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   r.field,
+				Message: r.field + " is required",
+			})
+		}
+	}
+
+	if birthDate == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "birth_date",
+			Message: "birth_date is required",
+		})
+	} else if _, err := time.Parse("2006-01-02", birthDate); err != nil {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "birth_date",
+			Message: "birth_date must be in YYYY-MM-DD format",
+		})
+	}
+
+	if salary <= 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "salary",
+			Message: "salary must be greater than 0",
+		})
+	}
+
+	return validationErrs
+}
+
+func (s *ValidateServiceImpl) ValidateSetLimitsRequest(req *dto.SetLimitsRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if req.Mode != "" && req.Mode != dto.LimitModeSet && req.Mode != dto.LimitModeRaise {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "mode",
+			Message: "mode must be set or raise",
+		})
+	}
+
+	if len(req.Limits) == 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "limits",
+			Message: "limits must contain at least one tenor",
+		})
+	}
+
+	seen := make(map[int]bool, len(req.Limits))
+	for i, item := range req.Limits {
+		field := fmt.Sprintf("limits[%d]", i)
+		if item.Tenor <= 0 {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   field + ".tenor",
+				Message: "tenor must be greater than 0",
+			})
+		} else if seen[item.Tenor] {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   field + ".tenor",
+				Message: fmt.Sprintf("tenor %d is listed more than once", item.Tenor),
+			})
+		}
+		seen[item.Tenor] = true
+
+		if item.LimitAmount < 0 {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   field + ".limit_amount",
+				Message: "limit_amount cannot be negative",
+			})
+		}
+	}
+
+	if req.ChangedBy == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "changed_by",
+			Message: "changed_by is required",
+		})
+	}
+
+	if req.Reason == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "reason",
+			Message: "reason is required",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateRecomputeLimitsRequest(req *dto.RecomputeLimitsRequest) error {
+	if req.ChangedBy == "" {
+		return dto.NewValidationError([]dto.ValidationError{{
+			Field:   "changed_by",
+			Message: "changed_by is required",
+		}})
+	}
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateLimitHistoryRequest(req *dto.LimitHistoryRequest) error {
+	if req.Limit < 0 {
+		return dto.NewValidationError([]dto.ValidationError{{
+			Field:   "limit",
+			Message: "limit cannot be negative",
+		}})
+	}
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateListTransactionsRequest(req *dto.ListTransactionsRequest) error {
+	var validationErrs []dto.ValidationError
+
+	var from, to time.Time
+	var err error
+	if req.From != "" {
+		if from, err = time.Parse("2006-01-02", req.From); err != nil {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   "from",
+				Message: "from must be in YYYY-MM-DD format",
+			})
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse("2006-01-02", req.To); err != nil {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   "to",
+				Message: "to must be in YYYY-MM-DD format",
+			})
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "to",
+			Message: "to cannot be before from",
+		})
+	}
+
+	if req.Tenor < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "tenor",
+			Message: "tenor cannot be negative",
+		})
+	}
+
+	if req.Limit < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "limit",
+			Message: "limit cannot be negative",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateCreatePaymentRequest(req *dto.CreatePaymentRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if req.Amount <= 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "amount",
+			Message: "amount must be greater than 0",
+		})
+	}
+
+	if len(req.Reference) > 100 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "reference",
+			Message: "reference must be at most 100 characters",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateCancelTransactionRequest(req *dto.CancelTransactionRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if strings.TrimSpace(req.Reason) == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "reason",
+			Message: "reason is required",
+		})
+	} else if len(req.Reason) > 255 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "reason",
+			Message: "reason must be at most 255 characters",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateCreateHoldRequest(req *dto.CreateHoldRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if req.CustomerNIK == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "customer_nik",
+			Message: "customer_nik is required",
+		})
+	} else if _, err := ParseNIK(req.CustomerNIK); err != nil {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "customer_nik",
+			Message: err.Error(),
+		})
+	}
+
+	if req.Tenor <= 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "tenor",
+			Message: "tenor must be greater than 0",
+		})
+	}
+
+	if req.Amount <= 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "amount",
+			Message: "amount must be greater than 0",
+		})
+	}
+
+	if len(req.Channel) > 100 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "channel",
+			Message: "channel must be at most 100 characters",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateConfirmHoldRequest(req *dto.ConfirmHoldRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if req.OTR <= 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "otr",
+			Message: "otr must be greater than 0",
+		})
+	}
+
+	if req.AdminFee < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "admin_fee",
+			Message: "admin_fee cannot be negative",
+		})
+	}
+
+	if req.Installment < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "installment",
+			Message: "installment cannot be negative",
+		})
+	}
+
+	if req.Interest < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "interest",
+			Message: "interest cannot be negative",
+		})
+	}
+
+	if req.AssetName == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "asset_name",
+			Message: "asset_name is required",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateSettlementQuoteRequest(req *dto.SettlementQuoteRequest) error {
+	if req.Date == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return dto.NewValidationError([]dto.ValidationError{{
+			Field:   "date",
+			Message: "date must be in YYYY-MM-DD format",
+		}})
+	}
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateSettleContractRequest(req *dto.SettleContractRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if req.Amount <= 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "amount",
+			Message: "amount must be greater than 0",
+		})
+	}
+
+	if len(req.Reference) > 100 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "reference",
+			Message: "reference must be at most 100 characters",
+		})
+	}
+
 	if len(validationErrs) > 0 {
 		return dto.NewValidationError(validationErrs)
 	}
@@ -74,6 +508,240 @@ func (s *ValidateServiceImpl) ValidateTransactionRequest(req *dto.CreateTransact
 	return nil
 }
 
+func (s *ValidateServiceImpl) ValidateTrialBalanceRequest(req *dto.TrialBalanceRequest) error {
+	if req.AsOf == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", req.AsOf); err != nil {
+		return dto.NewValidationError([]dto.ValidationError{{
+			Field:   "as_of",
+			Message: "as_of must be in YYYY-MM-DD format",
+		}})
+	}
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateCreateWebhookSubscriptionRequest(req *dto.CreateWebhookSubscriptionRequest) error {
+	var validationErrs []dto.ValidationError
+
+	if strings.TrimSpace(req.Partner) == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "partner",
+			Message: "partner is required",
+		})
+	} else if len(req.Partner) > 100 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "partner",
+			Message: "partner must be at most 100 characters",
+		})
+	}
+
+	if req.URL == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "url",
+			Message: "url is required",
+		})
+	}
+	validationErrs = append(validationErrs, validateWebhookURL(req.URL)...)
+
+	if req.Secret != "" && (len(req.Secret) < 16 || len(req.Secret) > 255) {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "secret",
+			Message: "secret must be between 16 and 255 characters",
+		})
+	}
+
+	if len(req.EventTypes) == 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "event_types",
+			Message: "event_types must contain at least one event",
+		})
+	}
+	validationErrs = append(validationErrs, validateWebhookEventTypes(req.EventTypes)...)
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateUpdateWebhookSubscriptionRequest(req *dto.UpdateWebhookSubscriptionRequest) error {
+	validationErrs := validateWebhookURL(req.URL)
+	validationErrs = append(validationErrs, validateWebhookEventTypes(req.EventTypes)...)
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateListWebhookDeliveriesRequest(req *dto.ListWebhookDeliveriesRequest) error {
+	var validationErrs []dto.ValidationError
+
+	switch req.Status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryFailed:
+	default:
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "status",
+			Message: "status must be pending, delivered or failed",
+		})
+	}
+
+	if req.Limit < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "limit",
+			Message: "limit cannot be negative",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+func (s *ValidateServiceImpl) ValidateListAuditLogsRequest(req *dto.ListAuditLogsRequest) error {
+	var validationErrs []dto.ValidationError
+
+	switch req.EntityType {
+	case "", model.AuditEntityCustomer, model.AuditEntityCustomerLimit, model.AuditEntityTransaction:
+	default:
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "entity_type",
+			Message: "entity_type must be customer, customer_limit or transaction",
+		})
+	}
+
+	if req.EntityID != "" && req.EntityType == "" {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "entity_type",
+			Message: "entity_type is required when entity_id is set",
+		})
+	}
+
+	var from, to time.Time
+	var err error
+	if req.From != "" {
+		if from, err = time.Parse("2006-01-02", req.From); err != nil {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   "from",
+				Message: "from must be in YYYY-MM-DD format",
+			})
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse("2006-01-02", req.To); err != nil {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   "to",
+				Message: "to must be in YYYY-MM-DD format",
+			})
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "to",
+			Message: "to cannot be before from",
+		})
+	}
+
+	if req.Limit < 0 {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "limit",
+			Message: "limit cannot be negative",
+		})
+	}
+
+	if len(validationErrs) > 0 {
+		return dto.NewValidationError(validationErrs)
+	}
+
+	return nil
+}
+
+// validateWebhookURL checks that a non-empty webhook URL is an absolute http(s) URL.
+func validateWebhookURL(rawURL string) []dto.ValidationError {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return []dto.ValidationError{{Field: "url", Message: "url must be an absolute http or https URL"}}
+	}
+	if len(rawURL) > 500 {
+		return []dto.ValidationError{{Field: "url", Message: "url must be at most 500 characters"}}
+	}
+	return nil
+}
+
+// validateWebhookEventTypes checks that every event type can be subscribed to.
+func validateWebhookEventTypes(eventTypes []string) []dto.ValidationError {
+	var validationErrs []dto.ValidationError
+	seen := make(map[string]bool, len(eventTypes))
+	for i, eventType := range eventTypes {
+		field := fmt.Sprintf("event_types[%d]", i)
+		if !IsWebhookEventType(eventType) {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("event type must be one of %s", strings.Join(WebhookEventTypes, ", ")),
+			})
+		} else if seen[eventType] {
+			validationErrs = append(validationErrs, dto.ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("event type %s is listed more than once", eventType),
+			})
+		}
+		seen[eventType] = true
+	}
+	return validationErrs
+}
+
+// crossCheckNIK validates the NIK structure and, when they are present and well-formed,
+// checks that the birth date and gender encoded in it match the customer data.
+func crossCheckNIK(nik, birthDate, gender string) []dto.ValidationError {
+	parsed, err := ParseNIK(nik)
+	if err != nil {
+		return []dto.ValidationError{{Field: "nik", Message: err.Error()}}
+	}
+
+	var validationErrs []dto.ValidationError
+	if bd, err := time.Parse("2006-01-02", birthDate); err == nil && !parsed.MatchesBirthDate(bd) {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "nik",
+			Message: (&NIKError{Segment: "birth date (digits 7-12)", Message: "does not match birth_date " + birthDate}).Error(),
+		})
+	}
+	if gender != "" && gender != parsed.Gender {
+		validationErrs = append(validationErrs, dto.ValidationError{
+			Field:   "nik",
+			Message: (&NIKError{Segment: "birth day (digits 7-8)", Message: "encoded gender does not match gender " + gender}).Error(),
+		})
+	}
+	return validationErrs
+}
+
+// ValidateCustomerNIK cross-checks the NIK of a stored customer against its
+// birth date and gender before limit is booked for it, so a customer saved
+// before NIKs were validated cannot be financed with mismatched identity data.
+// Failures are reported on customer_nik.
+func ValidateCustomerNIK(customer *model.Customer) error {
+	birthDate := customer.BirthDate
+	if bd, err := customer.ParseBirthDate(); err == nil {
+		birthDate = bd.Format("2006-01-02")
+	}
+
+	validationErrs := crossCheckNIK(customer.NIK, birthDate, customer.Gender)
+	if len(validationErrs) == 0 {
+		return nil
+	}
+	for i := range validationErrs {
+		validationErrs[i].Field = "customer_nik"
+	}
+	return dto.NewValidationError(validationErrs)
+}
+
 func HandleError(err error) (int, interface{}) {
 	return http.StatusInternalServerError, map[string]interface{}{
 		"error":   "Internal Server Error",
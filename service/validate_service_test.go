@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"multifinance/delivery/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSetLimitsRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		wantErr bool
+	}{
+		{name: "mode omitted", mode: ""},
+		{name: "mode set", mode: dto.LimitModeSet},
+		{name: "mode raise", mode: dto.LimitModeRaise},
+		{name: "unknown mode", mode: "lower", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &dto.SetLimitsRequest{
+				Mode:      tt.mode,
+				Limits:    []dto.LimitItem{{Tenor: 1, LimitAmount: 100000}},
+				ChangedBy: "credit.ops",
+				Reason:    "review gaji",
+			}
+
+			err := NewValidateService().ValidateSetLimitsRequest(req)
+
+			assert.Equal(t, tt.wantErr, err != nil, "%v", err)
+			// Validation never rewrites the request; the usecase applies the default.
+			assert.Equal(t, tt.mode, req.Mode)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+	mac := hmac.New(sha256.New, []byte("rahasia"))
+	mac.Write([]byte("1700000000." + string(body)))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, expected, SignWebhook("rahasia", 1700000000, body))
+	assert.NotEqual(t, expected, SignWebhook("rahasia", 1700000001, body))
+	assert.NotEqual(t, expected, SignWebhook("lain", 1700000000, body))
+}
+
+func TestValidateWebhookEventTypes(t *testing.T) {
+	assert.Empty(t, validateWebhookEventTypes([]string{model.EventContractCreated, model.EventPaymentReceived}))
+
+	// limit.changed has no channel, so no partner could ever receive it.
+	errs := validateWebhookEventTypes([]string{model.EventLimitChanged, model.EventContractCreated, model.EventContractCreated})
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "event_types[0]", errs[0].Field)
+		assert.Equal(t, "event_types[2]", errs[1].Field)
+	}
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	a, err := GenerateWebhookSecret()
+	assert.NoError(t, err)
+	b, err := GenerateWebhookSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(a, "whsec_"))
+	assert.Len(t, a, len("whsec_")+64)
+	assert.NotEqual(t, a, b)
+}
+
+func TestWebhookClient_Send(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":"evt-1","type":"payment.received"}`)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, payload, body)
+		assert.Equal(t, "evt-1", r.Header.Get(WebhookHeaderID))
+		assert.Equal(t, "payment.received", r.Header.Get(WebhookHeaderEvent))
+		assert.Equal(t, "1700000000", r.Header.Get(WebhookHeaderTimestamp))
+		assert.Equal(t, SignWebhook("rahasia", 1700000000, payload), r.Header.Get(WebhookHeaderSignature))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(time.Second)
+	sub := &model.WebhookSubscription{URL: server.URL, Secret: "rahasia"}
+	d := &model.WebhookDelivery{EventID: "evt-1", EventType: "payment.received", Payload: payload}
+
+	code, err := client.Send(context.Background(), sub, d, at)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	status = http.StatusInternalServerError
+	code, err = client.Send(context.Background(), sub, d, at)
+	assert.EqualError(t, err, "webhook endpoint responded 500")
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+type stubEventSink struct {
+	err       error
+	published int
+}
+
+func (s *stubEventSink) Publish(ctx context.Context, event EventEnvelope) error {
+	s.published++
+	return s.err
+}
+
+func TestMultiEventSink_Publish(t *testing.T) {
+	ok := &stubEventSink{}
+	failing := &stubEventSink{err: errors.New("broker down")}
+	sink := NewMultiEventSink(failing, ok)
+
+	err := sink.Publish(context.Background(), EventEnvelope{ID: "evt-1"})
+	assert.EqualError(t, err, "broker down")
+	assert.Equal(t, 1, failing.published)
+	assert.Equal(t, 1, ok.published, "a failing sink must not starve the others")
+}
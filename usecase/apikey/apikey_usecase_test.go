@@ -0,0 +1,134 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyRepository) CreateAPIKey(ctx context.Context, k *model.APIKey) error {
+	args := m.Called(ctx, k)
+	return args.Error(0)
+}
+
+func (m *mockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *mockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int64, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestAPIKeyUsecase_CreateKey(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	var stored *model.APIKey
+	repo.On("CreateAPIKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*model.APIKey)
+		stored.ID = 12
+	}).Return(nil).Once()
+
+	uc := NewAPIKeyUsecase(repo)
+	key, k, err := uc.CreateKey(context.Background(), "tokopedia", "checkout")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, k.KeyPrefix+"_"))
+	assert.Equal(t, hashKey(key), stored.KeyHash)
+	assert.Equal(t, int64(12), k.ID)
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	const key = "mfk_0a1b2c3d_5e6f"
+	recent := time.Now()
+	revoked := time.Now()
+
+	tests := []struct {
+		namaTest       string
+		key            string
+		setupMocks     func(repo *mockAPIKeyRepository)
+		erorDiharapkan error
+	}{
+		{
+			namaTest: "key valid dan last_used_at diperbarui",
+			key:      key,
+			setupMocks: func(repo *mockAPIKeyRepository) {
+				repo.On("GetAPIKeyByHash", mock.Anything, hashKey(key)).
+					Return(&model.APIKey{ID: 12, Partner: "tokopedia", Name: "checkout"}, nil).Once()
+				repo.On("TouchAPIKey", mock.Anything, int64(12), mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			namaTest: "key yang baru dipakai tidak diperbarui lagi",
+			key:      key,
+			setupMocks: func(repo *mockAPIKeyRepository) {
+				repo.On("GetAPIKeyByHash", mock.Anything, hashKey(key)).
+					Return(&model.APIKey{ID: 12, Partner: "tokopedia", LastUsedAt: &recent}, nil).Once()
+			},
+		},
+		{
+			namaTest: "key dicabut",
+			key:      key,
+			setupMocks: func(repo *mockAPIKeyRepository) {
+				repo.On("GetAPIKeyByHash", mock.Anything, hashKey(key)).
+					Return(&model.APIKey{ID: 12, Partner: "tokopedia", RevokedAt: &revoked}, nil).Once()
+			},
+			erorDiharapkan: ErrInvalidAPIKey,
+		},
+		{
+			namaTest: "key tidak dikenal",
+			key:      key,
+			setupMocks: func(repo *mockAPIKeyRepository) {
+				repo.On("GetAPIKeyByHash", mock.Anything, hashKey(key)).Return(nil, nil).Once()
+			},
+			erorDiharapkan: ErrInvalidAPIKey,
+		},
+		{
+			namaTest:       "format key salah",
+			key:            "bukan-key",
+			setupMocks:     func(repo *mockAPIKeyRepository) {},
+			erorDiharapkan: ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			repo := &mockAPIKeyRepository{}
+			tt.setupMocks(repo)
+
+			uc := NewAPIKeyUsecase(repo)
+			p, err := uc.Authenticate(context.Background(), tt.key)
+
+			if tt.erorDiharapkan != nil {
+				assert.Equal(t, tt.erorDiharapkan, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, auth.PrincipalPartner, p.Type)
+				assert.Equal(t, "tokopedia", p.Partner)
+				assert.Equal(t, "partner:tokopedia/12", p.Actor())
+				assert.True(t, p.HasRole(auth.RolePartner))
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type AuditRepository interface {
+	ListEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditLog, error)
+}
+
+type AuditUsecase interface {
+	// ListEntries returns audit log entries newest first, filtered by entity and
+	// date range.
+	ListEntries(ctx context.Context, req *dto.ListAuditLogsRequest) (*dto.ListAuditLogsResponse, error)
+}
+
+type auditUsecase struct {
+	auditRepo AuditRepository
+}
+
+func NewAuditUsecase(auditRepo AuditRepository) AuditUsecase {
+	return &auditUsecase{auditRepo: auditRepo}
+}
+
+func (u *auditUsecase) ListEntries(ctx context.Context, req *dto.ListAuditLogsRequest) (*dto.ListAuditLogsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	filter := model.AuditFilter{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		// Fetch one extra row to know whether another page exists.
+		Limit: limit + 1,
+	}
+	if req.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("tanggal from tidak valid: %w", err)
+		}
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("tanggal to tidak valid: %w", err)
+		}
+		// To is inclusive, so the bound is the start of the next day.
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if req.Cursor != "" {
+		beforeID, err := strconv.ParseInt(req.Cursor, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return nil, ErrInvalidCursor
+		}
+		filter.BeforeID = beforeID
+	}
+
+	entries, err := u.auditRepo.ListEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan audit log: %w", err)
+	}
+
+	resp := &dto.ListAuditLogsResponse{Items: []dto.AuditLogResponse{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		resp.NextCursor = strconv.FormatInt(entries[limit-1].ID, 10)
+	}
+	for i := range entries {
+		resp.Items = append(resp.Items, dto.NewAuditLogResponse(&entries[i]))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *mockAuditRepository) ListEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditLog, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.AuditLog), args.Error(1)
+}
+
+func TestAuditUsecase_ListEntries(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 3, 8, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		namaTest       string
+		req            *dto.ListAuditLogsRequest
+		setupMocks     func(auditRepo *mockAuditRepository)
+		erorDiharapkan error
+		jumlahItem     int
+		cursorBerikut  string
+	}{
+		{
+			namaTest: "filter entitas dan tanggal, ada halaman berikutnya",
+			req: &dto.ListAuditLogsRequest{
+				EntityType: model.AuditEntityCustomerLimit,
+				EntityID:   "3171010101900001/6",
+				From:       "2026-03-01",
+				To:         "2026-03-07",
+				Limit:      2,
+			},
+			setupMocks: func(auditRepo *mockAuditRepository) {
+				auditRepo.On("ListEntries", mock.Anything, model.AuditFilter{
+					EntityType: model.AuditEntityCustomerLimit,
+					EntityID:   "3171010101900001/6",
+					From:       &from,
+					To:         &to,
+					Limit:      3,
+				}).Return([]model.AuditLog{{ID: 9}, {ID: 7}, {ID: 4}}, nil).Once()
+			},
+			jumlahItem:    2,
+			cursorBerikut: "7",
+		},
+		{
+			namaTest: "halaman berikutnya dari cursor",
+			req:      &dto.ListAuditLogsRequest{Cursor: "7"},
+			setupMocks: func(auditRepo *mockAuditRepository) {
+				auditRepo.On("ListEntries", mock.Anything, model.AuditFilter{BeforeID: 7, Limit: defaultListLimit + 1}).
+					Return([]model.AuditLog{{ID: 4}}, nil).Once()
+			},
+			jumlahItem: 1,
+		},
+		{
+			namaTest:       "cursor tidak valid",
+			req:            &dto.ListAuditLogsRequest{Cursor: "abc"},
+			setupMocks:     func(auditRepo *mockAuditRepository) {},
+			erorDiharapkan: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			auditRepo := &mockAuditRepository{}
+			tt.setupMocks(auditRepo)
+
+			uc := NewAuditUsecase(auditRepo)
+			hasil, err := uc.ListEntries(context.Background(), tt.req)
+
+			if tt.erorDiharapkan != nil {
+				assert.ErrorIs(t, err, tt.erorDiharapkan)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, hasil.Items, tt.jumlahItem)
+				assert.Equal(t, tt.cursorBerikut, hasil.NextCursor)
+			}
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	onboardingActor  = "system"
+	onboardingReason = "limit policy on customer onboarding"
+
+	createReason = "customer onboarding"
+	updateReason = "customer data update"
+)
+
+var (
+	ErrCustomerNotFound      = errors.New("customer not found")
+	ErrCustomerAlreadyExists = errors.New("customer already exists")
+)
+
+type CustomerRepository interface {
+	GetCustomer(ctx context.Context, nik string) (*model.Customer, error)
+	GetCustomerForUpdate(ctx context.Context, tx repo.DBTx, nik string) (*model.Customer, error)
+	CreateCustomer(ctx context.Context, tx repo.DBTx, customer *model.Customer) error
+	UpdateCustomer(ctx context.Context, tx repo.DBTx, customer *model.Customer) error
+	ListCustomers(ctx context.Context, limit, offset int) ([]model.Customer, error)
+}
+
+// LimitAssigner applies the limit policy to a newly created customer.
+type LimitAssigner interface {
+	AssignLimits(ctx context.Context, tx repo.DBTx, customer *model.Customer, changedBy, reason string) ([]model.CustomerLimit, error)
+}
+
+type AuditRepository interface {
+	CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error
+}
+
+type CustomerUsecase interface {
+	CreateCustomer(ctx context.Context, req *dto.CreateCustomerRequest) (*model.Customer, error)
+	GetCustomer(ctx context.Context, nik string) (*model.Customer, error)
+	UpdateCustomer(ctx context.Context, nik string, req *dto.UpdateCustomerRequest) (*model.Customer, error)
+	ListCustomers(ctx context.Context, req *dto.ListCustomersRequest) (*dto.ListCustomersResponse, error)
+}
+
+type customerUsecase struct {
+	db            *sqlx.DB
+	customerRepo  CustomerRepository
+	limitAssigner LimitAssigner
+	auditRepo     AuditRepository
+}
+
+func NewCustomerUsecase(db *sqlx.DB, customerRepo CustomerRepository, limitAssigner LimitAssigner, auditRepo AuditRepository) CustomerUsecase {
+	return &customerUsecase{
+		db:            db,
+		customerRepo:  customerRepo,
+		limitAssigner: limitAssigner,
+		auditRepo:     auditRepo,
+	}
+}
+
+func (u *customerUsecase) CreateCustomer(ctx context.Context, req *dto.CreateCustomerRequest) (*model.Customer, error) {
+	existing, err := u.customerRepo.GetCustomer(ctx, req.NIK)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data customer: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrCustomerAlreadyExists
+	}
+
+	nik, err := service.ParseNIK(req.NIK)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := &model.Customer{
+		NIK:         req.NIK,
+		FullName:    req.FullName,
+		LegalName:   req.LegalName,
+		BirthPlace:  req.BirthPlace,
+		BirthDate:   req.BirthDate,
+		Gender:      nik.Gender,
+		Salary:      req.Salary,
+		PhotoKTP:    req.PhotoKTP,
+		PhotoSelfie: req.PhotoSelfie,
+	}
+
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	if err := u.customerRepo.CreateCustomer(ctx, dbTx, customer); err != nil {
+		// A concurrent registration of the same NIK won the insert.
+		if errors.Is(err, repo.ErrDuplicateKey) {
+			return nil, ErrCustomerAlreadyExists
+		}
+		return nil, fmt.Errorf("gagal membuat customer: %w", err)
+	}
+
+	if err := u.audit(ctx, dbTx, nil, customer, createReason); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.limitAssigner.AssignLimits(ctx, dbTx, customer, onboardingActor, onboardingReason); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return customer, nil
+}
+
+func (u *customerUsecase) GetCustomer(ctx context.Context, nik string) (*model.Customer, error) {
+	customer, err := u.customerRepo.GetCustomer(ctx, nik)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data customer: %w", err)
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+func (u *customerUsecase) UpdateCustomer(ctx context.Context, nik string, req *dto.UpdateCustomerRequest) (*model.Customer, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	before, err := u.customerRepo.GetCustomerForUpdate(ctx, dbTx, nik)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data customer: %w", err)
+	}
+	if before == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	customer := *before
+	customer.FullName = req.FullName
+	customer.LegalName = req.LegalName
+	customer.BirthPlace = req.BirthPlace
+	customer.BirthDate = req.BirthDate
+	customer.Salary = req.Salary
+	customer.PhotoKTP = req.PhotoKTP
+	customer.PhotoSelfie = req.PhotoSelfie
+
+	if err := u.customerRepo.UpdateCustomer(ctx, dbTx, &customer); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui customer: %w", err)
+	}
+
+	if err := u.audit(ctx, dbTx, before, &customer, updateReason); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return &customer, nil
+}
+
+// audit records the change of a customer from before to after inside tx.
+func (u *customerUsecase) audit(ctx context.Context, tx repo.DBTx, before, after *model.Customer, reason string) error {
+	entry, err := service.NewCustomerAudit(ctx, before, after, reason, time.Now())
+	if err == nil {
+		err = u.auditRepo.CreateEntries(ctx, tx, []model.AuditLog{entry})
+	}
+	if err != nil {
+		return fmt.Errorf("gagal mencatat audit customer: %w", err)
+	}
+	return nil
+}
+
+func (u *customerUsecase) ListCustomers(ctx context.Context, req *dto.ListCustomersRequest) (*dto.ListCustomersResponse, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	customers, err := u.customerRepo.ListCustomers(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan daftar customer: %w", err)
+	}
+
+	items := make([]dto.CustomerResponse, 0, len(customers))
+	for i := range customers {
+		items = append(items, dto.NewCustomerResponse(&customers[i]))
+	}
+
+	return &dto.ListCustomersResponse{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
@@ -0,0 +1,226 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCustomerRepository struct {
+	mock.Mock
+}
+
+func (m *mockCustomerRepository) GetCustomer(ctx context.Context, nik string) (*model.Customer, error) {
+	args := m.Called(ctx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *mockCustomerRepository) GetCustomerForUpdate(ctx context.Context, tx repo.DBTx, nik string) (*model.Customer, error) {
+	args := m.Called(ctx, tx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *mockCustomerRepository) CreateCustomer(ctx context.Context, tx repo.DBTx, customer *model.Customer) error {
+	args := m.Called(ctx, tx, customer)
+	return args.Error(0)
+}
+
+func (m *mockCustomerRepository) UpdateCustomer(ctx context.Context, tx repo.DBTx, customer *model.Customer) error {
+	args := m.Called(ctx, tx, customer)
+	return args.Error(0)
+}
+
+func (m *mockCustomerRepository) ListCustomers(ctx context.Context, limit, offset int) ([]model.Customer, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Customer), args.Error(1)
+}
+
+type mockLimitAssigner struct {
+	mock.Mock
+}
+
+func (m *mockLimitAssigner) AssignLimits(ctx context.Context, tx repo.DBTx, customer *model.Customer, changedBy, reason string) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, tx, customer, changedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+// recordingAudit keeps the audit entries written.
+type recordingAudit struct {
+	entries []model.AuditLog
+}
+
+func (a *recordingAudit) CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error {
+	a.entries = append(a.entries, entries...)
+	return nil
+}
+
+func TestCustomerUsecase_CreateCustomer(t *testing.T) {
+	req := &dto.CreateCustomerRequest{
+		NIK:         "3171010101900001",
+		FullName:    "Budi",
+		LegalName:   "Budi Santoso",
+		BirthPlace:  "Jakarta",
+		BirthDate:   "1990-01-01",
+		Salary:      10000000,
+		PhotoKTP:    "ktp_budi.jpg",
+		PhotoSelfie: "selfie_budi.jpg",
+	}
+
+	tests := []struct {
+		namaTest       string
+		setupMocks     func(customerRepo *mockCustomerRepository, assigner *mockLimitAssigner, sqlMock sqlmock.Sqlmock)
+		erorDiharapkan error
+	}{
+		{
+			namaTest: "customer berhasil dibuat dan limit diberikan",
+			setupMocks: func(customerRepo *mockCustomerRepository, assigner *mockLimitAssigner, sqlMock sqlmock.Sqlmock) {
+				customerRepo.On("GetCustomer", mock.Anything, req.NIK).Return(nil, nil).Once()
+				sqlMock.ExpectBegin()
+				customerRepo.On("CreateCustomer", mock.Anything, mock.Anything, mock.MatchedBy(func(c *model.Customer) bool {
+					return c.NIK == req.NIK && c.Salary == req.Salary && c.BirthDate == req.BirthDate && c.Gender == "M"
+				})).Return(nil).Once()
+				assigner.On("AssignLimits", mock.Anything, mock.Anything, mock.Anything, onboardingActor, onboardingReason).
+					Return([]model.CustomerLimit{}, nil).Once()
+				sqlMock.ExpectCommit()
+			},
+		},
+		{
+			namaTest: "customer sudah terdaftar",
+			setupMocks: func(customerRepo *mockCustomerRepository, assigner *mockLimitAssigner, sqlMock sqlmock.Sqlmock) {
+				customerRepo.On("GetCustomer", mock.Anything, req.NIK).
+					Return(&model.Customer{NIK: req.NIK}, nil).Once()
+			},
+			erorDiharapkan: ErrCustomerAlreadyExists,
+		},
+		{
+			namaTest: "gagal membuat customer",
+			setupMocks: func(customerRepo *mockCustomerRepository, assigner *mockLimitAssigner, sqlMock sqlmock.Sqlmock) {
+				customerRepo.On("GetCustomer", mock.Anything, req.NIK).Return(nil, nil).Once()
+				sqlMock.ExpectBegin()
+				customerRepo.On("CreateCustomer", mock.Anything, mock.Anything, mock.Anything).
+					Return(errors.New("database error")).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("gagal membuat customer: database error"),
+		},
+		{
+			namaTest: "NIK didaftarkan bersamaan",
+			setupMocks: func(customerRepo *mockCustomerRepository, assigner *mockLimitAssigner, sqlMock sqlmock.Sqlmock) {
+				customerRepo.On("GetCustomer", mock.Anything, req.NIK).Return(nil, nil).Once()
+				sqlMock.ExpectBegin()
+				customerRepo.On("CreateCustomer", mock.Anything, mock.Anything, mock.Anything).
+					Return(repo.ErrDuplicateKey).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrCustomerAlreadyExists,
+		},
+		{
+			namaTest: "gagal memberikan limit",
+			setupMocks: func(customerRepo *mockCustomerRepository, assigner *mockLimitAssigner, sqlMock sqlmock.Sqlmock) {
+				customerRepo.On("GetCustomer", mock.Anything, req.NIK).Return(nil, nil).Once()
+				sqlMock.ExpectBegin()
+				customerRepo.On("CreateCustomer", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				assigner.On("AssignLimits", mock.Anything, mock.Anything, mock.Anything, onboardingActor, onboardingReason).
+					Return(nil, errors.New("gagal memperbarui limit: database error")).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("gagal memperbarui limit: database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Gagal membuat mock database: %v", err)
+			}
+			defer db.Close()
+
+			customerRepo := &mockCustomerRepository{}
+			assigner := &mockLimitAssigner{}
+			tt.setupMocks(customerRepo, assigner, sqlMock)
+
+			uc := NewCustomerUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, assigner, &recordingAudit{})
+			customer, err := uc.CreateCustomer(context.Background(), req)
+
+			if tt.erorDiharapkan != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.erorDiharapkan.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, req.NIK, customer.NIK)
+			}
+			customerRepo.AssertExpectations(t)
+			assigner.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCustomerUsecase_ListCustomers_Pagination(t *testing.T) {
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("ListCustomers", mock.Anything, maxPageSize, maxPageSize).
+		Return([]model.Customer{{NIK: "3171010101900001"}}, nil).Once()
+
+	uc := NewCustomerUsecase(nil, customerRepo, &mockLimitAssigner{}, &recordingAudit{})
+	resp, err := uc.ListCustomers(context.Background(), &dto.ListCustomersRequest{Page: 2, PageSize: 500})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Page)
+	assert.Equal(t, maxPageSize, resp.PageSize)
+	assert.Len(t, resp.Items, 1)
+	customerRepo.AssertExpectations(t)
+}
+
+func TestCustomerUsecase_UpdateCustomer_Audited(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Gagal membuat mock database: %v", err)
+	}
+	defer db.Close()
+
+	customerRepo := &mockCustomerRepository{}
+	sqlMock.ExpectBegin()
+	customerRepo.On("GetCustomerForUpdate", mock.Anything, mock.Anything, "3171010101900001").
+		Return(&model.Customer{NIK: "3171010101900001", FullName: "Budi", Salary: 5000000}, nil).Once()
+	customerRepo.On("UpdateCustomer", mock.Anything, mock.Anything, mock.MatchedBy(func(c *model.Customer) bool {
+		return c.Salary == 8000000
+	})).Return(nil).Once()
+	sqlMock.ExpectCommit()
+
+	audit := &recordingAudit{}
+	uc := NewCustomerUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, &mockLimitAssigner{}, audit)
+	_, err = uc.UpdateCustomer(context.Background(), "3171010101900001", &dto.UpdateCustomerRequest{FullName: "Budi", Salary: 8000000})
+
+	assert.NoError(t, err)
+	if assert.Len(t, audit.entries, 1) {
+		entry := audit.entries[0]
+		assert.Equal(t, model.AuditEntityCustomer, entry.EntityType)
+		assert.Equal(t, model.AuditActionUpdate, entry.Action)
+		assert.Contains(t, string(entry.Before), `"salary":5000000`)
+		assert.Contains(t, string(entry.After), `"salary":8000000`)
+	}
+	customerRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
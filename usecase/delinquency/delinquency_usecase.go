@@ -0,0 +1,164 @@
+package delinquency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const refreshBatchSize = 500
+
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+type TransactionRepository interface {
+	GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error)
+	GetTransactionForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) (*model.Transaction, error)
+	ListContractNumbersByStatus(ctx context.Context, status, after string, limit int) ([]string, error)
+}
+
+type InstallmentRepository interface {
+	ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error)
+}
+
+type DelinquencyRepository interface {
+	UpsertDelinquency(ctx context.Context, tx repo.DBTx, d *model.Delinquency) error
+}
+
+type LedgerRepository interface {
+	ContractBalance(ctx context.Context, tx repo.DBTx, contractNumber, account string) (int64, error)
+	PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error
+}
+
+type DelinquencyUsecase interface {
+	// GetDelinquency assesses a contract as of now.
+	GetDelinquency(ctx context.Context, contractNumber string) (*model.Delinquency, error)
+	// RefreshAll assesses every active contract as of asOf, stores the snapshots
+	// and accrues the change in late penalties to the ledger. It returns the
+	// number of contracts refreshed.
+	RefreshAll(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type delinquencyUsecase struct {
+	db              *sqlx.DB
+	txRepo          TransactionRepository
+	installmentRepo InstallmentRepository
+	delinquencyRepo DelinquencyRepository
+	ledgerRepo      LedgerRepository
+	assessor        service.DelinquencyAssessor
+}
+
+func NewDelinquencyUsecase(db *sqlx.DB, txRepo TransactionRepository, installmentRepo InstallmentRepository, delinquencyRepo DelinquencyRepository, ledgerRepo LedgerRepository, assessor service.DelinquencyAssessor) DelinquencyUsecase {
+	return &delinquencyUsecase{
+		db:              db,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		delinquencyRepo: delinquencyRepo,
+		ledgerRepo:      ledgerRepo,
+		assessor:        assessor,
+	}
+}
+
+func (u *delinquencyUsecase) GetDelinquency(ctx context.Context, contractNumber string) (*model.Delinquency, error) {
+	t, err := u.txRepo.GetTransaction(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if t == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	installments, err := u.installmentRepo.ListInstallments(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan jadwal angsuran: %w", err)
+	}
+
+	d := u.assessor.Assess(contractNumber, installments, time.Now())
+	return &d, nil
+}
+
+func (u *delinquencyUsecase) RefreshAll(ctx context.Context, asOf time.Time) (int, error) {
+	var (
+		after     string
+		refreshed int
+		failed    int
+	)
+	for {
+		numbers, err := u.txRepo.ListContractNumbersByStatus(ctx, model.TransactionStatusActive, after, refreshBatchSize)
+		if err != nil {
+			return refreshed, fmt.Errorf("gagal mendapatkan daftar kontrak: %w", err)
+		}
+
+		for _, contractNumber := range numbers {
+			if err := ctx.Err(); err != nil {
+				return refreshed, err
+			}
+			// One broken contract must not stop the others from being assessed.
+			if err := u.refresh(ctx, contractNumber, asOf); err != nil {
+				log.Printf("Error - gagal menghitung keterlambatan %s: %v", contractNumber, err)
+				failed++
+				continue
+			}
+			refreshed++
+		}
+
+		if len(numbers) < refreshBatchSize {
+			break
+		}
+		after = numbers[len(numbers)-1]
+	}
+
+	if failed > 0 {
+		return refreshed, fmt.Errorf("gagal menghitung keterlambatan %d kontrak", failed)
+	}
+	return refreshed, nil
+}
+
+// refresh stores the delinquency snapshot of one contract and posts the
+// difference between its assessed penalty and the penalty already recognised in
+// the ledger, so both move together. Recognised rather than receivable, since
+// payments collect part of the receivable while the assessment keeps it. The contract row stays locked meanwhile, so
+// a payment or settlement cannot change what was assessed.
+func (u *delinquencyUsecase) refresh(ctx context.Context, contractNumber string, asOf time.Time) error {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	t, err := u.txRepo.GetTransactionForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return err
+	}
+	if t == nil || t.Status != model.TransactionStatusActive {
+		// Settled or cancelled since it was listed.
+		return nil
+	}
+
+	installments, err := u.installmentRepo.ListInstallments(ctx, contractNumber)
+	if err != nil {
+		return err
+	}
+	d := u.assessor.Assess(contractNumber, installments, asOf)
+
+	// Income accounts carry a credit balance.
+	income, err := u.ledgerRepo.ContractBalance(ctx, dbTx, contractNumber, model.AccountPenaltyIncome)
+	if err != nil {
+		return err
+	}
+	if err := u.ledgerRepo.PostEntries(ctx, dbTx, service.PenaltyAccrualEntries(contractNumber, d.PenaltyAmount+income, asOf)); err != nil {
+		return err
+	}
+
+	if err := u.delinquencyRepo.UpsertDelinquency(ctx, dbTx, &d); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
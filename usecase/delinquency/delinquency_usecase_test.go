@@ -0,0 +1,222 @@
+package delinquency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *mockTransactionRepository) GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionRepository) GetTransactionForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, tx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionRepository) ListContractNumbersByStatus(ctx context.Context, status, after string, limit int) ([]string, error) {
+	args := m.Called(ctx, status, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type mockInstallmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockInstallmentRepository) ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Installment), args.Error(1)
+}
+
+type mockDelinquencyRepository struct {
+	mock.Mock
+}
+
+func (m *mockDelinquencyRepository) UpsertDelinquency(ctx context.Context, tx repo.DBTx, d *model.Delinquency) error {
+	args := m.Called(ctx, tx, d)
+	return args.Error(0)
+}
+
+// recordingLedger keeps the posted journal entries and sums them per contract and
+// account like the real repository.
+type recordingLedger struct {
+	entries []model.JournalEntry
+}
+
+func (l *recordingLedger) ContractBalance(ctx context.Context, tx repo.DBTx, contractNumber, account string) (int64, error) {
+	var balance int64
+	for _, e := range l.entries {
+		for _, line := range e.Lines {
+			if e.ContractNumber == contractNumber && line.AccountCode == account {
+				balance += line.Debit - line.Credit
+			}
+		}
+	}
+	return balance, nil
+}
+
+func (l *recordingLedger) PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error {
+	for _, e := range entries {
+		if !e.Balanced() {
+			return repo.ErrUnbalancedEntry
+		}
+	}
+	l.entries = append(l.entries, entries...)
+	return nil
+}
+
+// stubAssessor reports every contract as 10 days past due with a 15.000 penalty.
+type stubAssessor struct{}
+
+func (s *stubAssessor) Assess(contractNumber string, installments []model.Installment, asOf time.Time) model.Delinquency {
+	return model.Delinquency{ContractNumber: contractNumber, DPD: 10, PenaltyAmount: 15000, Collectibility: 2, AsOf: asOf}
+}
+
+func activeContract(contractNumber string) *model.Transaction {
+	return &model.Transaction{ContractNumber: contractNumber, Status: model.TransactionStatusActive}
+}
+
+func TestDelinquencyUsecase_RefreshAll(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	txRepo := &mockTransactionRepository{}
+	installmentRepo := &mockInstallmentRepository{}
+	delinquencyRepo := &mockDelinquencyRepository{}
+
+	// A full first batch makes the usecase ask for the next one after its last contract.
+	firstBatch := make([]string, refreshBatchSize)
+	for i := range firstBatch {
+		firstBatch[i] = fmt.Sprintf("CON-%04d", i)
+	}
+	txRepo.On("ListContractNumbersByStatus", mock.Anything, model.TransactionStatusActive, "", refreshBatchSize).Return(firstBatch, nil).Once()
+	txRepo.On("ListContractNumbersByStatus", mock.Anything, model.TransactionStatusActive, firstBatch[len(firstBatch)-1], refreshBatchSize).Return([]string{"CON-9999"}, nil).Once()
+	txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, mock.Anything).Return(activeContract("CON"), nil)
+	for _, contractNumber := range append(firstBatch, "CON-9999") {
+		sqlMock.ExpectBegin()
+		if contractNumber == "CON-0001" {
+			sqlMock.ExpectRollback()
+		} else {
+			sqlMock.ExpectCommit()
+		}
+	}
+	installmentRepo.On("ListInstallments", mock.Anything, "CON-0001").Return(nil, errors.New("db error")).Once()
+	installmentRepo.On("ListInstallments", mock.Anything, mock.Anything).Return([]model.Installment{}, nil)
+	delinquencyRepo.On("UpsertDelinquency", mock.Anything, mock.Anything, mock.MatchedBy(func(d *model.Delinquency) bool {
+		return d.DPD == 10
+	})).Return(nil)
+
+	uc := NewDelinquencyUsecase(sqlx.NewDb(db, "sqlmock"), txRepo, installmentRepo, delinquencyRepo, &recordingLedger{}, &stubAssessor{})
+	refreshed, err := uc.RefreshAll(context.Background(), time.Now())
+
+	// The failing contract is reported, the others are still refreshed.
+	assert.EqualError(t, err, "gagal menghitung keterlambatan 1 kontrak")
+	assert.Equal(t, refreshBatchSize, refreshed)
+	txRepo.AssertExpectations(t)
+	delinquencyRepo.AssertNumberOfCalls(t, "UpsertDelinquency", refreshBatchSize)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestDelinquencyUsecase_RefreshAll_AccruesPenalty(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	asOf := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	txRepo := &mockTransactionRepository{}
+	installmentRepo := &mockInstallmentRepository{}
+	delinquencyRepo := &mockDelinquencyRepository{}
+	txRepo.On("ListContractNumbersByStatus", mock.Anything, model.TransactionStatusActive, "", refreshBatchSize).Return([]string{"CON-1", "CON-2", "CON-3"}, nil).Once()
+	txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(activeContract("CON-1"), nil).Once()
+	txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-2").Return(activeContract("CON-2"), nil).Once()
+	// Settled between listing and locking: nothing is assessed.
+	txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-3").Return(&model.Transaction{ContractNumber: "CON-3", Status: model.TransactionStatusPaidOff}, nil).Once()
+	installmentRepo.On("ListInstallments", mock.Anything, mock.Anything).Return([]model.Installment{}, nil)
+	delinquencyRepo.On("UpsertDelinquency", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+	for range 2 {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+	}
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+
+	// CON-1 has accrued 5.000 already, CON-2 its full 15.000 of which a payment
+	// collected 10.000; collected penalty is not accrued again.
+	ledger := &recordingLedger{}
+	ledger.entries = append(ledger.entries, service.PenaltyAccrualEntries("CON-1", 5000, asOf)...)
+	ledger.entries = append(ledger.entries, service.PenaltyAccrualEntries("CON-2", 15000, asOf)...)
+	ledger.entries = append(ledger.entries, service.RepaymentEntries(&model.Payment{ContractNumber: "CON-2", PenaltyPaid: 10000, PaidAt: asOf})...)
+
+	uc := NewDelinquencyUsecase(sqlx.NewDb(db, "sqlmock"), txRepo, installmentRepo, delinquencyRepo, ledger, &stubAssessor{})
+	refreshed, err := uc.RefreshAll(context.Background(), asOf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, refreshed)
+	if assert.Len(t, ledger.entries, 4) {
+		posted := ledger.entries[3]
+		assert.Equal(t, model.JournalEntryPenaltyAccrual, posted.EntryType)
+		assert.Equal(t, "CON-1", posted.ContractNumber)
+		assert.Equal(t, []model.JournalLine{
+			{AccountCode: model.AccountPenaltyReceivable, Debit: 10000},
+			{AccountCode: model.AccountPenaltyIncome, Credit: 10000},
+		}, posted.Lines)
+	}
+	txRepo.AssertExpectations(t)
+	delinquencyRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestDelinquencyUsecase_GetDelinquency(t *testing.T) {
+	t.Run("kontrak tidak ditemukan", func(t *testing.T) {
+		txRepo := &mockTransactionRepository{}
+		txRepo.On("GetTransaction", mock.Anything, "CON-X").Return(nil, nil)
+
+		uc := NewDelinquencyUsecase(nil, txRepo, &mockInstallmentRepository{}, &mockDelinquencyRepository{}, nil, &stubAssessor{})
+		_, err := uc.GetDelinquency(context.Background(), "CON-X")
+
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+	})
+
+	t.Run("dihitung dari jadwal angsuran", func(t *testing.T) {
+		txRepo := &mockTransactionRepository{}
+		installmentRepo := &mockInstallmentRepository{}
+		txRepo.On("GetTransaction", mock.Anything, "CON-1").Return(&model.Transaction{ContractNumber: "CON-1"}, nil)
+		installmentRepo.On("ListInstallments", mock.Anything, "CON-1").Return([]model.Installment{}, nil)
+
+		uc := NewDelinquencyUsecase(nil, txRepo, installmentRepo, &mockDelinquencyRepository{}, nil, &stubAssessor{})
+		d, err := uc.GetDelinquency(context.Background(), "CON-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, d.DPD)
+	})
+}
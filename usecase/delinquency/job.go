@@ -0,0 +1,27 @@
+package delinquency
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RefreshJob is the daily background job that recomputes DPD, penalties and
+// collectibility for all active contracts.
+type RefreshJob struct {
+	usecase DelinquencyUsecase
+}
+
+func NewRefreshJob(usecase DelinquencyUsecase) *RefreshJob {
+	return &RefreshJob{usecase: usecase}
+}
+
+func (j *RefreshJob) Name() string {
+	return "delinquency-refresh"
+}
+
+func (j *RefreshJob) Run(ctx context.Context) error {
+	refreshed, err := j.usecase.RefreshAll(ctx, time.Now())
+	log.Printf("Delinquency refresh assessed %d contracts", refreshed)
+	return err
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"multifinance/model"
+	"multifinance/worker"
+)
+
+// stallGrace is added to twice a job's interval before a job that has not
+// started again is reported as stalled.
+const stallGrace = time.Minute
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// MigrationSource reports whether the schema is migrated. It must not write to
+// the database: the readiness probe is public and runs every few seconds.
+type MigrationSource interface {
+	Current(ctx context.Context) error
+}
+
+type WorkerMonitor interface {
+	Status() []worker.JobStatus
+}
+
+type HealthUsecase interface {
+	// Readiness runs every dependency check and reports each one.
+	Readiness(ctx context.Context) model.HealthReport
+	// Drain makes readiness fail from now on, so load balancers stop routing
+	// traffic before the server shuts down.
+	Drain()
+}
+
+type healthUsecase struct {
+	db           Pinger
+	migrations   MigrationSource
+	workers      WorkerMonitor
+	checkTimeout time.Duration
+	draining     atomic.Bool
+}
+
+func NewHealthUsecase(db Pinger, migrations MigrationSource, workers WorkerMonitor, checkTimeout time.Duration) HealthUsecase {
+	return &healthUsecase{
+		db:           db,
+		migrations:   migrations,
+		workers:      workers,
+		checkTimeout: checkTimeout,
+	}
+}
+
+func (u *healthUsecase) Drain() {
+	u.draining.Store(true)
+}
+
+func (u *healthUsecase) Readiness(ctx context.Context) model.HealthReport {
+	var report model.HealthReport
+
+	var shutdownErr error
+	if u.draining.Load() {
+		shutdownErr = ErrShuttingDown
+	}
+	report.Checks = append(report.Checks, model.HealthCheck{Name: "shutdown", Err: shutdownErr})
+
+	report.Checks = append(report.Checks, u.timed(ctx, "database", u.db.PingContext))
+	report.Checks = append(report.Checks, u.timed(ctx, "migrations", u.migrations.Current))
+
+	now := time.Now()
+	for _, s := range u.workers.Status() {
+		report.Checks = append(report.Checks, model.HealthCheck{Name: "worker:" + s.Name, Err: checkJob(s, now)})
+	}
+
+	// The response only says which checks failed; the reasons go to the log.
+	for _, c := range report.Checks {
+		if c.Err != nil {
+			log.Printf("Error - readiness check %s failed: %v", c.Name, c.Err)
+		}
+	}
+	return report
+}
+
+// timed runs check with the configured timeout and records how long it took.
+func (u *healthUsecase) timed(ctx context.Context, name string, check func(ctx context.Context) error) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, u.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	return model.HealthCheck{Name: name, Err: err, Duration: time.Since(start)}
+}
+
+// checkJob fails a job that has not started within twice its interval, which
+// means it is stuck in a run or its loop has died. A failed last run alone is
+// not reported: the job retries on its next tick, and the dependency it failed
+// on has its own check.
+func checkJob(s worker.JobStatus, now time.Time) error {
+	if s.LastStart.IsZero() {
+		return errors.New("job has not started")
+	}
+	if since := now.Sub(s.LastStart); since > 2*s.Interval+stallGrace {
+		if s.Running {
+			return fmt.Errorf("job has been running for %s", since.Round(time.Second))
+		}
+		return fmt.Errorf("job has not run for %s", since.Round(time.Second))
+	}
+	return nil
+}
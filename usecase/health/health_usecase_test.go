@@ -0,0 +1,146 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/database"
+	"multifinance/model"
+	"multifinance/worker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) PingContext(ctx context.Context) error {
+	if p.err != nil {
+		return p.err
+	}
+	// The ping honours the check timeout like the driver does.
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
+type blockingPinger struct{}
+
+func (blockingPinger) PingContext(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type fakeMigrations struct {
+	err error
+}
+
+func (m fakeMigrations) Current(ctx context.Context) error {
+	return m.err
+}
+
+type fakeWorkers []worker.JobStatus
+
+func (w fakeWorkers) Status() []worker.JobStatus { return w }
+
+func checkErrors(r model.HealthReport) map[string]error {
+	errs := map[string]error{}
+	for _, c := range r.Checks {
+		errs[c.Name] = c.Err
+	}
+	return errs
+}
+
+func TestHealthUsecase_Readiness(t *testing.T) {
+	now := time.Now()
+	healthyJob := worker.JobStatus{Name: "outbox-relay", Interval: 5 * time.Second, LastStart: now.Add(-time.Second), LastEnd: now}
+
+	tests := []struct {
+		namaTest        string
+		db              Pinger
+		migrations      fakeMigrations
+		workers         fakeWorkers
+		drain           bool
+		siapDiharapkan  bool
+		gagalDiharapkan map[string]string
+	}{
+		{
+			namaTest:       "semua dependensi sehat",
+			db:             fakePinger{},
+			workers:        fakeWorkers{healthyJob},
+			siapDiharapkan: true,
+		},
+		{
+			namaTest:        "database mati",
+			db:              fakePinger{err: errors.New("connection refused")},
+			workers:         fakeWorkers{healthyJob},
+			gagalDiharapkan: map[string]string{"database": "connection refused"},
+		},
+		{
+			namaTest:        "ping database melewati batas waktu",
+			db:              blockingPinger{},
+			workers:         fakeWorkers{healthyJob},
+			gagalDiharapkan: map[string]string{"database": context.DeadlineExceeded.Error()},
+		},
+		{
+			namaTest:        "migrasi belum diterapkan",
+			db:              fakePinger{},
+			migrations:      fakeMigrations{err: database.ErrNotMigrated},
+			workers:         fakeWorkers{healthyJob},
+			gagalDiharapkan: map[string]string{"migrations": database.ErrNotMigrated.Error()},
+		},
+		{
+			namaTest: "job macet",
+			db:       fakePinger{},
+			workers: fakeWorkers{
+				healthyJob,
+				{Name: "delinquency-refresh", Interval: time.Minute, Running: true, LastStart: now.Add(-10 * time.Minute)},
+				{Name: "ledger-accrual", Interval: time.Hour},
+			},
+			gagalDiharapkan: map[string]string{
+				"worker:delinquency-refresh": "job has been running for 10m0s",
+				"worker:ledger-accrual":      "job has not started",
+			},
+		},
+		{
+			namaTest:       "job gagal tetapi masih berjalan sesuai jadwal",
+			db:             fakePinger{},
+			workers:        fakeWorkers{{Name: "outbox-relay", Interval: 5 * time.Second, LastStart: now, LastError: errors.New("sink down")}},
+			siapDiharapkan: true,
+		},
+		{
+			namaTest:        "sedang shutdown",
+			db:              fakePinger{},
+			workers:         fakeWorkers{healthyJob},
+			drain:           true,
+			gagalDiharapkan: map[string]string{"shutdown": ErrShuttingDown.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			uc := NewHealthUsecase(tt.db, tt.migrations, tt.workers, 20*time.Millisecond)
+			if tt.drain {
+				uc.Drain()
+			}
+
+			report := uc.Readiness(context.Background())
+
+			assert.Equal(t, tt.siapDiharapkan, report.Ready())
+			assert.Len(t, report.Checks, 3+len(tt.workers))
+			for name, err := range checkErrors(report) {
+				if msg, ok := tt.gagalDiharapkan[name]; ok {
+					assert.EqualError(t, err, msg, name)
+				} else {
+					assert.NoError(t, err, name)
+				}
+			}
+		})
+	}
+}
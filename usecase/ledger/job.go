@@ -0,0 +1,27 @@
+package ledger
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// AccrualJob is the daily background job that recognises interest income of
+// periods that have fallen due.
+type AccrualJob struct {
+	usecase LedgerUsecase
+}
+
+func NewAccrualJob(usecase LedgerUsecase) *AccrualJob {
+	return &AccrualJob{usecase: usecase}
+}
+
+func (j *AccrualJob) Name() string {
+	return "interest-accrual"
+}
+
+func (j *AccrualJob) Run(ctx context.Context) error {
+	accrued, err := j.usecase.AccrueInterest(ctx, time.Now())
+	log.Printf("Interest accrual posted for %d contracts", accrued)
+	return err
+}
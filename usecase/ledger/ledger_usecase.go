@@ -0,0 +1,131 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const accrualBatchSize = 500
+
+type TransactionRepository interface {
+	ListContractNumbersByStatus(ctx context.Context, status, after string, limit int) ([]string, error)
+}
+
+type InstallmentRepository interface {
+	ListInstallmentsForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) ([]model.Installment, error)
+	UpdateInstallmentPayments(ctx context.Context, tx repo.DBTx, installments []model.Installment) error
+}
+
+type LedgerRepository interface {
+	PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error
+	TrialBalance(ctx context.Context, asOf time.Time) ([]model.TrialBalanceLine, error)
+}
+
+type LedgerUsecase interface {
+	// TrialBalance returns the debits and credits of every account posted before asOf.
+	TrialBalance(ctx context.Context, asOf time.Time) ([]model.TrialBalanceLine, error)
+	// AccrueInterest recognises the interest of every active contract's periods due
+	// on or before asOf. It returns the number of contracts that accrued interest.
+	AccrueInterest(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type ledgerUsecase struct {
+	db              *sqlx.DB
+	txRepo          TransactionRepository
+	installmentRepo InstallmentRepository
+	ledgerRepo      LedgerRepository
+}
+
+func NewLedgerUsecase(db *sqlx.DB, txRepo TransactionRepository, installmentRepo InstallmentRepository, ledgerRepo LedgerRepository) LedgerUsecase {
+	return &ledgerUsecase{
+		db:              db,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		ledgerRepo:      ledgerRepo,
+	}
+}
+
+func (u *ledgerUsecase) TrialBalance(ctx context.Context, asOf time.Time) ([]model.TrialBalanceLine, error) {
+	lines, err := u.ledgerRepo.TrialBalance(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan neraca saldo: %w", err)
+	}
+	return lines, nil
+}
+
+func (u *ledgerUsecase) AccrueInterest(ctx context.Context, asOf time.Time) (int, error) {
+	var (
+		after   string
+		accrued int
+		failed  int
+	)
+	for {
+		numbers, err := u.txRepo.ListContractNumbersByStatus(ctx, model.TransactionStatusActive, after, accrualBatchSize)
+		if err != nil {
+			return accrued, fmt.Errorf("gagal mendapatkan daftar kontrak: %w", err)
+		}
+
+		for _, contractNumber := range numbers {
+			if err := ctx.Err(); err != nil {
+				return accrued, err
+			}
+			// One broken contract must not stop the others from accruing.
+			ok, err := u.accrue(ctx, contractNumber, asOf)
+			if err != nil {
+				log.Printf("Error - gagal mengakui bunga %s: %v", contractNumber, err)
+				failed++
+				continue
+			}
+			if ok {
+				accrued++
+			}
+		}
+
+		if len(numbers) < accrualBatchSize {
+			break
+		}
+		after = numbers[len(numbers)-1]
+	}
+
+	if failed > 0 {
+		return accrued, fmt.Errorf("gagal mengakui bunga %d kontrak", failed)
+	}
+	return accrued, nil
+}
+
+// accrue posts the interest accrual of one contract together with the updated
+// schedule, and reports whether there was anything to accrue.
+func (u *ledgerUsecase) accrue(ctx context.Context, contractNumber string, asOf time.Time) (bool, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	installments, err := u.installmentRepo.ListInstallmentsForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return false, err
+	}
+
+	amount := service.AccrueInterest(installments, asOf)
+	if amount == 0 {
+		return false, nil
+	}
+
+	if err := u.installmentRepo.UpdateInstallmentPayments(ctx, dbTx, installments); err != nil {
+		return false, err
+	}
+	if err := u.ledgerRepo.PostEntries(ctx, dbTx, service.InterestAccrualEntries(contractNumber, amount, asOf)); err != nil {
+		return false, err
+	}
+
+	return true, dbTx.Commit()
+}
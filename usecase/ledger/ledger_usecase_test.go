@@ -0,0 +1,125 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/model"
+	repo "multifinance/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *mockTransactionRepository) ListContractNumbersByStatus(ctx context.Context, status, after string, limit int) ([]string, error) {
+	args := m.Called(ctx, status, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type mockInstallmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockInstallmentRepository) ListInstallmentsForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) ([]model.Installment, error) {
+	args := m.Called(ctx, tx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Installment), args.Error(1)
+}
+
+func (m *mockInstallmentRepository) UpdateInstallmentPayments(ctx context.Context, tx repo.DBTx, installments []model.Installment) error {
+	args := m.Called(ctx, tx, installments)
+	return args.Error(0)
+}
+
+type mockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *mockLedgerRepository) PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error {
+	args := m.Called(ctx, tx, entries)
+	return args.Error(0)
+}
+
+func (m *mockLedgerRepository) TrialBalance(ctx context.Context, asOf time.Time) ([]model.TrialBalanceLine, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TrialBalanceLine), args.Error(1)
+}
+
+func TestLedgerUsecase_AccrueInterest(t *testing.T) {
+	asOf := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	// CON-1 has one period due and one still running; CON-2 has nothing due; CON-3 fails.
+	jadwalCON1 := []model.Installment{
+		{ContractNumber: "CON-1", Period: 1, DueDate: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), Interest: 50000, Status: model.InstallmentStatusUnpaid},
+		{ContractNumber: "CON-1", Period: 2, DueDate: time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC), Interest: 50000, Status: model.InstallmentStatusUnpaid},
+	}
+	jadwalCON2 := []model.Installment{
+		{ContractNumber: "CON-2", Period: 1, DueDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Interest: 30000, Status: model.InstallmentStatusUnpaid},
+	}
+
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	txRepo := &mockTransactionRepository{}
+	installmentRepo := &mockInstallmentRepository{}
+	ledgerRepo := &mockLedgerRepository{}
+
+	txRepo.On("ListContractNumbersByStatus", mock.Anything, model.TransactionStatusActive, "", accrualBatchSize).Return([]string{"CON-1", "CON-2", "CON-3"}, nil).Once()
+
+	sqlMock.ExpectBegin()
+	installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalCON1, nil).Once()
+	installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.MatchedBy(func(items []model.Installment) bool {
+		return items[0].AccruedInterest == 50000 && items[1].AccruedInterest == 0
+	})).Return(nil).Once()
+	ledgerRepo.On("PostEntries", mock.Anything, mock.Anything, mock.MatchedBy(func(entries []model.JournalEntry) bool {
+		return len(entries) == 1 && entries[0].EntryType == model.JournalEntryInterestAccrual &&
+			entries[0].Lines[0].AccountCode == model.AccountInterestReceivable && entries[0].Lines[0].Debit == 50000
+	})).Return(nil).Once()
+	sqlMock.ExpectCommit()
+
+	sqlMock.ExpectBegin()
+	installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-2").Return(jadwalCON2, nil).Once()
+	sqlMock.ExpectRollback()
+
+	sqlMock.ExpectBegin()
+	installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-3").Return(nil, errors.New("db error")).Once()
+	sqlMock.ExpectRollback()
+
+	uc := NewLedgerUsecase(sqlx.NewDb(db, "sqlmock"), txRepo, installmentRepo, ledgerRepo)
+	accrued, err := uc.AccrueInterest(context.Background(), asOf)
+
+	assert.EqualError(t, err, "gagal mengakui bunga 1 kontrak")
+	assert.Equal(t, 1, accrued)
+	txRepo.AssertExpectations(t)
+	installmentRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestLedgerUsecase_TrialBalance(t *testing.T) {
+	asOf := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+	ledgerRepo := &mockLedgerRepository{}
+	ledgerRepo.On("TrialBalance", mock.Anything, asOf).Return(nil, errors.New("db error")).Once()
+
+	uc := NewLedgerUsecase(nil, &mockTransactionRepository{}, &mockInstallmentRepository{}, ledgerRepo)
+	lines, err := uc.TrialBalance(context.Background(), asOf)
+
+	assert.EqualError(t, err, "gagal mendapatkan neraca saldo: db error")
+	assert.Nil(t, lines)
+}
@@ -0,0 +1,333 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultRecomputeReason = "limit policy recompute"
+
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+var (
+	ErrCustomerNotFound = errors.New("customer not found")
+	ErrLimitNotFound    = errors.New("limit not found")
+	ErrLimitDecrease    = errors.New("raise mode cannot lower an existing limit")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+type CustomerRepository interface {
+	GetCustomer(ctx context.Context, nik string) (*model.Customer, error)
+}
+
+type LimitRepository interface {
+	GetLimit(ctx context.Context, nik string, tenor int) (*model.CustomerLimit, error)
+	ListLimits(ctx context.Context, nik string) ([]model.CustomerLimit, error)
+	ListLimitsForUpdate(ctx context.Context, tx repo.DBTx, nik string) ([]model.CustomerLimit, error)
+	UpsertLimits(ctx context.Context, tx repo.DBTx, limits []model.CustomerLimit) error
+	CreateLimitAdjustments(ctx context.Context, tx repo.DBTx, adjustments []model.LimitAdjustment) error
+	CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error
+	ListLimitMovements(ctx context.Context, nik string, tenor int, beforeID int64, limit int) ([]model.LimitMovement, error)
+}
+
+type OutboxRepository interface {
+	CreateEvent(ctx context.Context, tx repo.DBTx, e *model.OutboxEvent) error
+}
+
+type AuditRepository interface {
+	CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error
+}
+
+type LimitUsecase interface {
+	GetLimits(ctx context.Context, nik string) ([]model.CustomerLimit, error)
+	SetLimits(ctx context.Context, nik string, req *dto.SetLimitsRequest) ([]model.CustomerLimit, error)
+	RecomputeLimits(ctx context.Context, nik string, req *dto.RecomputeLimitsRequest) ([]model.CustomerLimit, error)
+	// AssignLimits applies the limit policy to a customer inside the caller's transaction.
+	AssignLimits(ctx context.Context, tx repo.DBTx, customer *model.Customer, changedBy, reason string) ([]model.CustomerLimit, error)
+	// GetLimitHistory returns the movements of a tenor limit, newest first, with
+	// the current balance.
+	GetLimitHistory(ctx context.Context, nik string, tenor int, req *dto.LimitHistoryRequest) (*dto.LimitHistoryResponse, error)
+}
+
+type limitUsecase struct {
+	db           *sqlx.DB
+	customerRepo CustomerRepository
+	limitRepo    LimitRepository
+	outboxRepo   OutboxRepository
+	auditRepo    AuditRepository
+	policy       service.LimitPolicy
+}
+
+func NewLimitUsecase(db *sqlx.DB, customerRepo CustomerRepository, limitRepo LimitRepository, outboxRepo OutboxRepository, auditRepo AuditRepository, policy service.LimitPolicy) LimitUsecase {
+	return &limitUsecase{
+		db:           db,
+		customerRepo: customerRepo,
+		limitRepo:    limitRepo,
+		outboxRepo:   outboxRepo,
+		auditRepo:    auditRepo,
+		policy:       policy,
+	}
+}
+
+func (u *limitUsecase) GetLimits(ctx context.Context, nik string) ([]model.CustomerLimit, error) {
+	if _, err := u.getCustomer(ctx, nik); err != nil {
+		return nil, err
+	}
+
+	limits, err := u.limitRepo.ListLimits(ctx, nik)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+	return limits, nil
+}
+
+func (u *limitUsecase) SetLimits(ctx context.Context, nik string, req *dto.SetLimitsRequest) ([]model.CustomerLimit, error) {
+	if _, err := u.getCustomer(ctx, nik); err != nil {
+		return nil, err
+	}
+
+	limits := make([]model.CustomerLimit, 0, len(req.Limits))
+	for _, item := range req.Limits {
+		limits = append(limits, model.CustomerLimit{CustomerNIK: nik, Tenor: item.Tenor, CreditLine: item.LimitAmount})
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = dto.LimitModeSet
+	}
+
+	return u.inTx(ctx, func(tx repo.DBTx) ([]model.CustomerLimit, error) {
+		return u.applyLimits(ctx, tx, nik, limits, mode, req.ChangedBy, req.Reason)
+	})
+}
+
+func (u *limitUsecase) RecomputeLimits(ctx context.Context, nik string, req *dto.RecomputeLimitsRequest) ([]model.CustomerLimit, error) {
+	customer, err := u.getCustomer(ctx, nik)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = defaultRecomputeReason
+	}
+
+	return u.inTx(ctx, func(tx repo.DBTx) ([]model.CustomerLimit, error) {
+		return u.AssignLimits(ctx, tx, customer, req.ChangedBy, reason)
+	})
+}
+
+func (u *limitUsecase) AssignLimits(ctx context.Context, tx repo.DBTx, customer *model.Customer, changedBy, reason string) ([]model.CustomerLimit, error) {
+	limits, err := u.policy.Compute(customer, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung limit customer: %w", err)
+	}
+
+	return u.applyLimits(ctx, tx, customer.NIK, limits, dto.LimitModeSet, changedBy, reason)
+}
+
+// applyLimits sets the credit line of the given tenors and records an adjustment,
+// an audit entry and, when the available amount changes, a movement for each of
+// them. The available amount moves by the change in the line, so what active
+// contracts and holds use stays used; lowering a line below that usage leaves the
+// tenor overdrawn until repayments catch up.
+// It returns every tenor limit of the customer after the change. An authenticated
+// caller is recorded as the author instead of the changedBy the client sent.
+func (u *limitUsecase) applyLimits(ctx context.Context, tx repo.DBTx, nik string, limits []model.CustomerLimit, mode, changedBy, reason string) ([]model.CustomerLimit, error) {
+	if p := auth.FromContext(ctx); p != nil {
+		changedBy = p.Actor()
+	}
+
+	current, err := u.limitRepo.ListLimitsForUpdate(ctx, tx, nik)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+
+	byTenor := make(map[int]model.CustomerLimit, len(current))
+	for _, l := range current {
+		byTenor[l.Tenor] = l
+	}
+
+	now := time.Now()
+	updated := make([]model.CustomerLimit, 0, len(limits))
+	adjustments := make([]model.LimitAdjustment, 0, len(limits))
+	audits := make([]model.AuditLog, 0, len(limits))
+	movements := make([]model.LimitMovement, 0, len(limits))
+	for _, l := range limits {
+		previous, existed := byTenor[l.Tenor]
+		if mode == dto.LimitModeRaise && l.CreditLine < previous.CreditLine {
+			return nil, ErrLimitDecrease
+		}
+
+		delta := l.CreditLine - previous.CreditLine
+		next := model.CustomerLimit{
+			CustomerNIK: nik,
+			Tenor:       l.Tenor,
+			CreditLine:  l.CreditLine,
+			LimitAmount: previous.LimitAmount + delta,
+		}
+
+		var before *int64
+		if existed {
+			before = &previous.LimitAmount
+		}
+		audit, err := service.NewLimitAudit(ctx, nik, l.Tenor, before, next.LimitAmount, reason, now)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuat audit limit: %w", err)
+		}
+		audits = append(audits, audit)
+
+		if delta != 0 {
+			movements = append(movements, model.LimitMovement{
+				CustomerNIK:   nik,
+				Tenor:         l.Tenor,
+				Delta:         delta,
+				Balance:       next.LimitAmount,
+				ReferenceType: model.LimitMovementAdjustment,
+				Note:          reason,
+				CreatedAt:     now,
+			})
+		}
+
+		adjustments = append(adjustments, model.LimitAdjustment{
+			CustomerNIK:    nik,
+			Tenor:          l.Tenor,
+			PreviousAmount: previous.CreditLine,
+			NewAmount:      l.CreditLine,
+			ChangedBy:      changedBy,
+			Reason:         reason,
+			CreatedAt:      now,
+		})
+		updated = append(updated, next)
+		byTenor[l.Tenor] = next
+	}
+
+	if err := u.limitRepo.UpsertLimits(ctx, tx, updated); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui limit: %w", err)
+	}
+
+	if err := u.limitRepo.CreateLimitAdjustments(ctx, tx, adjustments); err != nil {
+		return nil, fmt.Errorf("gagal mencatat perubahan limit: %w", err)
+	}
+
+	if err := u.limitRepo.CreateLimitMovements(ctx, tx, movements); err != nil {
+		return nil, fmt.Errorf("gagal mencatat mutasi limit: %w", err)
+	}
+
+	if err := u.auditRepo.CreateEntries(ctx, tx, audits); err != nil {
+		return nil, fmt.Errorf("gagal mencatat audit limit: %w", err)
+	}
+
+	event, err := service.NewLimitEvent(nik, adjustments, now)
+	if err == nil {
+		err = u.outboxRepo.CreateEvent(ctx, tx, event)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan event %s: %w", model.EventLimitChanged, err)
+	}
+
+	result := make([]model.CustomerLimit, 0, len(byTenor))
+	for _, l := range byTenor {
+		result = append(result, l)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Tenor < result[j].Tenor })
+
+	return result, nil
+}
+
+func (u *limitUsecase) GetLimitHistory(ctx context.Context, nik string, tenor int, req *dto.LimitHistoryRequest) (*dto.LimitHistoryResponse, error) {
+	if _, err := u.getCustomer(ctx, nik); err != nil {
+		return nil, err
+	}
+
+	current, err := u.limitRepo.GetLimit(ctx, nik, tenor)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+	if current == nil {
+		return nil, ErrLimitNotFound
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	var beforeID int64
+	if req.Cursor != "" {
+		if beforeID, err = strconv.ParseInt(req.Cursor, 10, 64); err != nil || beforeID <= 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists.
+	movements, err := u.limitRepo.ListLimitMovements(ctx, nik, tenor, beforeID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan mutasi limit: %w", err)
+	}
+
+	resp := &dto.LimitHistoryResponse{
+		CustomerNIK: nik,
+		Tenor:       tenor,
+		Balance:     current.LimitAmount,
+		Items:       []dto.LimitMovementResponse{},
+	}
+	if len(movements) > limit {
+		movements = movements[:limit]
+		resp.NextCursor = strconv.FormatInt(movements[limit-1].ID, 10)
+	}
+	for i := range movements {
+		resp.Items = append(resp.Items, dto.NewLimitMovementResponse(&movements[i]))
+	}
+
+	return resp, nil
+}
+
+// inTx runs fn in a new database transaction and commits it when fn succeeds.
+func (u *limitUsecase) inTx(ctx context.Context, fn func(tx repo.DBTx) ([]model.CustomerLimit, error)) ([]model.CustomerLimit, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	limits, err := fn(dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return limits, nil
+}
+
+func (u *limitUsecase) getCustomer(ctx context.Context, nik string) (*model.Customer, error) {
+	customer, err := u.customerRepo.GetCustomer(ctx, nik)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data customer: %w", err)
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
+}
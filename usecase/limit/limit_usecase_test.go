@@ -0,0 +1,442 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCustomerRepository struct {
+	mock.Mock
+}
+
+func (m *mockCustomerRepository) GetCustomer(ctx context.Context, nik string) (*model.Customer, error) {
+	args := m.Called(ctx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+type mockLimitRepository struct {
+	mock.Mock
+}
+
+func (m *mockLimitRepository) ListLimits(ctx context.Context, nik string) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+func (m *mockLimitRepository) ListLimitsForUpdate(ctx context.Context, tx repo.DBTx, nik string) ([]model.CustomerLimit, error) {
+	args := m.Called(ctx, tx, nik)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CustomerLimit), args.Error(1)
+}
+
+func (m *mockLimitRepository) UpsertLimits(ctx context.Context, tx repo.DBTx, limits []model.CustomerLimit) error {
+	args := m.Called(ctx, tx, limits)
+	return args.Error(0)
+}
+
+func (m *mockLimitRepository) CreateLimitAdjustments(ctx context.Context, tx repo.DBTx, adjustments []model.LimitAdjustment) error {
+	args := m.Called(ctx, tx, adjustments)
+	return args.Error(0)
+}
+
+func (m *mockLimitRepository) CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error {
+	args := m.Called(ctx, tx, movements)
+	return args.Error(0)
+}
+
+func (m *mockLimitRepository) GetLimit(ctx context.Context, nik string, tenor int) (*model.CustomerLimit, error) {
+	args := m.Called(ctx, nik, tenor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomerLimit), args.Error(1)
+}
+
+func (m *mockLimitRepository) ListLimitMovements(ctx context.Context, nik string, tenor int, beforeID int64, limit int) ([]model.LimitMovement, error) {
+	args := m.Called(ctx, nik, tenor, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.LimitMovement), args.Error(1)
+}
+
+// recordingOutbox keeps the events written to the outbox.
+type recordingOutbox struct {
+	events []model.OutboxEvent
+}
+
+func (o *recordingOutbox) CreateEvent(ctx context.Context, tx repo.DBTx, e *model.OutboxEvent) error {
+	o.events = append(o.events, *e)
+	return nil
+}
+
+// recordingAudit keeps the audit entries written.
+type recordingAudit struct {
+	entries []model.AuditLog
+}
+
+func (a *recordingAudit) CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error {
+	a.entries = append(a.entries, entries...)
+	return nil
+}
+
+func TestLimitUsecase_SetLimits(t *testing.T) {
+	const nik = "3171010101900001"
+	// Tenor 2 has a 400k line of which contracts already use 200k.
+	currentLimits := []model.CustomerLimit{
+		{CustomerNIK: nik, Tenor: 1, CreditLine: 100000, LimitAmount: 100000},
+		{CustomerNIK: nik, Tenor: 2, CreditLine: 400000, LimitAmount: 200000},
+	}
+
+	tests := []struct {
+		namaTest       string
+		req            *dto.SetLimitsRequest
+		setupMocks     func(limitRepo *mockLimitRepository, sqlMock sqlmock.Sqlmock)
+		erorDiharapkan error
+		hasilLimit     []model.CustomerLimit
+		jumlahEvent    int
+	}{
+		{
+			namaTest: "set limit mengubah plafon dan menggeser sisa limit sebesar selisihnya",
+			req: &dto.SetLimitsRequest{
+				Mode:      dto.LimitModeSet,
+				Limits:    []dto.LimitItem{{Tenor: 2, LimitAmount: 300000}, {Tenor: 3, LimitAmount: 500000}},
+				ChangedBy: "credit.ops",
+				Reason:    "review gaji",
+			},
+			setupMocks: func(limitRepo *mockLimitRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				limitRepo.On("ListLimitsForUpdate", mock.Anything, mock.Anything, nik).Return(currentLimits, nil).Once()
+				limitRepo.On("UpsertLimits", mock.Anything, mock.Anything, []model.CustomerLimit{
+					{CustomerNIK: nik, Tenor: 2, CreditLine: 300000, LimitAmount: 100000},
+					{CustomerNIK: nik, Tenor: 3, CreditLine: 500000, LimitAmount: 500000},
+				}).Return(nil).Once()
+				limitRepo.On("CreateLimitAdjustments", mock.Anything, mock.Anything, mock.MatchedBy(func(adj []model.LimitAdjustment) bool {
+					return len(adj) == 2 &&
+						adj[0].PreviousAmount == 400000 && adj[0].NewAmount == 300000 &&
+						adj[1].PreviousAmount == 0 && adj[1].NewAmount == 500000 &&
+						adj[0].ChangedBy == "credit.ops" && adj[0].Reason == "review gaji"
+				})).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.MatchedBy(func(m []model.LimitMovement) bool {
+					return len(m) == 2 &&
+						m[0].Delta == -100000 && m[0].Balance == 100000 &&
+						m[1].Delta == 500000 && m[1].Balance == 500000 &&
+						m[0].ReferenceType == model.LimitMovementAdjustment && m[0].Note == "review gaji"
+				})).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			hasilLimit: []model.CustomerLimit{
+				{CustomerNIK: nik, Tenor: 1, CreditLine: 100000, LimitAmount: 100000},
+				{CustomerNIK: nik, Tenor: 2, CreditLine: 300000, LimitAmount: 100000},
+				{CustomerNIK: nik, Tenor: 3, CreditLine: 500000, LimitAmount: 500000},
+			},
+			jumlahEvent: 1,
+		},
+		{
+			// 350k is above what is available but below the line, so it is a decrease.
+			namaTest: "mode raise menolak penurunan plafon",
+			req: &dto.SetLimitsRequest{
+				Mode:      dto.LimitModeRaise,
+				Limits:    []dto.LimitItem{{Tenor: 2, LimitAmount: 350000}},
+				ChangedBy: "credit.ops",
+				Reason:    "kenaikan limit",
+			},
+			setupMocks: func(limitRepo *mockLimitRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				limitRepo.On("ListLimitsForUpdate", mock.Anything, mock.Anything, nik).Return(currentLimits, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrLimitDecrease,
+		},
+		{
+			namaTest: "mode kosong diperlakukan sebagai set",
+			req: &dto.SetLimitsRequest{
+				Limits:    []dto.LimitItem{{Tenor: 2, LimitAmount: 150000}},
+				ChangedBy: "credit.ops",
+				Reason:    "review gaji",
+			},
+			setupMocks: func(limitRepo *mockLimitRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				limitRepo.On("ListLimitsForUpdate", mock.Anything, mock.Anything, nik).Return(currentLimits, nil).Once()
+				limitRepo.On("UpsertLimits", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("CreateLimitAdjustments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			hasilLimit: []model.CustomerLimit{
+				{CustomerNIK: nik, Tenor: 1, CreditLine: 100000, LimitAmount: 100000},
+				{CustomerNIK: nik, Tenor: 2, CreditLine: 150000, LimitAmount: -50000},
+			},
+			jumlahEvent: 1,
+		},
+		{
+			namaTest: "gagal memperbarui limit",
+			req: &dto.SetLimitsRequest{
+				Mode:      dto.LimitModeSet,
+				Limits:    []dto.LimitItem{{Tenor: 1, LimitAmount: 300000}},
+				ChangedBy: "credit.ops",
+				Reason:    "review gaji",
+			},
+			setupMocks: func(limitRepo *mockLimitRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				limitRepo.On("ListLimitsForUpdate", mock.Anything, mock.Anything, nik).Return(currentLimits, nil).Once()
+				limitRepo.On("UpsertLimits", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("database error")).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("gagal memperbarui limit: database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Gagal membuat mock database: %v", err)
+			}
+			defer db.Close()
+
+			customerRepo := &mockCustomerRepository{}
+			customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+			limitRepo := &mockLimitRepository{}
+			tt.setupMocks(limitRepo, sqlMock)
+
+			outbox := &recordingOutbox{}
+			uc := NewLimitUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, limitRepo, outbox, &recordingAudit{}, nil)
+			limits, err := uc.SetLimits(context.Background(), nik, tt.req)
+
+			if tt.erorDiharapkan != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.erorDiharapkan.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.hasilLimit, limits)
+			}
+			assert.Len(t, outbox.events, tt.jumlahEvent)
+			for _, e := range outbox.events {
+				assert.Equal(t, model.EventLimitChanged, e.EventType)
+				assert.Equal(t, nik, e.AggregateID)
+			}
+			limitRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+type stubLimitPolicy struct {
+	limits []model.CustomerLimit
+}
+
+func (p *stubLimitPolicy) Compute(customer *model.Customer, now time.Time) ([]model.CustomerLimit, error) {
+	return p.limits, nil
+}
+
+func TestLimitUsecase_RecomputeLimits(t *testing.T) {
+	const nik = "3171010101900001"
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Gagal membuat mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Tenor 1 has 800.000 drawn from a 1.000.000 line and tenor 3 is fully
+	// drawn; a recompute must keep what is drawn and only move by the change
+	// in the line.
+	policyLimits := []model.CustomerLimit{
+		{CustomerNIK: nik, Tenor: 1, CreditLine: 1500000},
+		{CustomerNIK: nik, Tenor: 2, CreditLine: 10000000},
+		{CustomerNIK: nik, Tenor: 3, CreditLine: 3000000},
+	}
+	expected := []model.CustomerLimit{
+		{CustomerNIK: nik, Tenor: 1, CreditLine: 1500000, LimitAmount: 700000},
+		{CustomerNIK: nik, Tenor: 2, CreditLine: 10000000, LimitAmount: 10000000},
+		{CustomerNIK: nik, Tenor: 3, CreditLine: 3000000, LimitAmount: 0},
+	}
+
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik, Salary: 10000000}, nil)
+	limitRepo := &mockLimitRepository{}
+	sqlMock.ExpectBegin()
+	limitRepo.On("ListLimitsForUpdate", mock.Anything, mock.Anything, nik).
+		Return([]model.CustomerLimit{
+			{CustomerNIK: nik, Tenor: 1, CreditLine: 1000000, LimitAmount: 200000},
+			{CustomerNIK: nik, Tenor: 3, CreditLine: 3000000, LimitAmount: 0},
+		}, nil).Once()
+	limitRepo.On("UpsertLimits", mock.Anything, mock.Anything, expected).Return(nil).Once()
+	limitRepo.On("CreateLimitAdjustments", mock.Anything, mock.Anything, mock.MatchedBy(func(adj []model.LimitAdjustment) bool {
+		return len(adj) == 3 && adj[0].PreviousAmount == 1000000 && adj[0].NewAmount == 1500000 && adj[0].Reason == defaultRecomputeReason
+	})).Return(nil).Once()
+	limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.MatchedBy(func(movements []model.LimitMovement) bool {
+		return len(movements) == 2 &&
+			movements[0].Tenor == 1 && movements[0].Delta == 500000 && movements[0].Balance == 700000 &&
+			movements[1].Tenor == 2 && movements[1].Delta == 10000000
+	})).Return(nil).Once()
+	sqlMock.ExpectCommit()
+
+	audit := &recordingAudit{}
+	uc := NewLimitUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, limitRepo, &recordingOutbox{}, audit, &stubLimitPolicy{limits: policyLimits})
+	limits, err := uc.RecomputeLimits(context.Background(), nik, &dto.RecomputeLimitsRequest{ChangedBy: "risk"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, limits)
+	if assert.Len(t, audit.entries, 3) {
+		assert.Equal(t, model.AuditActionUpdate, audit.entries[0].Action)
+		assert.JSONEq(t, `{"customer_nik":"3171010101900001","tenor":1,"limit_amount":200000}`, string(audit.entries[0].Before))
+		assert.JSONEq(t, `{"customer_nik":"3171010101900001","tenor":1,"limit_amount":700000}`, string(audit.entries[0].After))
+		assert.Equal(t, model.AuditActionCreate, audit.entries[1].Action)
+		assert.Nil(t, audit.entries[1].Before)
+		assert.Equal(t, service.SystemActor, audit.entries[1].Actor)
+	}
+	limitRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestLimitUsecase_SetLimits_RecordsAuthenticatedCaller(t *testing.T) {
+	const nik = "3171010101900001"
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Gagal membuat mock database: %v", err)
+	}
+	defer db.Close()
+
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+	limitRepo := &mockLimitRepository{}
+	sqlMock.ExpectBegin()
+	limitRepo.On("ListLimitsForUpdate", mock.Anything, mock.Anything, nik).Return([]model.CustomerLimit{}, nil).Once()
+	limitRepo.On("UpsertLimits", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	limitRepo.On("CreateLimitAdjustments", mock.Anything, mock.Anything, mock.MatchedBy(func(adj []model.LimitAdjustment) bool {
+		return len(adj) == 1 && adj[0].ChangedBy == "staff:budi"
+	})).Return(nil).Once()
+	limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	sqlMock.ExpectCommit()
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Type: auth.PrincipalStaff, ID: "budi"})
+	ctx = auth.WithRequestID(ctx, "req-1")
+	audit := &recordingAudit{}
+	uc := NewLimitUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, limitRepo, &recordingOutbox{}, audit, nil)
+	_, err = uc.SetLimits(ctx, nik, &dto.SetLimitsRequest{
+		Mode:      dto.LimitModeSet,
+		Limits:    []dto.LimitItem{{Tenor: 1, LimitAmount: 100000}},
+		ChangedBy: "orang.lain",
+		Reason:    "review gaji",
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, audit.entries, 1) {
+		assert.Equal(t, "staff:budi", audit.entries[0].Actor)
+		assert.Equal(t, "req-1", *audit.entries[0].RequestID)
+		assert.Equal(t, "review gaji", audit.entries[0].Reason)
+		assert.Equal(t, service.LimitAuditID(nik, 1), audit.entries[0].EntityID)
+	}
+	limitRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestLimitUsecase_GetLimits_CustomerNotFound(t *testing.T) {
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, "9999999999999999").Return(nil, nil)
+
+	uc := NewLimitUsecase(nil, customerRepo, &mockLimitRepository{}, nil, nil, nil)
+	_, err := uc.GetLimits(context.Background(), "9999999999999999")
+
+	assert.Equal(t, ErrCustomerNotFound, err)
+}
+
+func TestLimitUsecase_GetLimitHistory(t *testing.T) {
+	const nik = "3171010101900001"
+	movements := []model.LimitMovement{
+		{ID: 9, CustomerNIK: nik, Tenor: 3, Delta: 200000, Balance: 500000, ReferenceType: model.LimitMovementPayment, ReferenceID: "12"},
+		{ID: 7, CustomerNIK: nik, Tenor: 3, Delta: -700000, Balance: 300000, ReferenceType: model.LimitMovementContract, ReferenceID: "CON-1"},
+		{ID: 2, CustomerNIK: nik, Tenor: 3, Delta: 1000000, Balance: 1000000, ReferenceType: model.LimitMovementOpening},
+	}
+
+	tests := []struct {
+		namaTest       string
+		req            *dto.LimitHistoryRequest
+		setupMocks     func(limitRepo *mockLimitRepository)
+		erorDiharapkan error
+		jumlahItem     int
+		cursorBerikut  string
+	}{
+		{
+			namaTest: "halaman pertama dengan cursor berikutnya",
+			req:      &dto.LimitHistoryRequest{Limit: 2},
+			setupMocks: func(limitRepo *mockLimitRepository) {
+				limitRepo.On("GetLimit", mock.Anything, nik, 3).Return(&model.CustomerLimit{CustomerNIK: nik, Tenor: 3, LimitAmount: 500000}, nil).Once()
+				limitRepo.On("ListLimitMovements", mock.Anything, nik, 3, int64(0), 3).Return(movements, nil).Once()
+			},
+			jumlahItem:    2,
+			cursorBerikut: "7",
+		},
+		{
+			namaTest: "halaman terakhir",
+			req:      &dto.LimitHistoryRequest{Cursor: "7"},
+			setupMocks: func(limitRepo *mockLimitRepository) {
+				limitRepo.On("GetLimit", mock.Anything, nik, 3).Return(&model.CustomerLimit{CustomerNIK: nik, Tenor: 3, LimitAmount: 500000}, nil).Once()
+				limitRepo.On("ListLimitMovements", mock.Anything, nik, 3, int64(7), defaultHistoryLimit+1).Return(movements[2:], nil).Once()
+			},
+			jumlahItem: 1,
+		},
+		{
+			namaTest: "tenor tanpa limit",
+			req:      &dto.LimitHistoryRequest{},
+			setupMocks: func(limitRepo *mockLimitRepository) {
+				limitRepo.On("GetLimit", mock.Anything, nik, 3).Return(nil, nil).Once()
+			},
+			erorDiharapkan: ErrLimitNotFound,
+		},
+		{
+			namaTest: "cursor tidak valid",
+			req:      &dto.LimitHistoryRequest{Cursor: "abc"},
+			setupMocks: func(limitRepo *mockLimitRepository) {
+				limitRepo.On("GetLimit", mock.Anything, nik, 3).Return(&model.CustomerLimit{CustomerNIK: nik, Tenor: 3, LimitAmount: 500000}, nil).Once()
+			},
+			erorDiharapkan: ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			customerRepo := &mockCustomerRepository{}
+			customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+			limitRepo := &mockLimitRepository{}
+			tt.setupMocks(limitRepo)
+
+			uc := NewLimitUsecase(nil, customerRepo, limitRepo, nil, nil, nil)
+			resp, err := uc.GetLimitHistory(context.Background(), nik, 3, tt.req)
+
+			if tt.erorDiharapkan != nil {
+				assert.Equal(t, tt.erorDiharapkan, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(500000), resp.Balance)
+				assert.Len(t, resp.Items, tt.jumlahItem)
+				assert.Equal(t, tt.cursorBerikut, resp.NextCursor)
+			}
+			limitRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RelayJob is the background job that forwards outbox events to the event sink.
+type RelayJob struct {
+	usecase OutboxUsecase
+}
+
+func NewRelayJob(usecase OutboxUsecase) *RelayJob {
+	return &RelayJob{usecase: usecase}
+}
+
+func (j *RelayJob) Name() string {
+	return "outbox-relay"
+}
+
+func (j *RelayJob) Run(ctx context.Context) error {
+	published, err := j.usecase.RelayPending(ctx, time.Now())
+	if published > 0 {
+		log.Printf("Outbox relay published %d events", published)
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository interface {
+	ListClaimableEvents(ctx context.Context, tx repo.DBTx, now time.Time, limit int) ([]model.OutboxEvent, error)
+	ClaimEvents(ctx context.Context, tx repo.DBTx, ids []int64, owner string, until time.Time) error
+	MarkEventPublished(ctx context.Context, id int64, at time.Time) error
+	MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+	MarkEventDead(ctx context.Context, id int64, at time.Time, lastError string) error
+}
+
+type OutboxUsecase interface {
+	// RelayPending claims the events due at now and publishes them to the sink,
+	// at least once each. A rejected event is retried with backoff until it runs
+	// out of attempts and is marked failed. It returns the number of events
+	// published.
+	RelayPending(ctx context.Context, now time.Time) (int, error)
+}
+
+type outboxUsecase struct {
+	db          *sqlx.DB
+	outboxRepo  OutboxRepository
+	sink        service.EventSink
+	backoff     service.Backoff
+	owner       string
+	claimTTL    time.Duration
+	maxAttempts int
+	batchSize   int
+}
+
+// NewOutboxUsecase builds the relay. owner identifies this process in the claims
+// it takes; a claim older than claimTTL is taken over by another relay, so it
+// must outlast publishing a whole batch.
+func NewOutboxUsecase(db *sqlx.DB, outboxRepo OutboxRepository, sink service.EventSink, backoff service.Backoff, owner string, claimTTL time.Duration, maxAttempts, batchSize int) OutboxUsecase {
+	return &outboxUsecase{
+		db:          db,
+		outboxRepo:  outboxRepo,
+		sink:        sink,
+		backoff:     backoff,
+		owner:       owner,
+		claimTTL:    claimTTL,
+		maxAttempts: maxAttempts,
+		batchSize:   batchSize,
+	}
+}
+
+func (u *outboxUsecase) RelayPending(ctx context.Context, now time.Time) (int, error) {
+	events, err := u.claim(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var published, failed int
+	for i := range events {
+		e := &events[i]
+		if err := ctx.Err(); err != nil {
+			// The claims of the remaining events expire and another run picks them up.
+			return published, err
+		}
+
+		if err := u.sink.Publish(ctx, service.NewEventEnvelope(e)); err != nil {
+			failed++
+			if err := u.markFailed(ctx, e, now, err); err != nil {
+				return published, fmt.Errorf("gagal memperbarui event outbox: %w", err)
+			}
+			continue
+		}
+
+		// A crash before this update republishes the event, hence at-least-once.
+		if err := u.outboxRepo.MarkEventPublished(ctx, e.ID, time.Now()); err != nil {
+			return published, fmt.Errorf("gagal memperbarui event outbox: %w", err)
+		}
+		published++
+	}
+
+	if failed > 0 {
+		return published, fmt.Errorf("gagal mengirim %d event", failed)
+	}
+	return published, nil
+}
+
+// claim locks the due events, leases them to this relay and commits, so other
+// relays skip them while they are published outside the transaction.
+func (u *outboxUsecase) claim(ctx context.Context, now time.Time) ([]model.OutboxEvent, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	events, err := u.outboxRepo.ListClaimableEvents(ctx, dbTx, now, u.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan event outbox: %w", err)
+	}
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ids := make([]int64, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+	}
+	if err := u.outboxRepo.ClaimEvents(ctx, dbTx, ids, u.owner, now.Add(u.claimTTL)); err != nil {
+		return nil, fmt.Errorf("gagal mengklaim event outbox: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return events, nil
+}
+
+// markFailed schedules a retry, or marks the event failed once it has used all
+// its attempts so later events of its aggregate are no longer held back.
+func (u *outboxUsecase) markFailed(ctx context.Context, e *model.OutboxEvent, now time.Time, sendErr error) error {
+	attempts := e.Attempts + 1
+	if attempts >= u.maxAttempts {
+		log.Printf("Error - event %s (%s) gagal setelah %d percobaan dan tidak dikirim lagi: %v", e.EventID, e.EventType, attempts, sendErr)
+		return u.outboxRepo.MarkEventDead(ctx, e.ID, now, sendErr.Error())
+	}
+
+	next := now.Add(u.backoff.Delay(attempts))
+	log.Printf("Error - gagal mengirim event %s (%s), dicoba lagi %s: %v", e.EventID, e.EventType, next.Format(time.RFC3339), sendErr)
+	return u.outboxRepo.MarkEventFailed(ctx, e.ID, next, sendErr.Error())
+}
@@ -0,0 +1,147 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *mockOutboxRepository) ListClaimableEvents(ctx context.Context, tx repo.DBTx, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.OutboxEvent), args.Error(1)
+}
+
+func (m *mockOutboxRepository) ClaimEvents(ctx context.Context, tx repo.DBTx, ids []int64, owner string, until time.Time) error {
+	args := m.Called(ctx, tx, ids, owner, until)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepository) MarkEventPublished(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepository) MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	args := m.Called(ctx, id, nextAttemptAt, lastError)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepository) MarkEventDead(ctx context.Context, id int64, at time.Time, lastError string) error {
+	args := m.Called(ctx, id, at, lastError)
+	return args.Error(0)
+}
+
+// stubSink rejects the events listed in fail and records the rest.
+type stubSink struct {
+	fail      map[string]bool
+	published []string
+}
+
+func (s *stubSink) Publish(ctx context.Context, event service.EventEnvelope) error {
+	if s.fail[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func newTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return sqlx.NewDb(db, "sqlmock"), sqlMock
+}
+
+func TestOutboxUsecase_RelayPending(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	events := []model.OutboxEvent{
+		{ID: 1, EventID: "e1", EventType: model.EventContractCreated, AggregateID: "CON-1", Payload: []byte(`{}`)},
+		{ID: 2, EventID: "e2", EventType: model.EventContractCreated, AggregateID: "CON-2", Payload: []byte(`{}`), Attempts: 2},
+		{ID: 4, EventID: "e4", EventType: model.EventContractCancelled, AggregateID: "CON-3", Payload: []byte(`{}`)},
+	}
+
+	db, sqlMock := newTestDB(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	outboxRepo := &mockOutboxRepository{}
+	outboxRepo.On("ListClaimableEvents", mock.Anything, mock.Anything, now, 100).Return(events, nil).Once()
+	outboxRepo.On("ClaimEvents", mock.Anything, mock.Anything, []int64{1, 2, 4}, "relay-1", now.Add(time.Minute)).Return(nil).Once()
+	outboxRepo.On("MarkEventPublished", mock.Anything, int64(1), mock.Anything).Return(nil).Once()
+	// Third failure in a row: 10s doubled twice.
+	outboxRepo.On("MarkEventFailed", mock.Anything, int64(2), now.Add(40*time.Second), "sink unavailable").Return(nil).Once()
+	outboxRepo.On("MarkEventPublished", mock.Anything, int64(4), mock.Anything).Return(nil).Once()
+
+	sink := &stubSink{fail: map[string]bool{"e2": true}}
+	uc := NewOutboxUsecase(db, outboxRepo, sink, service.Backoff{Base: 10 * time.Second, Max: time.Hour}, "relay-1", time.Minute, 5, 100)
+	published, err := uc.RelayPending(context.Background(), now)
+
+	assert.EqualError(t, err, "gagal mengirim 1 event")
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []string{"e1", "e4"}, sink.published)
+	outboxRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestOutboxUsecase_RelayPending_MarksFailedAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	events := []model.OutboxEvent{
+		{ID: 7, EventID: "e7", EventType: model.EventPaymentReceived, AggregateID: "CON-7", Payload: []byte(`{}`), Attempts: 4},
+	}
+
+	db, sqlMock := newTestDB(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	outboxRepo := &mockOutboxRepository{}
+	outboxRepo.On("ListClaimableEvents", mock.Anything, mock.Anything, now, 100).Return(events, nil).Once()
+	outboxRepo.On("ClaimEvents", mock.Anything, mock.Anything, []int64{7}, "relay-1", now.Add(time.Minute)).Return(nil).Once()
+	// The fifth attempt is the last one; no retry is scheduled.
+	outboxRepo.On("MarkEventDead", mock.Anything, int64(7), now, "sink unavailable").Return(nil).Once()
+
+	sink := &stubSink{fail: map[string]bool{"e7": true}}
+	uc := NewOutboxUsecase(db, outboxRepo, sink, service.Backoff{Base: 10 * time.Second, Max: time.Hour}, "relay-1", time.Minute, 5, 100)
+	published, err := uc.RelayPending(context.Background(), now)
+
+	assert.EqualError(t, err, "gagal mengirim 1 event")
+	assert.Equal(t, 0, published)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkEventFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestOutboxUsecase_RelayPending_NothingToClaim(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	db, sqlMock := newTestDB(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+
+	outboxRepo := &mockOutboxRepository{}
+	outboxRepo.On("ListClaimableEvents", mock.Anything, mock.Anything, now, 100).Return([]model.OutboxEvent{}, nil).Once()
+
+	uc := NewOutboxUsecase(db, outboxRepo, &stubSink{}, service.Backoff{Base: 10 * time.Second, Max: time.Hour}, "relay-1", time.Minute, 5, 100)
+	published, err := uc.RelayPending(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+	outboxRepo.AssertNotCalled(t, "ClaimEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,368 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrContractNotActive    = errors.New("contract is not active")
+	ErrOverpayment          = errors.New("payment exceeds outstanding amount")
+	ErrSettlementDateInPast = errors.New("settlement date is in the past")
+)
+
+type TransactionRepository interface {
+	GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error)
+	GetTransactionForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) (*model.Transaction, error)
+	UpdateTransactionStatus(ctx context.Context, tx repo.DBTx, contractNumber, status string) error
+}
+
+type InstallmentRepository interface {
+	ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error)
+	ListInstallmentsForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) ([]model.Installment, error)
+	UpdateInstallmentPayments(ctx context.Context, tx repo.DBTx, installments []model.Installment) error
+}
+
+type LimitRepository interface {
+	GetLimitForUpdate(ctx context.Context, tx repo.DBTx, nik string, tenor int) (*model.CustomerLimit, error)
+	UpdateLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) error
+	CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error
+}
+
+type PaymentRepository interface {
+	CreatePayment(ctx context.Context, tx repo.DBTx, p *model.Payment) error
+	ListPayments(ctx context.Context, contractNumber string) ([]model.Payment, error)
+}
+
+type SettlementRepository interface {
+	CreateSettlement(ctx context.Context, tx repo.DBTx, s *model.Settlement) error
+}
+
+type LedgerRepository interface {
+	ContractBalance(ctx context.Context, tx repo.DBTx, contractNumber, account string) (int64, error)
+	PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error
+}
+
+type OutboxRepository interface {
+	CreateEvent(ctx context.Context, tx repo.DBTx, e *model.OutboxEvent) error
+}
+
+type AuditRepository interface {
+	CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error
+}
+
+type PaymentUsecase interface {
+	// PostPayment collects the late penalty owed first, then allocates the rest of
+	// a repayment to the oldest unpaid periods, interest first, and returns the
+	// principal portion to the customer's limit for the contract tenor.
+	PostPayment(ctx context.Context, contractNumber string, req *dto.CreatePaymentRequest) (*model.Payment, error)
+	ListPayments(ctx context.Context, contractNumber string) ([]model.Payment, error)
+	// QuoteSettlement prices paying off an active contract on date, which must not
+	// be in the past.
+	QuoteSettlement(ctx context.Context, contractNumber string, date time.Time) (*model.Settlement, error)
+	// SettleContract pays off an active contract today for exactly the quoted total,
+	// closes every open period and restores the remaining principal to the limit.
+	SettleContract(ctx context.Context, contractNumber string, req *dto.SettleContractRequest) (*model.Settlement, error)
+}
+
+type paymentUsecase struct {
+	db              *sqlx.DB
+	txRepo          TransactionRepository
+	installmentRepo InstallmentRepository
+	limitRepo       LimitRepository
+	paymentRepo     PaymentRepository
+	settlementRepo  SettlementRepository
+	ledgerRepo      LedgerRepository
+	outboxRepo      OutboxRepository
+	auditRepo       AuditRepository
+	settlements     service.SettlementCalculator
+	assessor        service.DelinquencyAssessor
+}
+
+func NewPaymentUsecase(db *sqlx.DB, txRepo TransactionRepository, installmentRepo InstallmentRepository, limitRepo LimitRepository, paymentRepo PaymentRepository, settlementRepo SettlementRepository, ledgerRepo LedgerRepository, outboxRepo OutboxRepository, auditRepo AuditRepository, settlements service.SettlementCalculator, assessor service.DelinquencyAssessor) PaymentUsecase {
+	return &paymentUsecase{
+		db:              db,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		limitRepo:       limitRepo,
+		paymentRepo:     paymentRepo,
+		settlementRepo:  settlementRepo,
+		ledgerRepo:      ledgerRepo,
+		outboxRepo:      outboxRepo,
+		auditRepo:       auditRepo,
+		settlements:     settlements,
+		assessor:        assessor,
+	}
+}
+
+func (u *paymentUsecase) PostPayment(ctx context.Context, contractNumber string, req *dto.CreatePaymentRequest) (*model.Payment, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	t, err := u.txRepo.GetTransactionForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if t == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if t.Status != model.TransactionStatusActive {
+		return nil, ErrContractNotActive
+	}
+
+	installments, err := u.installmentRepo.ListInstallmentsForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan jadwal angsuran: %w", err)
+	}
+
+	now := time.Now()
+	penalty, unrecognised, err := u.penaltyOwed(ctx, dbTx, contractNumber, installments, now)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &model.Payment{
+		ContractNumber: contractNumber,
+		Amount:         req.Amount,
+		Reference:      req.Reference,
+		PaidAt:         now,
+	}
+	changed, paidOff, err := allocate(payment, installments, penalty, now)
+	if err != nil {
+		return nil, err
+	}
+	accrued := service.AccrueInterest(changed, now)
+
+	if err := u.installmentRepo.UpdateInstallmentPayments(ctx, dbTx, changed); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui angsuran: %w", err)
+	}
+
+	if err := u.paymentRepo.CreatePayment(ctx, dbTx, payment); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pembayaran: %w", err)
+	}
+
+	entries := service.InterestAccrualEntries(contractNumber, accrued, now)
+	entries = append(entries, service.PenaltyAccrualEntries(contractNumber, unrecognised, now)...)
+	entries = append(entries, service.RepaymentEntries(payment)...)
+	if err := u.ledgerRepo.PostEntries(ctx, dbTx, entries); err != nil {
+		return nil, fmt.Errorf("gagal mencatat jurnal pembayaran: %w", err)
+	}
+
+	if err := u.publishPayment(ctx, dbTx, t, payment, false); err != nil {
+		return nil, err
+	}
+
+	if err := u.restoreLimit(ctx, dbTx, t, payment, "payment on contract "+contractNumber); err != nil {
+		return nil, err
+	}
+
+	if paidOff {
+		if err := u.closeContract(ctx, dbTx, t, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return payment, nil
+}
+
+func (u *paymentUsecase) ListPayments(ctx context.Context, contractNumber string) ([]model.Payment, error) {
+	t, err := u.txRepo.GetTransaction(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if t == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	payments, err := u.paymentRepo.ListPayments(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pembayaran: %w", err)
+	}
+	return payments, nil
+}
+
+// penaltyOwed returns the late penalty a contract owes as of asOf, and the part of
+// it the delinquency job has not recognised yet, which must be accrued before it
+// is collected. The assessed penalty covers the whole life of the contract, so
+// what was recognised before, collected or not, is deducted from it.
+func (u *paymentUsecase) penaltyOwed(ctx context.Context, tx repo.DBTx, contractNumber string, installments []model.Installment, asOf time.Time) (int64, int64, error) {
+	recognised, receivable, err := u.penaltyBalances(ctx, tx, contractNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+	assessed := u.assessor.Assess(contractNumber, installments, asOf).PenaltyAmount
+	unrecognised := assessed - recognised
+	return receivable + unrecognised, unrecognised, nil
+}
+
+// penaltyBalances returns the penalty recognised as income on a contract so far
+// and the part of it still receivable; the difference has been collected.
+func (u *paymentUsecase) penaltyBalances(ctx context.Context, tx repo.DBTx, contractNumber string) (int64, int64, error) {
+	income, err := u.ledgerRepo.ContractBalance(ctx, tx, contractNumber, model.AccountPenaltyIncome)
+	if err != nil {
+		return 0, 0, fmt.Errorf("gagal mendapatkan pendapatan denda: %w", err)
+	}
+	receivable, err := u.ledgerRepo.ContractBalance(ctx, tx, contractNumber, model.AccountPenaltyReceivable)
+	if err != nil {
+		return 0, 0, fmt.Errorf("gagal mendapatkan piutang denda: %w", err)
+	}
+	// Income accounts carry a credit balance.
+	return -income, receivable, nil
+}
+
+// publishPayment records the payment.received event of a payment on t in the
+// outbox inside tx.
+func (u *paymentUsecase) publishPayment(ctx context.Context, tx repo.DBTx, t *model.Transaction, payment *model.Payment, earlySettlement bool) error {
+	event, err := service.NewPaymentEvent(payment, t.Channel, earlySettlement)
+	if err == nil {
+		err = u.outboxRepo.CreateEvent(ctx, tx, event)
+	}
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan event %s: %w", model.EventPaymentReceived, err)
+	}
+	return nil
+}
+
+// restoreLimit returns the principal repaid by payment to the limit of the
+// contract tenor, and records the movement and its audit entry inside tx.
+func (u *paymentUsecase) restoreLimit(ctx context.Context, tx repo.DBTx, t *model.Transaction, payment *model.Payment, reason string) error {
+	principal, at := payment.PrincipalPaid, payment.PaidAt
+	if principal <= 0 {
+		return nil
+	}
+
+	limit, err := u.limitRepo.GetLimitForUpdate(ctx, tx, t.CustomerNIK, t.Tenor)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+	if limit == nil {
+		return nil
+	}
+
+	restored := limit.LimitAmount + principal
+	if err := u.limitRepo.UpdateLimit(ctx, tx, t.CustomerNIK, t.Tenor, restored); err != nil {
+		return fmt.Errorf("gagal mengembalikan limit: %w", err)
+	}
+
+	err = u.limitRepo.CreateLimitMovements(ctx, tx, []model.LimitMovement{{
+		CustomerNIK:   t.CustomerNIK,
+		Tenor:         t.Tenor,
+		Delta:         principal,
+		Balance:       restored,
+		ReferenceType: model.LimitMovementPayment,
+		ReferenceID:   strconv.FormatInt(payment.ID, 10),
+		Note:          reason,
+		CreatedAt:     at,
+	}})
+	if err != nil {
+		return fmt.Errorf("gagal mencatat mutasi limit: %w", err)
+	}
+
+	entry, err := service.NewLimitAudit(ctx, t.CustomerNIK, t.Tenor, &limit.LimitAmount, restored, reason, at)
+	if err == nil {
+		err = u.auditRepo.CreateEntries(ctx, tx, []model.AuditLog{entry})
+	}
+	if err != nil {
+		return fmt.Errorf("gagal mencatat audit limit: %w", err)
+	}
+	return nil
+}
+
+// closeContract marks a fully repaid contract paid off, audits it and records
+// the event in the outbox inside tx.
+func (u *paymentUsecase) closeContract(ctx context.Context, tx repo.DBTx, t *model.Transaction, at time.Time) error {
+	if err := u.txRepo.UpdateTransactionStatus(ctx, tx, t.ContractNumber, model.TransactionStatusPaidOff); err != nil {
+		return fmt.Errorf("gagal memperbarui status transaksi: %w", err)
+	}
+
+	closed := *t
+	closed.Status = model.TransactionStatusPaidOff
+	entry, err := service.NewContractAudit(ctx, t, &closed, "contract paid off", at)
+	if err == nil {
+		err = u.auditRepo.CreateEntries(ctx, tx, []model.AuditLog{entry})
+	}
+	if err != nil {
+		return fmt.Errorf("gagal mencatat audit transaksi: %w", err)
+	}
+
+	event, err := service.NewContractEvent(model.EventContractPaidOff, &closed, at)
+	if err == nil {
+		err = u.outboxRepo.CreateEvent(ctx, tx, event)
+	}
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan event %s: %w", model.EventContractPaidOff, err)
+	}
+	return nil
+}
+
+// allocate collects the penalty owed from payment.Amount first, then spreads the
+// rest over the schedule in period order, settling the interest of a period
+// before its principal. It fills the payment totals and allocations and returns
+// the periods that changed and whether the contract, penalty included, is now
+// fully paid.
+func allocate(payment *model.Payment, installments []model.Installment, penalty int64, now time.Time) ([]model.Installment, bool, error) {
+	outstanding := penalty
+	for _, i := range installments {
+		principal, interest := i.Due()
+		outstanding += principal + interest
+	}
+	if payment.Amount > outstanding {
+		return nil, false, ErrOverpayment
+	}
+
+	payment.PenaltyPaid = min(payment.Amount, penalty)
+	remaining := payment.Amount - payment.PenaltyPaid
+	changed := make([]model.Installment, 0, len(installments))
+	for _, i := range installments {
+		if remaining == 0 {
+			break
+		}
+		principalDue, interestDue := i.Due()
+		if principalDue+interestDue == 0 {
+			continue
+		}
+
+		interest := min(remaining, interestDue)
+		remaining -= interest
+		principal := min(remaining, principalDue)
+		remaining -= principal
+
+		i.PaidInterest += interest
+		i.PaidPrincipal += principal
+		i.Status = model.InstallmentStatusPartial
+		if i.PaidInterest == i.Interest && i.PaidPrincipal == i.Principal {
+			i.Status = model.InstallmentStatusPaid
+			paidAt := now
+			i.PaidAt = &paidAt
+		}
+
+		payment.InterestPaid += interest
+		payment.PrincipalPaid += principal
+		payment.Allocations = append(payment.Allocations, model.PaymentAllocation{
+			Period:    i.Period,
+			Principal: principal,
+			Interest:  interest,
+		})
+		changed = append(changed, i)
+	}
+
+	return changed, payment.Amount == outstanding, nil
+}
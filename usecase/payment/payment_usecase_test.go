@@ -0,0 +1,634 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/config"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *mockTransactionRepository) GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionRepository) GetTransactionForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, tx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionRepository) UpdateTransactionStatus(ctx context.Context, tx repo.DBTx, contractNumber, status string) error {
+	args := m.Called(ctx, tx, contractNumber, status)
+	return args.Error(0)
+}
+
+type mockInstallmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockInstallmentRepository) ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Installment), args.Error(1)
+}
+
+func (m *mockInstallmentRepository) ListInstallmentsForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) ([]model.Installment, error) {
+	args := m.Called(ctx, tx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Installment), args.Error(1)
+}
+
+func (m *mockInstallmentRepository) UpdateInstallmentPayments(ctx context.Context, tx repo.DBTx, installments []model.Installment) error {
+	args := m.Called(ctx, tx, installments)
+	return args.Error(0)
+}
+
+type mockLimitRepository struct {
+	mock.Mock
+}
+
+func (m *mockLimitRepository) GetLimitForUpdate(ctx context.Context, tx repo.DBTx, nik string, tenor int) (*model.CustomerLimit, error) {
+	args := m.Called(ctx, tx, nik, tenor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomerLimit), args.Error(1)
+}
+
+func (m *mockLimitRepository) UpdateLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) error {
+	args := m.Called(ctx, tx, nik, tenor, amount)
+	return args.Error(0)
+}
+
+func (m *mockLimitRepository) CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error {
+	args := m.Called(ctx, tx, movements)
+	return args.Error(0)
+}
+
+type mockPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *mockPaymentRepository) CreatePayment(ctx context.Context, tx repo.DBTx, p *model.Payment) error {
+	args := m.Called(ctx, tx, p)
+	return args.Error(0)
+}
+
+func (m *mockPaymentRepository) ListPayments(ctx context.Context, contractNumber string) ([]model.Payment, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+type mockSettlementRepository struct {
+	mock.Mock
+}
+
+func (m *mockSettlementRepository) CreateSettlement(ctx context.Context, tx repo.DBTx, s *model.Settlement) error {
+	args := m.Called(ctx, tx, s)
+	return args.Error(0)
+}
+
+// recordingLedger keeps the posted journal entries and, like the real repository,
+// rejects unbalanced ones.
+type recordingLedger struct {
+	entries []model.JournalEntry
+}
+
+func (l *recordingLedger) PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error {
+	for _, e := range entries {
+		if !e.Balanced() {
+			return repo.ErrUnbalancedEntry
+		}
+	}
+	l.entries = append(l.entries, entries...)
+	return nil
+}
+
+// ContractBalance sums the recorded lines of account for the contract, like the
+// real repository.
+func (l *recordingLedger) ContractBalance(ctx context.Context, tx repo.DBTx, contractNumber, account string) (int64, error) {
+	var balance int64
+	for _, e := range l.entries {
+		for _, line := range e.Lines {
+			if e.ContractNumber == contractNumber && line.AccountCode == account {
+				balance += line.Debit - line.Credit
+			}
+		}
+	}
+	return balance, nil
+}
+
+// entryTypes lists the types of the posted entries in order.
+func (l *recordingLedger) entryTypes() []string {
+	types := make([]string, 0, len(l.entries))
+	for _, e := range l.entries {
+		types = append(types, e.EntryType)
+	}
+	return types
+}
+
+// stubAssessor assesses every contract with the same late penalty.
+type stubAssessor struct {
+	penalty int64
+}
+
+func (s *stubAssessor) Assess(contractNumber string, installments []model.Installment, asOf time.Time) model.Delinquency {
+	return model.Delinquency{ContractNumber: contractNumber, PenaltyAmount: s.penalty, AsOf: asOf}
+}
+
+// recordingOutbox keeps the events written to the outbox.
+type recordingOutbox struct {
+	events []model.OutboxEvent
+}
+
+func (o *recordingOutbox) CreateEvent(ctx context.Context, tx repo.DBTx, e *model.OutboxEvent) error {
+	o.events = append(o.events, *e)
+	return nil
+}
+
+// recordingAudit keeps the audit entries written.
+type recordingAudit struct {
+	entries []model.AuditLog
+}
+
+func (a *recordingAudit) CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error {
+	a.entries = append(a.entries, entries...)
+	return nil
+}
+
+// jadwal dua periode: pokok 500000 dan bunga 50000 per periode
+func jadwalDuaPeriode() []model.Installment {
+	return []model.Installment{
+		{ContractNumber: "CON-1", Period: 1, Principal: 500000, Interest: 50000, Amount: 550000, Status: model.InstallmentStatusUnpaid},
+		{ContractNumber: "CON-1", Period: 2, Principal: 500000, Interest: 50000, Amount: 550000, Status: model.InstallmentStatusUnpaid},
+	}
+}
+
+func TestPaymentUsecase_PostPayment(t *testing.T) {
+	kontrak := &model.Transaction{ContractNumber: "CON-1", CustomerNIK: "3171010101900001", Tenor: 2, Status: model.TransactionStatusActive}
+
+	tests := []struct {
+		namaTest       string
+		amount         int64
+		setupMocks     func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock)
+		erorDiharapkan error
+		// dendaDiakui is the penalty the delinquency job already accrued and
+		// dendaDinilai the penalty assessed as of today.
+		dendaDiakui  int64
+		dendaDinilai int64
+		pokokDibayar int64
+		bungaDibayar int64
+		dendaDibayar int64
+		lunas        bool
+	}{
+		{
+			namaTest: "bayar satu periode dan kembalikan pokok ke limit",
+			amount:   550000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.MatchedBy(func(items []model.Installment) bool {
+					return len(items) == 1 && items[0].Status == model.InstallmentStatusPaid && items[0].PaidAt != nil
+				})).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 100000}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(600000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.MatchedBy(func(m []model.LimitMovement) bool {
+					return len(m) == 1 && m[0].Delta == 500000 && m[0].Balance == 600000 && m[0].ReferenceType == model.LimitMovementPayment
+				})).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			pokokDibayar: 500000,
+			bungaDibayar: 50000,
+		},
+		{
+			namaTest: "pelunasan penuh menutup kontrak",
+			amount:   1100000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(1000000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				txRepo.On("UpdateTransactionStatus", mock.Anything, mock.Anything, "CON-1", model.TransactionStatusPaidOff).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			pokokDibayar: 1000000,
+			bungaDibayar: 100000,
+			lunas:        true,
+		},
+		{
+			namaTest: "pembayaran melebihi sisa tagihan",
+			amount:   1100001,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrOverpayment,
+		},
+		{
+			namaTest:     "denda ditagih lebih dulu, termasuk yang belum diakui job",
+			amount:       550000,
+			dendaDiakui:  20000,
+			dendaDinilai: 25000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.MatchedBy(func(items []model.Installment) bool {
+					return len(items) == 1 && items[0].Status == model.InstallmentStatusPartial && items[0].PaidPrincipal == 475000
+				})).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.MatchedBy(func(p *model.Payment) bool {
+					return p.PenaltyPaid == 25000
+				})).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(475000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			pokokDibayar: 475000,
+			bungaDibayar: 50000,
+			dendaDibayar: 25000,
+		},
+		{
+			namaTest:    "jadwal lunas tetapi denda masih terutang",
+			amount:      1100000,
+			dendaDiakui: 25000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(975000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			pokokDibayar: 975000,
+			bungaDibayar: 100000,
+			dendaDibayar: 25000,
+		},
+		{
+			namaTest:    "pelunasan penuh beserta denda menutup kontrak",
+			amount:      1125000,
+			dendaDiakui: 25000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(1000000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				txRepo.On("UpdateTransactionStatus", mock.Anything, mock.Anything, "CON-1", model.TransactionStatusPaidOff).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+			pokokDibayar: 1000000,
+			bungaDibayar: 100000,
+			dendaDibayar: 25000,
+			lunas:        true,
+		},
+		{
+			namaTest: "kontrak tidak ditemukan",
+			amount:   100000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(nil, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrTransactionNotFound,
+		},
+		{
+			namaTest: "kontrak sudah lunas",
+			amount:   100000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				lunas := *kontrak
+				lunas.Status = model.TransactionStatusPaidOff
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(&lunas, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrContractNotActive,
+		},
+		{
+			namaTest: "gagal mengembalikan limit membatalkan pembayaran",
+			amount:   550000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwalDuaPeriode(), nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(500000)).Return(errors.New("db error")).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("gagal mengembalikan limit: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			txRepo := new(mockTransactionRepository)
+			installmentRepo := new(mockInstallmentRepository)
+			limitRepo := new(mockLimitRepository)
+			paymentRepo := new(mockPaymentRepository)
+			tt.setupMocks(txRepo, installmentRepo, limitRepo, paymentRepo, sqlMock)
+
+			ledger := &recordingLedger{entries: service.PenaltyAccrualEntries("CON-1", tt.dendaDiakui, time.Now())}
+			assessor := &stubAssessor{penalty: max(tt.dendaDinilai, tt.dendaDiakui)}
+			outbox := &recordingOutbox{}
+			uc := NewPaymentUsecase(sqlx.NewDb(db, "sqlmock"), txRepo, installmentRepo, limitRepo, paymentRepo, nil, ledger, outbox, &recordingAudit{}, nil, assessor)
+			hasil, err := uc.PostPayment(context.Background(), "CON-1", &dto.CreatePaymentRequest{Amount: tt.amount})
+
+			if tt.erorDiharapkan != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.erorDiharapkan.Error())
+				assert.Nil(t, hasil)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.pokokDibayar, hasil.PrincipalPaid)
+				assert.Equal(t, tt.bungaDibayar, hasil.InterestPaid)
+				assert.Equal(t, tt.dendaDibayar, hasil.PenaltyPaid)
+				// Whatever penalty was collected leaves nothing of it receivable.
+				piutang, _ := ledger.ContractBalance(context.Background(), nil, "CON-1", model.AccountPenaltyReceivable)
+				assert.Equal(t, max(tt.dendaDinilai, tt.dendaDiakui)-tt.dendaDibayar, piutang)
+				assert.Equal(t, model.EventPaymentReceived, outbox.events[0].EventType)
+				if tt.lunas {
+					assert.Len(t, outbox.events, 2)
+					assert.Equal(t, model.EventContractPaidOff, outbox.events[1].EventType)
+				} else {
+					assert.Len(t, outbox.events, 1)
+				}
+			}
+
+			txRepo.AssertExpectations(t)
+			installmentRepo.AssertExpectations(t)
+			limitRepo.AssertExpectations(t)
+			paymentRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllocate_BungaDuluBaruPokok(t *testing.T) {
+	jadwal := jadwalDuaPeriode()
+	jadwal[0].PaidInterest = 50000
+	jadwal[0].PaidPrincipal = 200000
+	jadwal[0].Status = model.InstallmentStatusPartial
+
+	payment := &model.Payment{Amount: 400000}
+	changed, lunas, err := allocate(payment, jadwal, 0, time.Now())
+
+	assert.NoError(t, err)
+	assert.False(t, lunas)
+	assert.Len(t, changed, 2)
+	// sisa pokok periode 1 dilunasi, lalu bunga periode 2 dan sebagian pokoknya
+	assert.Equal(t, model.InstallmentStatusPaid, changed[0].Status)
+	assert.Equal(t, model.InstallmentStatusPartial, changed[1].Status)
+	assert.Equal(t, []model.PaymentAllocation{
+		{Period: 1, Principal: 300000, Interest: 0},
+		{Period: 2, Principal: 50000, Interest: 50000},
+	}, payment.Allocations)
+	assert.Equal(t, int64(350000), payment.PrincipalPaid)
+	assert.Equal(t, int64(50000), payment.InterestPaid)
+}
+
+// jadwal dua periode yang dimulai 10 hari lalu, jatuh tempo 20 dan 50 hari lagi
+func kontrakBerjalan() (*model.Transaction, []model.Installment) {
+	now := time.Now()
+	kontrak := &model.Transaction{
+		ContractNumber: "CON-1",
+		CustomerNIK:    "3171010101900001",
+		Tenor:          2,
+		Status:         model.TransactionStatusActive,
+		CreatedAt:      now.AddDate(0, 0, -10),
+	}
+	jadwal := jadwalDuaPeriode()
+	jadwal[0].DueDate = now.AddDate(0, 0, 20)
+	jadwal[1].DueDate = now.AddDate(0, 0, 50)
+	return kontrak, jadwal
+}
+
+func newSettlementCalculator() service.SettlementCalculator {
+	return service.NewSettlementCalculator(
+		config.SettlementConfig{FeeRate: 0.01},
+		service.NewDelinquencyAssessor(config.DelinquencyConfig{PenaltyMethod: "flat_per_day", PenaltyFlatPerDay: 5000}),
+	)
+}
+
+func TestPaymentUsecase_QuoteSettlement(t *testing.T) {
+	kontrak, jadwal := kontrakBerjalan()
+
+	t.Run("tanggal di masa lalu ditolak", func(t *testing.T) {
+		uc := NewPaymentUsecase(nil, new(mockTransactionRepository), new(mockInstallmentRepository), nil, nil, nil, nil, nil, nil, newSettlementCalculator(), nil)
+		hasil, err := uc.QuoteSettlement(context.Background(), "CON-1", time.Now().AddDate(0, 0, -1))
+		assert.Equal(t, ErrSettlementDateInPast, err)
+		assert.Nil(t, hasil)
+	})
+
+	t.Run("bunga berjalan dihitung sampai hari ini", func(t *testing.T) {
+		txRepo := new(mockTransactionRepository)
+		installmentRepo := new(mockInstallmentRepository)
+		txRepo.On("GetTransaction", mock.Anything, "CON-1").Return(kontrak, nil).Once()
+		installmentRepo.On("ListInstallments", mock.Anything, "CON-1").Return(jadwal, nil).Once()
+
+		uc := NewPaymentUsecase(nil, txRepo, installmentRepo, nil, nil, nil, &recordingLedger{}, nil, nil, newSettlementCalculator(), nil)
+		hasil, err := uc.QuoteSettlement(context.Background(), "CON-1", time.Now())
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1000000), hasil.RemainingPrincipal)
+		assert.Equal(t, int64(16667), hasil.AccruedInterest)
+		assert.Equal(t, int64(10000), hasil.Fee)
+		assert.Equal(t, int64(1026667), hasil.Total)
+	})
+
+	t.Run("denda yang sudah dibayar lewat angsuran tidak ditagih lagi", func(t *testing.T) {
+		terlambat := append([]model.Installment(nil), jadwal...)
+		terlambat[0].DueDate = time.Now().AddDate(0, 0, -3)
+		txRepo := new(mockTransactionRepository)
+		installmentRepo := new(mockInstallmentRepository)
+		txRepo.On("GetTransaction", mock.Anything, "CON-1").Return(kontrak, nil).Once()
+		installmentRepo.On("ListInstallments", mock.Anything, "CON-1").Return(terlambat, nil).Once()
+
+		// 3 hari x 5000 sudah diakui, 10000 di antaranya sudah dibayar.
+		ledger := &recordingLedger{entries: service.PenaltyAccrualEntries("CON-1", 15000, time.Now())}
+		ledger.entries = append(ledger.entries, service.RepaymentEntries(&model.Payment{ContractNumber: "CON-1", PenaltyPaid: 10000, PaidAt: time.Now()})...)
+
+		uc := NewPaymentUsecase(nil, txRepo, installmentRepo, nil, nil, nil, ledger, nil, nil, newSettlementCalculator(), nil)
+		hasil, err := uc.QuoteSettlement(context.Background(), "CON-1", time.Now())
+
+		penuh := newSettlementCalculator().Quote(kontrak, terlambat, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(15000), penuh.Penalty)
+		assert.Equal(t, int64(5000), hasil.Penalty)
+		assert.Equal(t, penuh.Total-10000, hasil.Total)
+	})
+}
+
+func TestPaymentUsecase_SettleContract(t *testing.T) {
+	tests := []struct {
+		namaTest       string
+		amount         int64
+		setupMocks     func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, settlementRepo *mockSettlementRepository, sqlMock sqlmock.Sqlmock)
+		erorDiharapkan error
+	}{
+		{
+			namaTest: "pelunasan menutup kontrak dan mengembalikan limit",
+			amount:   1026667,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, settlementRepo *mockSettlementRepository, sqlMock sqlmock.Sqlmock) {
+				kontrak, jadwal := kontrakBerjalan()
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwal, nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.MatchedBy(func(items []model.Installment) bool {
+					return len(items) == 2 &&
+						items[0].Status == model.InstallmentStatusSettled && items[0].PaidInterest == 16667 &&
+						items[1].Status == model.InstallmentStatusSettled && items[1].PaidInterest == 0 &&
+						items[1].PaidPrincipal == 500000
+				})).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.MatchedBy(func(p *model.Payment) bool {
+					return p.Amount == 1026667 && p.PrincipalPaid == 1000000 && p.InterestPaid == 16667
+				})).Run(func(args mock.Arguments) {
+					args.Get(2).(*model.Payment).ID = 7
+				}).Return(nil).Once()
+				settlementRepo.On("CreateSettlement", mock.Anything, mock.Anything, mock.MatchedBy(func(s *model.Settlement) bool {
+					return s.PaymentID == 7 && s.Total == 1026667
+				})).Return(nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 2).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 2, int64(1000000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.MatchedBy(func(m []model.LimitMovement) bool {
+					return len(m) == 1 && m[0].Delta == 1000000 && m[0].ReferenceID == "7" && m[0].Note == "early settlement of contract CON-1"
+				})).Return(nil).Once()
+				txRepo.On("UpdateTransactionStatus", mock.Anything, mock.Anything, "CON-1", model.TransactionStatusPaidOff).Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+		},
+		{
+			namaTest: "nominal tidak sama dengan total pelunasan",
+			amount:   1000000,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, settlementRepo *mockSettlementRepository, sqlMock sqlmock.Sqlmock) {
+				kontrak, jadwal := kontrakBerjalan()
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwal, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("validation failed"),
+		},
+		{
+			namaTest: "kontrak sudah dibatalkan",
+			amount:   1026667,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, settlementRepo *mockSettlementRepository, sqlMock sqlmock.Sqlmock) {
+				kontrak, _ := kontrakBerjalan()
+				kontrak.Status = model.TransactionStatusCancelled
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrContractNotActive,
+		},
+		{
+			namaTest: "gagal menyimpan pelunasan membatalkan semuanya",
+			amount:   1026667,
+			setupMocks: func(txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, limitRepo *mockLimitRepository, paymentRepo *mockPaymentRepository, settlementRepo *mockSettlementRepository, sqlMock sqlmock.Sqlmock) {
+				kontrak, jadwal := kontrakBerjalan()
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(kontrak, nil).Once()
+				installmentRepo.On("ListInstallmentsForUpdate", mock.Anything, mock.Anything, "CON-1").Return(jadwal, nil).Once()
+				installmentRepo.On("UpdateInstallmentPayments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				paymentRepo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				settlementRepo.On("CreateSettlement", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("gagal menyimpan pelunasan: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			txRepo := new(mockTransactionRepository)
+			installmentRepo := new(mockInstallmentRepository)
+			limitRepo := new(mockLimitRepository)
+			paymentRepo := new(mockPaymentRepository)
+			settlementRepo := new(mockSettlementRepository)
+			tt.setupMocks(txRepo, installmentRepo, limitRepo, paymentRepo, settlementRepo, sqlMock)
+
+			ledger := &recordingLedger{}
+			outbox := &recordingOutbox{}
+			audit := &recordingAudit{}
+			uc := NewPaymentUsecase(sqlx.NewDb(db, "sqlmock"), txRepo, installmentRepo, limitRepo, paymentRepo, settlementRepo, ledger, outbox, audit, newSettlementCalculator(), nil)
+			hasil, err := uc.SettleContract(context.Background(), "CON-1", &dto.SettleContractRequest{Amount: tt.amount})
+
+			if tt.erorDiharapkan != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.erorDiharapkan.Error())
+				assert.Nil(t, hasil)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(7), hasil.PaymentID)
+				assert.Equal(t, []string{model.JournalEntryInterestAccrual, model.JournalEntryRepayment, model.JournalEntrySettlementFee}, ledger.entryTypes())
+				assert.Len(t, outbox.events, 2)
+				assert.Equal(t, model.EventPaymentReceived, outbox.events[0].EventType)
+				assert.Contains(t, string(outbox.events[0].Payload), `"early_settlement":true`)
+				assert.Equal(t, model.EventContractPaidOff, outbox.events[1].EventType)
+				if assert.Len(t, audit.entries, 2) {
+					assert.Equal(t, model.AuditEntityCustomerLimit, audit.entries[0].EntityType)
+					assert.Equal(t, model.AuditEntityTransaction, audit.entries[1].EntityType)
+					assert.Contains(t, string(audit.entries[1].After), `"status":"paid_off"`)
+				}
+			}
+
+			txRepo.AssertExpectations(t)
+			installmentRepo.AssertExpectations(t)
+			limitRepo.AssertExpectations(t)
+			paymentRepo.AssertExpectations(t)
+			settlementRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
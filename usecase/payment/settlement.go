@@ -0,0 +1,153 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+)
+
+func (u *paymentUsecase) QuoteSettlement(ctx context.Context, contractNumber string, date time.Time) (*model.Settlement, error) {
+	if date.Before(startOfDay(time.Now())) {
+		return nil, ErrSettlementDateInPast
+	}
+
+	t, err := u.txRepo.GetTransaction(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if t == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if t.Status != model.TransactionStatusActive {
+		return nil, ErrContractNotActive
+	}
+
+	installments, err := u.installmentRepo.ListInstallments(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan jadwal angsuran: %w", err)
+	}
+
+	quote, err := u.quote(ctx, nil, t, installments, date)
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (u *paymentUsecase) SettleContract(ctx context.Context, contractNumber string, req *dto.SettleContractRequest) (*model.Settlement, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	t, err := u.txRepo.GetTransactionForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if t == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if t.Status != model.TransactionStatusActive {
+		return nil, ErrContractNotActive
+	}
+
+	installments, err := u.installmentRepo.ListInstallmentsForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan jadwal angsuran: %w", err)
+	}
+
+	now := time.Now()
+	settlement, err := u.quote(ctx, dbTx, t, installments, now)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount != settlement.Total {
+		return nil, dto.NewValidationError([]dto.ValidationError{{
+			Field:   "amount",
+			Message: fmt.Sprintf("amount must equal the settlement total of %d", settlement.Total),
+		}})
+	}
+
+	closed := u.settlements.Close(settlement, t, installments, now)
+	accrued := service.AccrueInterest(closed, now)
+	if err := u.installmentRepo.UpdateInstallmentPayments(ctx, dbTx, closed); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui angsuran: %w", err)
+	}
+
+	payment := &model.Payment{
+		ContractNumber: contractNumber,
+		Amount:         settlement.Total,
+		PrincipalPaid:  settlement.RemainingPrincipal,
+		InterestPaid:   settlement.AccruedInterest - settlement.Discount,
+		PenaltyPaid:    settlement.Penalty,
+		Reference:      req.Reference,
+		PaidAt:         now,
+	}
+	if err := u.paymentRepo.CreatePayment(ctx, dbTx, payment); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pembayaran: %w", err)
+	}
+
+	settlement.PaymentID = payment.ID
+	settlement.CreatedAt = now
+	if err := u.settlementRepo.CreateSettlement(ctx, dbTx, &settlement); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pelunasan: %w", err)
+	}
+
+	// The delinquency job accrues penalties daily; recognise whatever it has not
+	// yet accrued up to today so the settlement clears the whole receivable.
+	accruedPenalty, err := u.ledgerRepo.ContractBalance(ctx, dbTx, contractNumber, model.AccountPenaltyReceivable)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan piutang denda: %w", err)
+	}
+
+	entries := service.InterestAccrualEntries(contractNumber, accrued, now)
+	entries = append(entries, service.PenaltyAccrualEntries(contractNumber, settlement.Penalty-accruedPenalty, now)...)
+	entries = append(entries, service.SettlementEntries(&settlement, payment)...)
+	if err := u.ledgerRepo.PostEntries(ctx, dbTx, entries); err != nil {
+		return nil, fmt.Errorf("gagal mencatat jurnal pelunasan: %w", err)
+	}
+
+	if err := u.publishPayment(ctx, dbTx, t, payment, true); err != nil {
+		return nil, err
+	}
+
+	if err := u.restoreLimit(ctx, dbTx, t, payment, "early settlement of contract "+contractNumber); err != nil {
+		return nil, err
+	}
+
+	if err := u.closeContract(ctx, dbTx, t, now); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return &settlement, nil
+}
+
+// quote prices paying off t on date. Penalty already collected by instalment
+// payments is left out, since the calculator assesses it over the whole life of
+// the contract.
+func (u *paymentUsecase) quote(ctx context.Context, tx repo.DBTx, t *model.Transaction, installments []model.Installment, date time.Time) (model.Settlement, error) {
+	quote := u.settlements.Quote(t, installments, date)
+
+	recognised, receivable, err := u.penaltyBalances(ctx, tx, t.ContractNumber)
+	if err != nil {
+		return model.Settlement{}, err
+	}
+	collected := recognised - receivable
+	quote.Penalty -= collected
+	quote.Total -= collected
+	return quote, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,32 @@
+package transaction
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// encodeCursor builds the opaque pagination cursor pointing at the last row of a page.
+func encodeCursor(createdAt time.Time, contractNumber string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + contractNumber
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return time.Time{}, "", errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return createdAt, parts[1], nil
+}
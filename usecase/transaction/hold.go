@@ -0,0 +1,374 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+)
+
+const expireBatchSize = 100
+
+func (u *transactionUsecase) CreateHold(ctx context.Context, req *dto.CreateHoldRequest) (*model.LimitHold, error) {
+	if p := auth.FromContext(ctx); p != nil && p.IsPartner() {
+		if req.Channel != "" && req.Channel != p.Partner {
+			return nil, ErrChannelForbidden
+		}
+		req.Channel = p.Partner
+	}
+
+	now := time.Now()
+
+	// A hold that could never be confirmed is refused up front.
+	if _, err := u.pricing.Quote(req.Amount, 0, req.Tenor, now); errors.Is(err, service.ErrTenorNotPriced) {
+		return nil, ErrTenorNotOffered
+	} else if err != nil {
+		return nil, fmt.Errorf("gagal menghitung harga: %w", err)
+	}
+
+	customer, err := u.customerRepo.GetCustomer(ctx, req.CustomerNIK)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data customer: %w", err)
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	if err := service.ValidateCustomerNIK(customer); err != nil {
+		return nil, err
+	}
+
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	// Holds deduct through the same conditional UPDATE as purchases, so holds and
+	// purchases racing for one tenor can never reserve more than its limit.
+	deducted, err := u.limitRepo.DeductLimit(ctx, dbTx, req.CustomerNIK, req.Tenor, req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memperbarui limit: %w", err)
+	}
+	if !deducted {
+		return nil, ErrLimitExceeded
+	}
+	limit, err := u.limitRepo.GetLimitForUpdate(ctx, dbTx, req.CustomerNIK, req.Tenor)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+
+	hold := &model.LimitHold{
+		CustomerNIK: req.CustomerNIK,
+		Tenor:       req.Tenor,
+		Amount:      req.Amount,
+		Channel:     req.Channel,
+		Status:      model.LimitHoldActive,
+		ExpiresAt:   now.Add(u.holdTTL),
+		CreatedAt:   now,
+	}
+	if err := u.holdRepo.CreateHold(ctx, dbTx, hold); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan hold limit: %w", err)
+	}
+
+	holdID := strconv.FormatInt(hold.ID, 10)
+	err = u.limitRepo.CreateLimitMovements(ctx, dbTx, []model.LimitMovement{{
+		CustomerNIK:   hold.CustomerNIK,
+		Tenor:         hold.Tenor,
+		Delta:         -hold.Amount,
+		Balance:       limit.LimitAmount,
+		ReferenceType: model.LimitMovementHold,
+		ReferenceID:   holdID,
+		CreatedAt:     now,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("gagal mencatat mutasi limit: %w", err)
+	}
+
+	err = u.audit(ctx, dbTx, now, limitAudit(hold.CustomerNIK, hold.Tenor, limit.LimitAmount+hold.Amount, limit.LimitAmount, "hold "+holdID+" placed"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return hold, nil
+}
+
+func (u *transactionUsecase) GetHold(ctx context.Context, id int64) (*model.LimitHold, error) {
+	hold, err := u.holdRepo.GetHold(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan hold limit: %w", err)
+	}
+	if hold == nil || !holdVisibleTo(ctx, hold) {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+func (u *transactionUsecase) ConfirmHold(ctx context.Context, id int64, req *dto.ConfirmHoldRequest) (*model.Transaction, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	hold, err := u.activeHoldForUpdate(ctx, dbTx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// An expired hold is left for the sweeper, which returns its amount.
+	if !now.Before(hold.ExpiresAt) {
+		return nil, ErrHoldExpired
+	}
+
+	quote, err := u.pricing.Quote(req.OTR, req.AdminFee, hold.Tenor, now)
+	if errors.Is(err, service.ErrTenorNotPriced) {
+		return nil, ErrTenorNotOffered
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung harga: %w", err)
+	}
+	if err := u.pricing.Reconcile(quote, req.Installment, req.Interest); err != nil {
+		return nil, err
+	}
+
+	totalAmount := req.OTR + req.AdminFee
+	if totalAmount > hold.Amount {
+		return nil, ErrHoldAmountExceeded
+	}
+
+	transaction := &model.Transaction{
+		CustomerNIK: hold.CustomerNIK,
+		OTR:         req.OTR,
+		AdminFee:    req.AdminFee,
+		Installment: quote.Installment,
+		Interest:    quote.Interest,
+		AssetName:   req.AssetName,
+		Tenor:       hold.Tenor,
+		Channel:     hold.Channel,
+		Status:      model.TransactionStatusActive,
+		CreatedAt:   now,
+	}
+	if err := u.bookContract(ctx, dbTx, transaction, quote.Schedule, now); err != nil {
+		return nil, err
+	}
+
+	audits, err := u.settleHold(ctx, dbTx, hold, model.LimitHoldConfirmed, transaction.ContractNumber, totalAmount, now)
+	if err != nil {
+		return nil, err
+	}
+	audits = append(audits, contractAudit(nil, transaction, "contract booked from hold "+strconv.FormatInt(hold.ID, 10)))
+	if err := u.audit(ctx, dbTx, now, audits...); err != nil {
+		return nil, err
+	}
+
+	if err := u.publish(ctx, dbTx, model.EventContractCreated, transaction, now); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func (u *transactionUsecase) ReleaseHold(ctx context.Context, id int64) (*model.LimitHold, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	hold, err := u.activeHoldForUpdate(ctx, dbTx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.releaseHold(ctx, dbTx, hold, model.LimitHoldReleased, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return hold, nil
+}
+
+func (u *transactionUsecase) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	var (
+		afterID int64
+		expired int
+		failed  int
+	)
+	for {
+		ids, err := u.holdRepo.ListExpiredHoldIDs(ctx, now, afterID, expireBatchSize)
+		if err != nil {
+			return expired, fmt.Errorf("gagal mendapatkan daftar hold limit: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			// One broken hold must not keep the others from returning their limit.
+			released, err := u.expireHold(ctx, id, now)
+			if err != nil {
+				log.Printf("Error - gagal mengakhiri hold limit %d: %v", id, err)
+				failed++
+				continue
+			}
+			if released {
+				expired++
+			}
+		}
+
+		if len(ids) < expireBatchSize {
+			break
+		}
+		afterID = ids[len(ids)-1]
+	}
+
+	if failed > 0 {
+		return expired, fmt.Errorf("gagal mengakhiri %d hold limit", failed)
+	}
+	return expired, nil
+}
+
+// expireHold releases one expired hold in its own transaction. It reports false
+// when the hold was confirmed or released since it was listed.
+func (u *transactionUsecase) expireHold(ctx context.Context, id int64, now time.Time) (bool, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	hold, err := u.holdRepo.GetHoldForUpdate(ctx, dbTx, id)
+	if err != nil {
+		return false, fmt.Errorf("gagal mendapatkan hold limit: %w", err)
+	}
+	if hold == nil || hold.Status != model.LimitHoldActive || hold.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	if err := u.releaseHold(ctx, dbTx, hold, model.LimitHoldExpired, now); err != nil {
+		return false, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return false, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+	return true, nil
+}
+
+// activeHoldForUpdate locks a hold the caller may see and checks that it is
+// still active.
+func (u *transactionUsecase) activeHoldForUpdate(ctx context.Context, tx repo.DBTx, id int64) (*model.LimitHold, error) {
+	hold, err := u.holdRepo.GetHoldForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan hold limit: %w", err)
+	}
+	if hold == nil || !holdVisibleTo(ctx, hold) {
+		return nil, ErrHoldNotFound
+	}
+	if hold.Status != model.LimitHoldActive {
+		return nil, ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+// releaseHold returns the whole held amount to the limit and closes the hold
+// with status inside tx.
+func (u *transactionUsecase) releaseHold(ctx context.Context, tx repo.DBTx, hold *model.LimitHold, status string, at time.Time) error {
+	audits, err := u.settleHold(ctx, tx, hold, status, "", 0, at)
+	if err != nil {
+		return err
+	}
+	return u.audit(ctx, tx, at, audits...)
+}
+
+// settleHold closes an active hold with status inside tx. The held amount goes
+// back to the limit and booked, the amount of the contract the hold was
+// confirmed as, is taken out again, so the history shows both movements. It
+// returns the limit audit when the balance changed.
+func (u *transactionUsecase) settleHold(ctx context.Context, tx repo.DBTx, hold *model.LimitHold, status, contractNumber string, booked int64, at time.Time) ([]auditChange, error) {
+	holdID := strconv.FormatInt(hold.ID, 10)
+	note := "hold " + holdID + " " + status
+
+	limit, err := u.limitRepo.GetLimitForUpdate(ctx, tx, hold.CustomerNIK, hold.Tenor)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+
+	var audits []auditChange
+	if limit != nil {
+		released := limit.LimitAmount + hold.Amount
+		movements := []model.LimitMovement{{
+			CustomerNIK:   hold.CustomerNIK,
+			Tenor:         hold.Tenor,
+			Delta:         hold.Amount,
+			Balance:       released,
+			ReferenceType: model.LimitMovementHoldRelease,
+			ReferenceID:   holdID,
+			Note:          note,
+			CreatedAt:     at,
+		}}
+		restored := released
+		if booked > 0 {
+			restored -= booked
+			movements = append(movements, model.LimitMovement{
+				CustomerNIK:   hold.CustomerNIK,
+				Tenor:         hold.Tenor,
+				Delta:         -booked,
+				Balance:       restored,
+				ReferenceType: model.LimitMovementContract,
+				ReferenceID:   contractNumber,
+				CreatedAt:     at,
+			})
+		}
+
+		if restored != limit.LimitAmount {
+			if err := u.limitRepo.UpdateLimit(ctx, tx, hold.CustomerNIK, hold.Tenor, restored); err != nil {
+				return nil, fmt.Errorf("gagal mengembalikan limit: %w", err)
+			}
+			audits = append(audits, limitAudit(hold.CustomerNIK, hold.Tenor, limit.LimitAmount, restored, note))
+		}
+		if err := u.limitRepo.CreateLimitMovements(ctx, tx, movements); err != nil {
+			return nil, fmt.Errorf("gagal mencatat mutasi limit: %w", err)
+		}
+	}
+
+	var number *string
+	if contractNumber != "" {
+		number = &contractNumber
+	}
+	if err := u.holdRepo.ResolveHold(ctx, tx, hold.ID, status, number, at); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui hold limit: %w", err)
+	}
+	hold.Status = status
+	hold.ContractNumber = number
+	hold.ResolvedAt = &at
+
+	return audits, nil
+}
+
+// holdVisibleTo reports whether the caller may see the hold. Like contracts,
+// partners only see holds placed through their own channel.
+func holdVisibleTo(ctx context.Context, h *model.LimitHold) bool {
+	p := auth.FromContext(ctx)
+	return p == nil || !p.IsPartner() || h.Channel == p.Partner
+}
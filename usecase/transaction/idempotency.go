@@ -0,0 +1,49 @@
+package transaction
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"multifinance/delivery/dto"
+	"multifinance/model"
+)
+
+// hashRequest fingerprints the request body so a reused key can be told apart
+// from a genuine retry. The idempotency key itself is not part of the body.
+func hashRequest(req *dto.CreateTransactionRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay returns the stored response for key on channel, or nil when the key is
+// unused there. Keys are scoped per channel, so a partner can neither collide
+// with nor replay another partner's key.
+func (u *transactionUsecase) replay(ctx context.Context, channel, key, requestHash string) (*model.Transaction, error) {
+	stored, err := u.idempotencyRepo.GetIdempotencyKey(ctx, channel, key)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan idempotency key: %w", err)
+	}
+	if stored == nil {
+		return nil, nil
+	}
+	if stored.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	var transaction model.Transaction
+	if err := json.Unmarshal(stored.Response, &transaction); err != nil {
+		return nil, fmt.Errorf("gagal membaca respons tersimpan: %w", err)
+	}
+	// The stored contract goes through the same visibility check as a lookup.
+	if !visibleTo(ctx, &transaction) {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return &transaction, nil
+}
@@ -0,0 +1,28 @@
+package transaction
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// HoldExpiryJob is the background job that returns the limit of expired holds.
+type HoldExpiryJob struct {
+	usecase TransactionUsecase
+}
+
+func NewHoldExpiryJob(usecase TransactionUsecase) *HoldExpiryJob {
+	return &HoldExpiryJob{usecase: usecase}
+}
+
+func (j *HoldExpiryJob) Name() string {
+	return "limit-hold-expiry"
+}
+
+func (j *HoldExpiryJob) Run(ctx context.Context) error {
+	expired, err := j.usecase.ExpireHolds(ctx, time.Now())
+	if expired > 0 {
+		log.Printf("Limit hold expiry released %d holds", expired)
+	}
+	return err
+}
@@ -2,20 +2,46 @@ package transaction
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"multifinance/auth"
 	"multifinance/delivery/dto"
 	"multifinance/model"
 	repo "multifinance/repository"
+	"multifinance/service"
 
 	"github.com/jmoiron/sqlx"
 )
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
 var (
-	ErrCustomerNotFound = errors.New("customer not found")
-	ErrLimitExceeded    = errors.New("transaction amount exceeds available limit")
+	ErrCustomerNotFound    = errors.New("customer not found")
+	ErrLimitExceeded       = errors.New("transaction amount exceeds available limit")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrTenorNotOffered     = errors.New("tenor is not offered")
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a different body.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used with a different request")
+
+	ErrContractNotActive        = errors.New("contract is not active")
+	ErrCancellationWindowClosed = errors.New("cancellation window has closed")
+	ErrContractHasPayments      = errors.New("contract already has payments")
+
+	// ErrChannelForbidden is returned when a partner books for another partner's channel.
+	ErrChannelForbidden = errors.New("partner cannot create transactions for another channel")
+
+	ErrHoldNotFound  = errors.New("hold not found")
+	ErrHoldNotActive = errors.New("hold is not active")
+	ErrHoldExpired   = errors.New("hold has expired")
+	// ErrHoldAmountExceeded is returned when a hold is confirmed for more than it reserved.
+	ErrHoldAmountExceeded = errors.New("contract amount exceeds the held amount")
 )
 
 type DBTx = repo.DBTx
@@ -23,39 +49,157 @@ type DB = repo.DB
 
 type CustomerRepository interface {
 	GetCustomer(ctx context.Context, nik string) (*model.Customer, error)
-	CreateCustomer(ctx context.Context, customer *model.Customer) error
+	CreateCustomer(ctx context.Context, tx repo.DBTx, customer *model.Customer) error
 }
 
 type LimitRepository interface {
-	GetLimit(ctx context.Context, nik string, tenor int) (*model.CustomerLimit, error)
+	DeductLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) (bool, error)
+	GetLimitForUpdate(ctx context.Context, tx repo.DBTx, nik string, tenor int) (*model.CustomerLimit, error)
 	UpdateLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) error
+	CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error
 }
 
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, tx repo.DBTx, transaction *model.Transaction) error
+	GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error)
+	GetTransactionForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) (*model.Transaction, error)
+	ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
+	CancelTransaction(ctx context.Context, tx repo.DBTx, contractNumber, reason string, at time.Time) error
+}
+
+type InstallmentRepository interface {
+	CreateInstallments(ctx context.Context, tx repo.DBTx, installments []model.Installment) error
+	ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error)
+	CancelInstallments(ctx context.Context, tx repo.DBTx, contractNumber string) error
+}
+
+type PaymentRepository interface {
+	CountPayments(ctx context.Context, tx repo.DBTx, contractNumber string) (int, error)
+}
+
+type LedgerRepository interface {
+	PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error
+}
+
+type OutboxRepository interface {
+	CreateEvent(ctx context.Context, tx repo.DBTx, e *model.OutboxEvent) error
+}
+
+type AuditRepository interface {
+	CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error
+}
+
+type HoldRepository interface {
+	CreateHold(ctx context.Context, tx repo.DBTx, h *model.LimitHold) error
+	GetHold(ctx context.Context, id int64) (*model.LimitHold, error)
+	GetHoldForUpdate(ctx context.Context, tx repo.DBTx, id int64) (*model.LimitHold, error)
+	ResolveHold(ctx context.Context, tx repo.DBTx, id int64, status string, contractNumber *string, at time.Time) error
+	ListExpiredHoldIDs(ctx context.Context, now time.Time, afterID int64, limit int) ([]int64, error)
+}
+
+type IdempotencyRepository interface {
+	GetIdempotencyKey(ctx context.Context, channel, key string) (*model.IdempotencyKey, error)
+	CreateIdempotencyKey(ctx context.Context, tx repo.DBTx, key *model.IdempotencyKey) error
 }
 
 type TransactionUsecase interface {
+	// CreateTransaction books a contract. When req.IdempotencyKey is set, a retry with
+	// the same body returns the contract created by the first request.
 	CreateTransaction(ctx context.Context, req *dto.CreateTransactionRequest) (*model.Transaction, error)
+	GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error)
+	ListCustomerTransactions(ctx context.Context, nik string, req *dto.ListTransactionsRequest) (*dto.ListTransactionsResponse, error)
+	GetSchedule(ctx context.Context, contractNumber string) (*dto.ScheduleResponse, error)
+	// CancelTransaction voids a contract within the cooling-off window when no payment
+	// has been posted, and returns OTR plus admin fee to the tenor limit.
+	CancelTransaction(ctx context.Context, contractNumber string, req *dto.CancelTransactionRequest) (*model.Transaction, error)
+
+	// CreateHold reserves limit for a checkout in progress. The amount is deducted
+	// right away and stays reserved until the hold is confirmed, released or expires.
+	CreateHold(ctx context.Context, req *dto.CreateHoldRequest) (*model.LimitHold, error)
+	GetHold(ctx context.Context, id int64) (*model.LimitHold, error)
+	// ConfirmHold books an active hold as a contract and returns the part of the
+	// held amount the contract does not use to the limit.
+	ConfirmHold(ctx context.Context, id int64, req *dto.ConfirmHoldRequest) (*model.Transaction, error)
+	// ReleaseHold returns the held amount of an active hold to the limit.
+	ReleaseHold(ctx context.Context, id int64) (*model.LimitHold, error)
+	// ExpireHolds releases every active hold that expired at or before now and
+	// returns the number of holds released.
+	ExpireHolds(ctx context.Context, now time.Time) (int, error)
 }
 
 type transactionUsecase struct {
-	db           *sqlx.DB
-	customerRepo CustomerRepository
-	limitRepo    LimitRepository
-	txRepo       TransactionRepository
+	db              *sqlx.DB
+	customerRepo    CustomerRepository
+	limitRepo       LimitRepository
+	txRepo          TransactionRepository
+	installmentRepo InstallmentRepository
+	pricing         service.PricingService
+	idempotencyRepo IdempotencyRepository
+	contractNumbers service.ContractNumberGenerator
+	paymentRepo     PaymentRepository
+	ledgerRepo      LedgerRepository
+	outboxRepo      OutboxRepository
+	auditRepo       AuditRepository
+	holdRepo        HoldRepository
+	coolingOff      time.Duration
+	holdTTL         time.Duration
 }
 
-func NewTransactionUsecase(db *sqlx.DB, customerRepo CustomerRepository, limitRepo LimitRepository, txRepo TransactionRepository) TransactionUsecase {
+func NewTransactionUsecase(db *sqlx.DB, customerRepo CustomerRepository, limitRepo LimitRepository, txRepo TransactionRepository, installmentRepo InstallmentRepository, pricing service.PricingService, idempotencyRepo IdempotencyRepository, contractNumbers service.ContractNumberGenerator, paymentRepo PaymentRepository, ledgerRepo LedgerRepository, outboxRepo OutboxRepository, auditRepo AuditRepository, holdRepo HoldRepository, coolingOff, holdTTL time.Duration) TransactionUsecase {
 	return &transactionUsecase{
-		db:           db,
-		customerRepo: customerRepo,
-		limitRepo:    limitRepo,
-		txRepo:       txRepo,
+		db:              db,
+		customerRepo:    customerRepo,
+		limitRepo:       limitRepo,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		pricing:         pricing,
+		idempotencyRepo: idempotencyRepo,
+		contractNumbers: contractNumbers,
+		paymentRepo:     paymentRepo,
+		ledgerRepo:      ledgerRepo,
+		outboxRepo:      outboxRepo,
+		auditRepo:       auditRepo,
+		holdRepo:        holdRepo,
+		coolingOff:      coolingOff,
+		holdTTL:         holdTTL,
 	}
 }
 
 func (u *transactionUsecase) CreateTransaction(ctx context.Context, req *dto.CreateTransactionRequest) (*model.Transaction, error) {
+	if p := auth.FromContext(ctx); p != nil && p.IsPartner() {
+		if req.Channel != "" && req.Channel != p.Partner {
+			return nil, ErrChannelForbidden
+		}
+		req.Channel = p.Partner
+	}
+
+	var requestHash string
+	if req.IdempotencyKey != "" {
+		var err error
+		if requestHash, err = hashRequest(req); err != nil {
+			return nil, fmt.Errorf("gagal membuat hash request: %w", err)
+		}
+		replayed, err := u.replay(ctx, req.Channel, req.IdempotencyKey, requestHash)
+		if err != nil || replayed != nil {
+			return replayed, err
+		}
+	}
+
+	now := time.Now()
+
+	// Interest and installment are always computed here; client values are only
+	// checked against the quote so a partner cannot under-price a contract.
+	quote, err := u.pricing.Quote(req.OTR, req.AdminFee, req.Tenor, now)
+	if errors.Is(err, service.ErrTenorNotPriced) {
+		return nil, ErrTenorNotOffered
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung harga: %w", err)
+	}
+	if err := u.pricing.Reconcile(quote, req.Installment, req.Interest); err != nil {
+		return nil, err
+	}
+
 	dbTx, err := u.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
@@ -76,40 +220,103 @@ func (u *transactionUsecase) CreateTransaction(ctx context.Context, req *dto.Cre
 		dbTx.Rollback()
 		return nil, ErrCustomerNotFound
 	}
+	if err := service.ValidateCustomerNIK(customer); err != nil {
+		dbTx.Rollback()
+		return nil, err
+	}
 
-	limit, err := u.limitRepo.GetLimit(ctx, req.CustomerNIK, req.Tenor)
+	// The deduction is a single conditional UPDATE, so concurrent purchases
+	// against the same tenor are serialized by the row lock and cannot overspend.
+	totalAmount := req.OTR + req.AdminFee
+	deducted, err := u.limitRepo.DeductLimit(ctx, dbTx, req.CustomerNIK, req.Tenor, totalAmount)
+	if err != nil {
+		dbTx.Rollback()
+		return nil, fmt.Errorf("gagal memperbarui limit: %w", err)
+	}
+	if !deducted {
+		dbTx.Rollback()
+		return nil, ErrLimitExceeded
+	}
+	limit, err := u.limitRepo.GetLimitForUpdate(ctx, dbTx, req.CustomerNIK, req.Tenor)
 	if err != nil {
 		dbTx.Rollback()
 		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
 	}
 
-	totalAmount := req.OTR + req.AdminFee
+	transaction := &model.Transaction{
+		CustomerNIK: req.CustomerNIK,
+		OTR:         req.OTR,
+		AdminFee:    req.AdminFee,
+		Installment: quote.Installment,
+		Interest:    quote.Interest,
+		AssetName:   req.AssetName,
+		Tenor:       req.Tenor,
+		Channel:     req.Channel,
+		Status:      model.TransactionStatusActive,
+		CreatedAt:   now,
+	}
 
-	if limit.LimitAmount < totalAmount {
+	if err := u.bookContract(ctx, dbTx, transaction, quote.Schedule, now); err != nil {
 		dbTx.Rollback()
-		return nil, ErrLimitExceeded
+		return nil, err
 	}
 
-	transaction := &model.Transaction{
-		ContractNumber: fmt.Sprintf("CON-%d", time.Now().UnixNano()),
-		CustomerNIK:    req.CustomerNIK,
-		OTR:            req.OTR,
-		AdminFee:       req.AdminFee,
-		Installment:    req.Installment,
-		Interest:       req.Interest,
-		AssetName:      req.AssetName,
-		CreatedAt:      time.Now(),
+	err = u.limitRepo.CreateLimitMovements(ctx, dbTx, []model.LimitMovement{{
+		CustomerNIK:   req.CustomerNIK,
+		Tenor:         req.Tenor,
+		Delta:         -totalAmount,
+		Balance:       limit.LimitAmount,
+		ReferenceType: model.LimitMovementContract,
+		ReferenceID:   transaction.ContractNumber,
+		CreatedAt:     now,
+	}})
+	if err != nil {
+		dbTx.Rollback()
+		return nil, fmt.Errorf("gagal mencatat mutasi limit: %w", err)
 	}
 
-	if err := u.txRepo.CreateTransaction(ctx, dbTx, transaction); err != nil {
+	reason := "contract " + transaction.ContractNumber
+	err = u.audit(ctx, dbTx, now,
+		limitAudit(req.CustomerNIK, req.Tenor, limit.LimitAmount+totalAmount, limit.LimitAmount, reason),
+		contractAudit(nil, transaction, "contract booked"))
+	if err != nil {
 		dbTx.Rollback()
-		return nil, fmt.Errorf("gagal membuat transaksi: %w", err)
+		return nil, err
 	}
 
-	newLimitAmount := limit.LimitAmount - totalAmount
-	if err := u.limitRepo.UpdateLimit(ctx, dbTx, req.CustomerNIK, req.Tenor, newLimitAmount); err != nil {
+	if err := u.publish(ctx, dbTx, model.EventContractCreated, transaction, now); err != nil {
 		dbTx.Rollback()
-		return nil, fmt.Errorf("gagal memperbarui limit: %w", err)
+		return nil, err
+	}
+
+	// The key is stored with the contract so a crash can never leave one without the other.
+	if req.IdempotencyKey != "" {
+		response, err := json.Marshal(transaction)
+		if err != nil {
+			dbTx.Rollback()
+			return nil, fmt.Errorf("gagal menyimpan idempotency key: %w", err)
+		}
+		err = u.idempotencyRepo.CreateIdempotencyKey(ctx, dbTx, &model.IdempotencyKey{
+			Channel:        req.Channel,
+			Key:            req.IdempotencyKey,
+			RequestHash:    requestHash,
+			ContractNumber: transaction.ContractNumber,
+			Response:       response,
+			CreatedAt:      now,
+		})
+		if errors.Is(err, repo.ErrDuplicateKey) {
+			// A concurrent request with the same key committed first; answer with its contract.
+			dbTx.Rollback()
+			replayed, err := u.replay(ctx, req.Channel, req.IdempotencyKey, requestHash)
+			if err == nil && replayed == nil {
+				err = fmt.Errorf("gagal menyimpan idempotency key: %w", repo.ErrDuplicateKey)
+			}
+			return replayed, err
+		}
+		if err != nil {
+			dbTx.Rollback()
+			return nil, fmt.Errorf("gagal menyimpan idempotency key: %w", err)
+		}
 	}
 
 	if err := dbTx.Commit(); err != nil {
@@ -119,3 +326,258 @@ func (u *transactionUsecase) CreateTransaction(ctx context.Context, req *dto.Cre
 
 	return transaction, nil
 }
+
+func (u *transactionUsecase) CancelTransaction(ctx context.Context, contractNumber string, req *dto.CancelTransactionRequest) (*model.Transaction, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	transaction, err := u.txRepo.GetTransactionForUpdate(ctx, dbTx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if transaction == nil || !visibleTo(ctx, transaction) {
+		return nil, ErrTransactionNotFound
+	}
+	if transaction.Status != model.TransactionStatusActive {
+		return nil, ErrContractNotActive
+	}
+
+	now := time.Now()
+	if now.Sub(transaction.CreatedAt) > u.coolingOff {
+		return nil, ErrCancellationWindowClosed
+	}
+
+	payments, err := u.paymentRepo.CountPayments(ctx, dbTx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pembayaran: %w", err)
+	}
+	if payments > 0 {
+		return nil, ErrContractHasPayments
+	}
+
+	limit, err := u.limitRepo.GetLimitForUpdate(ctx, dbTx, transaction.CustomerNIK, transaction.Tenor)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan limit customer: %w", err)
+	}
+	var audits []auditChange
+	if limit != nil {
+		restored := limit.LimitAmount + transaction.OTR + transaction.AdminFee
+		if err := u.limitRepo.UpdateLimit(ctx, dbTx, transaction.CustomerNIK, transaction.Tenor, restored); err != nil {
+			return nil, fmt.Errorf("gagal mengembalikan limit: %w", err)
+		}
+		err = u.limitRepo.CreateLimitMovements(ctx, dbTx, []model.LimitMovement{{
+			CustomerNIK:   transaction.CustomerNIK,
+			Tenor:         transaction.Tenor,
+			Delta:         transaction.OTR + transaction.AdminFee,
+			Balance:       restored,
+			ReferenceType: model.LimitMovementCancellation,
+			ReferenceID:   contractNumber,
+			Note:          req.Reason,
+			CreatedAt:     now,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("gagal mencatat mutasi limit: %w", err)
+		}
+		audits = append(audits, limitAudit(transaction.CustomerNIK, transaction.Tenor, limit.LimitAmount, restored, "cancellation of contract "+contractNumber))
+	}
+
+	if err := u.txRepo.CancelTransaction(ctx, dbTx, contractNumber, req.Reason, now); err != nil {
+		return nil, fmt.Errorf("gagal membatalkan transaksi: %w", err)
+	}
+	if err := u.installmentRepo.CancelInstallments(ctx, dbTx, contractNumber); err != nil {
+		return nil, fmt.Errorf("gagal membatalkan jadwal angsuran: %w", err)
+	}
+	if err := u.ledgerRepo.PostEntries(ctx, dbTx, service.CancellationEntries(transaction, now)); err != nil {
+		return nil, fmt.Errorf("gagal mencatat jurnal pembatalan: %w", err)
+	}
+
+	before := *transaction
+	transaction.Status = model.TransactionStatusCancelled
+	transaction.CancelledAt = &now
+	transaction.CancelReason = &req.Reason
+	audits = append(audits, contractAudit(&before, transaction, req.Reason))
+	if err := u.audit(ctx, dbTx, now, audits...); err != nil {
+		return nil, err
+	}
+	if err := u.publish(ctx, dbTx, model.EventContractCancelled, transaction, now); err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal melakukan commit transaksi: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// bookContract numbers transaction and stores it with its installment schedule
+// and disbursement journal inside tx. The limit must already be deducted.
+func (u *transactionUsecase) bookContract(ctx context.Context, tx repo.DBTx, transaction *model.Transaction, schedule []model.Installment, at time.Time) error {
+	var err error
+	transaction.ContractNumber, err = u.contractNumbers.Next(ctx, tx, transaction)
+	if err != nil {
+		return fmt.Errorf("gagal membuat nomor kontrak: %w", err)
+	}
+
+	if err := u.txRepo.CreateTransaction(ctx, tx, transaction); err != nil {
+		return fmt.Errorf("gagal membuat transaksi: %w", err)
+	}
+
+	for i := range schedule {
+		schedule[i].ContractNumber = transaction.ContractNumber
+	}
+	if err := u.installmentRepo.CreateInstallments(ctx, tx, schedule); err != nil {
+		return fmt.Errorf("gagal membuat jadwal angsuran: %w", err)
+	}
+
+	if err := u.ledgerRepo.PostEntries(ctx, tx, service.DisbursementEntries(transaction, at)); err != nil {
+		return fmt.Errorf("gagal mencatat jurnal pencairan: %w", err)
+	}
+	return nil
+}
+
+// publish records a contract lifecycle event in the outbox inside tx.
+func (u *transactionUsecase) publish(ctx context.Context, tx repo.DBTx, eventType string, transaction *model.Transaction, at time.Time) error {
+	event, err := service.NewContractEvent(eventType, transaction, at)
+	if err == nil {
+		err = u.outboxRepo.CreateEvent(ctx, tx, event)
+	}
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan event %s: %w", eventType, err)
+	}
+	return nil
+}
+
+// auditChange builds one audit entry of a change made in this package.
+type auditChange func(ctx context.Context, at time.Time) (model.AuditLog, error)
+
+func limitAudit(nik string, tenor int, previous, current int64, reason string) auditChange {
+	return func(ctx context.Context, at time.Time) (model.AuditLog, error) {
+		return service.NewLimitAudit(ctx, nik, tenor, &previous, current, reason, at)
+	}
+}
+
+func contractAudit(before, after *model.Transaction, reason string) auditChange {
+	return func(ctx context.Context, at time.Time) (model.AuditLog, error) {
+		return service.NewContractAudit(ctx, before, after, reason, at)
+	}
+}
+
+// audit records the given changes in the audit log inside tx.
+func (u *transactionUsecase) audit(ctx context.Context, tx repo.DBTx, at time.Time, changes ...auditChange) error {
+	entries := make([]model.AuditLog, 0, len(changes))
+	for _, change := range changes {
+		entry, err := change(ctx, at)
+		if err != nil {
+			return fmt.Errorf("gagal membuat audit: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := u.auditRepo.CreateEntries(ctx, tx, entries); err != nil {
+		return fmt.Errorf("gagal mencatat audit: %w", err)
+	}
+	return nil
+}
+
+func (u *transactionUsecase) GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error) {
+	transaction, err := u.txRepo.GetTransaction(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan transaksi: %w", err)
+	}
+	if transaction == nil || !visibleTo(ctx, transaction) {
+		return nil, ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+// visibleTo reports whether the caller may see the contract. Partners only see
+// contracts booked through their own channel; to them any other contract does
+// not exist.
+func visibleTo(ctx context.Context, t *model.Transaction) bool {
+	p := auth.FromContext(ctx)
+	return p == nil || !p.IsPartner() || t.Channel == p.Partner
+}
+
+func (u *transactionUsecase) ListCustomerTransactions(ctx context.Context, nik string, req *dto.ListTransactionsRequest) (*dto.ListTransactionsResponse, error) {
+	customer, err := u.customerRepo.GetCustomer(ctx, nik)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan data customer: %w", err)
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	filter := model.TransactionFilter{
+		CustomerNIK: nik,
+		Tenor:       req.Tenor,
+		AssetName:   req.AssetName,
+		// Fetch one extra row to know whether another page exists.
+		Limit: limit + 1,
+	}
+	if req.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("tanggal from tidak valid: %w", err)
+		}
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("tanggal to tidak valid: %w", err)
+		}
+		// To is inclusive, so the bound is the start of the next day.
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if req.Cursor != "" {
+		createdAt, contractNumber, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		filter.AfterCreatedAt = createdAt
+		filter.AfterContractNumber = contractNumber
+	}
+
+	transactions, err := u.txRepo.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan daftar transaksi: %w", err)
+	}
+
+	resp := &dto.ListTransactionsResponse{Items: []dto.TransactionResponse{}}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		last := transactions[limit-1]
+		resp.NextCursor = encodeCursor(last.CreatedAt, last.ContractNumber)
+	}
+	for i := range transactions {
+		resp.Items = append(resp.Items, dto.NewTransactionResponse(&transactions[i]))
+	}
+
+	return resp, nil
+}
+
+func (u *transactionUsecase) GetSchedule(ctx context.Context, contractNumber string) (*dto.ScheduleResponse, error) {
+	transaction, err := u.GetTransaction(ctx, contractNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := u.installmentRepo.ListInstallments(ctx, contractNumber)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan jadwal angsuran: %w", err)
+	}
+
+	return dto.NewScheduleResponse(transaction, installments), nil
+}
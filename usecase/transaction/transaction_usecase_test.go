@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"multifinance/auth"
 	"multifinance/delivery/dto"
 	"multifinance/model"
 	repo "multifinance/repository"
+	"multifinance/service"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
@@ -29,8 +33,8 @@ func (m *mockCustomerRepository) GetCustomer(ctx context.Context, nik string) (*
 	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
-func (m *mockCustomerRepository) CreateCustomer(ctx context.Context, customer *model.Customer) error {
-	args := m.Called(ctx, customer)
+func (m *mockCustomerRepository) CreateCustomer(ctx context.Context, tx repo.DBTx, customer *model.Customer) error {
+	args := m.Called(ctx, tx, customer)
 	return args.Error(0)
 }
 
@@ -38,8 +42,13 @@ type mockLimitRepository struct {
 	mock.Mock
 }
 
-func (m *mockLimitRepository) GetLimit(ctx context.Context, nik string, tenor int) (*model.CustomerLimit, error) {
-	args := m.Called(ctx, nik, tenor)
+func (m *mockLimitRepository) DeductLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) (bool, error) {
+	args := m.Called(ctx, tx, nik, tenor, amount)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockLimitRepository) GetLimitForUpdate(ctx context.Context, tx repo.DBTx, nik string, tenor int) (*model.CustomerLimit, error) {
+	args := m.Called(ctx, tx, nik, tenor)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -51,6 +60,11 @@ func (m *mockLimitRepository) UpdateLimit(ctx context.Context, tx repo.DBTx, nik
 	return args.Error(0)
 }
 
+func (m *mockLimitRepository) CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error {
+	args := m.Called(ctx, tx, movements)
+	return args.Error(0)
+}
+
 type mockTransactionRepository struct {
 	mock.Mock
 }
@@ -60,6 +74,160 @@ func (m *mockTransactionRepository) CreateTransaction(ctx context.Context, tx re
 	return args.Error(0)
 }
 
+func (m *mockTransactionRepository) GetTransaction(ctx context.Context, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionRepository) GetTransactionForUpdate(ctx context.Context, tx repo.DBTx, contractNumber string) (*model.Transaction, error) {
+	args := m.Called(ctx, tx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionRepository) CancelTransaction(ctx context.Context, tx repo.DBTx, contractNumber, reason string, at time.Time) error {
+	args := m.Called(ctx, tx, contractNumber, reason, at)
+	return args.Error(0)
+}
+
+func (m *mockTransactionRepository) ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Transaction), args.Error(1)
+}
+
+type mockInstallmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockInstallmentRepository) CreateInstallments(ctx context.Context, tx repo.DBTx, installments []model.Installment) error {
+	args := m.Called(ctx, tx, installments)
+	return args.Error(0)
+}
+
+func (m *mockInstallmentRepository) ListInstallments(ctx context.Context, contractNumber string) ([]model.Installment, error) {
+	args := m.Called(ctx, contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Installment), args.Error(1)
+}
+
+func (m *mockInstallmentRepository) CancelInstallments(ctx context.Context, tx repo.DBTx, contractNumber string) error {
+	args := m.Called(ctx, tx, contractNumber)
+	return args.Error(0)
+}
+
+type mockPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *mockPaymentRepository) CountPayments(ctx context.Context, tx repo.DBTx, contractNumber string) (int, error) {
+	args := m.Called(ctx, tx, contractNumber)
+	return args.Int(0), args.Error(1)
+}
+
+type mockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *mockIdempotencyRepository) GetIdempotencyKey(ctx context.Context, channel, key string) (*model.IdempotencyKey, error) {
+	args := m.Called(ctx, channel, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyKey), args.Error(1)
+}
+
+func (m *mockIdempotencyRepository) CreateIdempotencyKey(ctx context.Context, tx repo.DBTx, key *model.IdempotencyKey) error {
+	args := m.Called(ctx, tx, key)
+	return args.Error(0)
+}
+
+// sequentialContractNumbers hands out CON-TEST-1, CON-TEST-2, ... so tests get
+// deterministic contract numbers.
+type sequentialContractNumbers struct {
+	next int64
+}
+
+func (g *sequentialContractNumbers) Next(ctx context.Context, tx repo.DBTx, transaction *model.Transaction) (string, error) {
+	return fmt.Sprintf("CON-TEST-%d", atomic.AddInt64(&g.next, 1)), nil
+}
+
+// recordingLedger keeps the posted journal entries and, like the real repository,
+// rejects unbalanced ones.
+type recordingLedger struct {
+	mu      sync.Mutex
+	entries []model.JournalEntry
+}
+
+func (l *recordingLedger) PostEntries(ctx context.Context, tx repo.DBTx, entries []model.JournalEntry) error {
+	for _, e := range entries {
+		if !e.Balanced() {
+			return repo.ErrUnbalancedEntry
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entries...)
+	return nil
+}
+
+// recordingOutbox keeps the events written to the outbox.
+type recordingOutbox struct {
+	mu     sync.Mutex
+	events []model.OutboxEvent
+}
+
+func (o *recordingOutbox) CreateEvent(ctx context.Context, tx repo.DBTx, e *model.OutboxEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, *e)
+	return nil
+}
+
+// recordingAudit keeps the audit entries written.
+type recordingAudit struct {
+	mu      sync.Mutex
+	entries []model.AuditLog
+}
+
+func (a *recordingAudit) CreateEntries(ctx context.Context, tx repo.DBTx, entries []model.AuditLog) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entries...)
+	return nil
+}
+
+// stubPricingService returns a fixed quote so the tests control installment and interest.
+type stubPricingService struct{}
+
+func (s *stubPricingService) Quote(otr, adminFee int64, tenor int, start time.Time) (*service.Quote, error) {
+	if tenor == 99 {
+		return nil, service.ErrTenorNotPriced
+	}
+	return &service.Quote{
+		Principal:   otr + adminFee,
+		Interest:    100000,
+		Installment: 1000000,
+		Schedule:    service.BuildFlatSchedule("", otr+adminFee, 100000, tenor, start),
+	}, nil
+}
+
+func (s *stubPricingService) Reconcile(quote *service.Quote, installment, interest int64) error {
+	if installment != 0 && installment != quote.Installment {
+		return dto.NewValidationError([]dto.ValidationError{{Field: "installment", Message: "mismatch"}})
+	}
+	return nil
+}
+
 func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 	tests := []struct {
 		namaTest         string
@@ -81,28 +249,34 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
 				sqlMock.ExpectBegin()
 
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
-					Return(&model.Customer{NIK: "1234567890123456", FullName: "John Doe"}, nil)
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
+					Return(&model.Customer{NIK: "3171010101900001", FullName: "John Doe"}, nil)
 
-				limitRepo.On("GetLimit", mock.Anything, "1234567890123456", 6).
-					Return(&model.CustomerLimit{CustomerNIK: "1234567890123456", Tenor: 6, LimitAmount: 10000000}, nil).Once()
+				limitRepo.On("DeductLimit", mock.Anything, mock.Anything, "3171010101900001", 6, int64(1050000)).
+					Return(true, nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 6).
+					Return(&model.CustomerLimit{LimitAmount: 3950000}, nil).Once()
 
 				txRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.MatchedBy(func(tx *model.Transaction) bool {
-					return tx.CustomerNIK == "1234567890123456" && 
+					return tx.CustomerNIK == "3171010101900001" && 
 						tx.OTR == 1000000 && 
 						tx.AdminFee == 50000 &&
 						tx.Installment == 1000000 &&
 						tx.Interest == 100000 &&
-						tx.AssetName == "Laptop"
+						tx.AssetName == "Laptop" &&
+						tx.Tenor == 6
+				})).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.MatchedBy(func(m []model.LimitMovement) bool {
+					return len(m) == 1 &&
+						m[0].Delta == -1050000 &&
+						m[0].Balance == 3950000 &&
+						m[0].ReferenceType == model.LimitMovementContract
 				})).Return(nil).Once()
-
-				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "1234567890123456", 6, int64(8950000)).
-					Return(nil).Once()
 
 				sqlMock.ExpectCommit()
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -121,7 +295,7 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 				sqlMock.ExpectBegin().WillReturnError(errors.New("database error"))
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -139,12 +313,12 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 			namaTest: "gagal mendapatkan data customer",
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
 				sqlMock.ExpectBegin()
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
 					Return(nil, errors.New("database error")).Once()
 				sqlMock.ExpectRollback().WillReturnError(nil)
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -159,20 +333,20 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 		},
 
 		{
-			namaTest: "gagal mendapatkan limit",
+			namaTest: "gagal memotong limit",
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
 				sqlMock.ExpectBegin()
 
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
-					Return(&model.Customer{NIK: "1234567890123456", FullName: "John Doe"}, nil)
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
+					Return(&model.Customer{NIK: "3171010101900001", FullName: "John Doe"}, nil)
 
-				limitRepo.On("GetLimit", mock.Anything, "1234567890123456", 6).
-					Return(nil, errors.New("database error")).Once()
+				limitRepo.On("DeductLimit", mock.Anything, mock.Anything, "3171010101900001", 6, int64(1050000)).
+					Return(false, errors.New("database error")).Once()
 
 				sqlMock.ExpectRollback().WillReturnError(nil)
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -181,7 +355,7 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 				Tenor:       6,
 			},
 			harusError: true,
-			erorDiharapkan: fmt.Errorf("gagal mendapatkan limit customer: database error"),
+			erorDiharapkan: fmt.Errorf("gagal memperbarui limit: database error"),
 			harusUpdateLimit: false,
 			shouldPanic: false,
 		},
@@ -191,11 +365,13 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
 				sqlMock.ExpectBegin()
 
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
-					Return(&model.Customer{NIK: "1234567890123456", FullName: "John Doe"}, nil)
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
+					Return(&model.Customer{NIK: "3171010101900001", FullName: "John Doe"}, nil)
 
-				limitRepo.On("GetLimit", mock.Anything, "1234567890123456", 6).
-					Return(&model.CustomerLimit{CustomerNIK: "1234567890123456", Tenor: 6, LimitAmount: 10000000}, nil).Once()
+				limitRepo.On("DeductLimit", mock.Anything, mock.Anything, "3171010101900001", 6, int64(1050000)).
+					Return(true, nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 6).
+					Return(&model.CustomerLimit{LimitAmount: 3950000}, nil).Once()
 
 				txRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).
 					Return(errors.New("database error")).Once()
@@ -203,7 +379,7 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 				sqlMock.ExpectRollback().WillReturnError(nil)
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -218,25 +394,26 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 		},
 
 		{
-			namaTest: "gagal update limit",
+			namaTest: "gagal commit transaksi",
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
 				sqlMock.ExpectBegin()
 
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
-					Return(&model.Customer{NIK: "1234567890123456", FullName: "John Doe"}, nil)
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
+					Return(&model.Customer{NIK: "3171010101900001", FullName: "John Doe"}, nil)
 
-				limitRepo.On("GetLimit", mock.Anything, "1234567890123456", 6).
-					Return(&model.CustomerLimit{CustomerNIK: "1234567890123456", Tenor: 6, LimitAmount: 10000000}, nil).Once()
+				limitRepo.On("DeductLimit", mock.Anything, mock.Anything, "3171010101900001", 6, int64(1050000)).
+					Return(true, nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 6).
+					Return(&model.CustomerLimit{LimitAmount: 3950000}, nil).Once()
 
 				txRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 
-				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "1234567890123456", 6, int64(8950000)).
-					Return(errors.New("database error")).Once()
-
+				sqlMock.ExpectCommit().WillReturnError(errors.New("commit failed"))
 				sqlMock.ExpectRollback().WillReturnError(nil)
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -245,77 +422,93 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 				Tenor:       6,
 			},
 			harusError: true,
-			erorDiharapkan: fmt.Errorf("gagal memperbarui limit: database error"),
+			erorDiharapkan: fmt.Errorf("gagal melakukan commit transaksi: commit failed"),
 			harusUpdateLimit: true,
 			shouldPanic: false,
 		},
 
 		{
-			namaTest: "gagal commit transaksi",
+			namaTest: "customer tidak ditemukan",
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
+				customerRepo.On("GetCustomer", mock.Anything, "9999999999999999").
+					Return(nil, errors.New("customer not found"))
 				sqlMock.ExpectBegin()
-
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
-					Return(&model.Customer{NIK: "1234567890123456", FullName: "John Doe"}, nil)
-
-				limitRepo.On("GetLimit", mock.Anything, "1234567890123456", 6).
-					Return(&model.CustomerLimit{CustomerNIK: "1234567890123456", Tenor: 6, LimitAmount: 10000000}, nil).Once()
-
-				txRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
-
-				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "1234567890123456", 6, int64(8950000)).Return(nil).Once()
-
-				sqlMock.ExpectCommit().WillReturnError(errors.New("commit failed"))
 				sqlMock.ExpectRollback().WillReturnError(nil)
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
-				OTR:         1000000,
-				AdminFee:    50000,
+				CustomerNIK: "9999999999999999",
+				OTR:         9000000,
+				AdminFee:    1000000,
 				Installment: 1000000,
 				Interest:    100000,
 				AssetName:   "Laptop",
 				Tenor:       6,
 			},
 			harusError: true,
-			erorDiharapkan: fmt.Errorf("gagal melakukan commit transaksi: commit failed"),
-			harusUpdateLimit: true,
+			erorDiharapkan: errors.New("customer not found"),
 			shouldPanic: false,
 		},
-
 		{
-			namaTest: "customer tidak ditemukan",
+			namaTest: "NIK customer tidak sesuai tanggal lahir tersimpan",
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
-				customerRepo.On("GetCustomer", mock.Anything, "9999999999999999").
-					Return(nil, errors.New("customer not found"))
 				sqlMock.ExpectBegin()
-				sqlMock.ExpectRollback().WillReturnError(nil)
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
+					Return(&model.Customer{NIK: "3171010101900001", FullName: "John Doe", BirthDate: "1990-01-02"}, nil)
+				sqlMock.ExpectRollback()
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "9999999999999999",
-				OTR:         9000000,
-				AdminFee:    1000000,
+				CustomerNIK: "3171010101900001",
+				OTR:         1000000,
+				AdminFee:    50000,
 				Installment: 1000000,
 				Interest:    100000,
 				AssetName:   "Laptop",
 				Tenor:       6,
 			},
-			harusError: true,
-			erorDiharapkan: errors.New("customer not found"),
-			shouldPanic: false,
+			harusError:     true,
+			erorDiharapkan: errors.New("validation failed"),
+		},
+		{
+			namaTest: "tenor tidak ditawarkan",
+			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
+			},
+			req: &dto.CreateTransactionRequest{
+				CustomerNIK: "3171010101900001",
+				OTR:         1000000,
+				AdminFee:    50000,
+				AssetName:   "Laptop",
+				Tenor:       99,
+			},
+			harusError:     true,
+			erorDiharapkan: ErrTenorNotOffered,
+		},
+		{
+			namaTest: "angsuran dari client tidak sesuai harga server",
+			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
+			},
+			req: &dto.CreateTransactionRequest{
+				CustomerNIK: "3171010101900001",
+				OTR:         1000000,
+				AdminFee:    50000,
+				Installment: 1,
+				AssetName:   "Laptop",
+				Tenor:       6,
+			},
+			harusError:     true,
+			erorDiharapkan: errors.New("validation failed"),
 		},
 		{
 			namaTest: "limit tidak mencukupi",
 			setupMocks: func(customerRepo *mockCustomerRepository, limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, sqlMock sqlmock.Sqlmock) {
 				sqlMock.ExpectBegin()
-				customerRepo.On("GetCustomer", mock.Anything, "1234567890123456").
-					Return(&model.Customer{NIK: "1234567890123456", FullName: "John Doe"}, nil)
-				limitRepo.On("GetLimit", mock.Anything, "1234567890123456", 6).
-					Return(&model.CustomerLimit{CustomerNIK: "1234567890123456", Tenor: 6, LimitAmount: 100000}, nil).Once()
+				customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").
+					Return(&model.Customer{NIK: "3171010101900001", FullName: "John Doe"}, nil)
+				limitRepo.On("DeductLimit", mock.Anything, mock.Anything, "3171010101900001", 6, int64(1050000)).
+					Return(false, nil).Once()
 				sqlMock.ExpectRollback().WillReturnError(nil)
 			},
 			req: &dto.CreateTransactionRequest{
-				CustomerNIK: "1234567890123456",
+				CustomerNIK: "3171010101900001",
 				OTR:         1000000,
 				AdminFee:    50000,
 				Installment: 1000000,
@@ -349,7 +542,12 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 			tt.setupMocks(customerRepo, limitRepo, txRepo, sqlMock)
 
 			// Create usecase with mocked dependencies
-			uc := NewTransactionUsecase(sqlxDB, customerRepo, limitRepo, txRepo)
+			installmentRepo := &mockInstallmentRepository{}
+			installmentRepo.On("CreateInstallments", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			ledger := &recordingLedger{}
+			outbox := &recordingOutbox{}
+			uc := NewTransactionUsecase(sqlxDB, customerRepo, limitRepo, txRepo, installmentRepo, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, ledger, outbox, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
 
 			// Skip panic test as it's covered by other test cases
 
@@ -364,6 +562,10 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 				}
 			} else {
 				assert.NoError(t, err)
+				assert.NotEmpty(t, ledger.entries)
+				assert.Equal(t, model.JournalEntryDisbursement, ledger.entries[0].EntryType)
+				assert.Len(t, outbox.events, 1)
+				assert.Equal(t, model.EventContractCreated, outbox.events[0].EventType)
 			}
 
 			// Verify all expectations were met
@@ -379,6 +581,673 @@ func TestTransactionUsecase_CreateTransaction(t *testing.T) {
 	}
 }
 
+func TestTransactionUsecase_CreateTransaction_IdempotencyKey(t *testing.T) {
+	newReq := func() *dto.CreateTransactionRequest {
+		return &dto.CreateTransactionRequest{
+			CustomerNIK:    "3171010101900001",
+			OTR:            1000000,
+			AdminFee:       50000,
+			AssetName:      "Laptop",
+			Tenor:          6,
+			IdempotencyKey: "retry-1",
+		}
+	}
+	hash, err := hashRequest(newReq())
+	assert.NoError(t, err)
+
+	t.Run("kunci baru disimpan dalam transaksi yang sama", func(t *testing.T) {
+		db, sqlMock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		customerRepo := &mockCustomerRepository{}
+		limitRepo := &mockLimitRepository{}
+		txRepo := &mockTransactionRepository{}
+		installmentRepo := &mockInstallmentRepository{}
+		idempotencyRepo := &mockIdempotencyRepository{}
+
+		idempotencyRepo.On("GetIdempotencyKey", mock.Anything, "", "retry-1").Return(nil, nil).Once()
+		sqlMock.ExpectBegin()
+		customerRepo.On("GetCustomer", mock.Anything, "3171010101900001").Return(&model.Customer{NIK: "3171010101900001"}, nil)
+		limitRepo.On("DeductLimit", mock.Anything, mock.Anything, "3171010101900001", 6, int64(1050000)).Return(true, nil)
+		limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 6).Return(&model.CustomerLimit{LimitAmount: 3950000}, nil)
+		txRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		installmentRepo.On("CreateInstallments", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		idempotencyRepo.On("CreateIdempotencyKey", mock.Anything, mock.Anything, mock.MatchedBy(func(k *model.IdempotencyKey) bool {
+			return k.Key == "retry-1" && k.RequestHash == hash && k.ContractNumber == "CON-TEST-1" && len(k.Response) > 0
+		})).Return(nil).Once()
+		sqlMock.ExpectCommit()
+
+		uc := NewTransactionUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, limitRepo, txRepo, installmentRepo, &stubPricingService{}, idempotencyRepo, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+		_, err = uc.CreateTransaction(context.Background(), newReq())
+
+		assert.NoError(t, err)
+		idempotencyRepo.AssertExpectations(t)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("retry mengembalikan kontrak yang sama tanpa memotong limit", func(t *testing.T) {
+		idempotencyRepo := &mockIdempotencyRepository{}
+		idempotencyRepo.On("GetIdempotencyKey", mock.Anything, "", "retry-1").Return(&model.IdempotencyKey{
+			Key:            "retry-1",
+			RequestHash:    hash,
+			ContractNumber: "CON-1",
+			Response:       []byte(`{"ContractNumber":"CON-1","CustomerNIK":"3171010101900001"}`),
+		}, nil).Once()
+
+		uc := NewTransactionUsecase(nil, &mockCustomerRepository{}, &mockLimitRepository{}, &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, idempotencyRepo, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+		hasil, err := uc.CreateTransaction(context.Background(), newReq())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "CON-1", hasil.ContractNumber)
+	})
+
+	t.Run("kunci sama dengan body berbeda ditolak", func(t *testing.T) {
+		idempotencyRepo := &mockIdempotencyRepository{}
+		idempotencyRepo.On("GetIdempotencyKey", mock.Anything, "", "retry-1").Return(&model.IdempotencyKey{
+			Key:         "retry-1",
+			RequestHash: hash,
+		}, nil).Once()
+
+		req := newReq()
+		req.OTR = 2000000
+		uc := NewTransactionUsecase(nil, &mockCustomerRepository{}, &mockLimitRepository{}, &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, idempotencyRepo, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+		_, err := uc.CreateTransaction(context.Background(), req)
+
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	})
+
+	t.Run("kunci partner dicari dalam channel partner itu", func(t *testing.T) {
+		partnerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Type: auth.PrincipalPartner, ID: "1", Partner: "tokopaedi"})
+		req := newReq()
+		req.Channel = "tokopaedi"
+		partnerHash, err := hashRequest(req)
+		assert.NoError(t, err)
+
+		idempotencyRepo := &mockIdempotencyRepository{}
+		idempotencyRepo.On("GetIdempotencyKey", mock.Anything, "tokopaedi", "retry-1").Return(&model.IdempotencyKey{
+			Channel:        "tokopaedi",
+			Key:            "retry-1",
+			RequestHash:    partnerHash,
+			ContractNumber: "CON-1",
+			Response:       []byte(`{"ContractNumber":"CON-1","CustomerNIK":"3171010101900001","Channel":"tokopaedi"}`),
+		}, nil).Once()
+
+		uc := NewTransactionUsecase(nil, &mockCustomerRepository{}, &mockLimitRepository{}, &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, idempotencyRepo, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+		hasil, err := uc.CreateTransaction(partnerCtx, newReq())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "CON-1", hasil.ContractNumber)
+		idempotencyRepo.AssertExpectations(t)
+	})
+
+	t.Run("respons tersimpan milik partner lain tidak diputar ulang", func(t *testing.T) {
+		partnerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Type: auth.PrincipalPartner, ID: "2", Partner: "bukalapak"})
+		req := newReq()
+		req.Channel = "bukalapak"
+		partnerHash, err := hashRequest(req)
+		assert.NoError(t, err)
+
+		idempotencyRepo := &mockIdempotencyRepository{}
+		idempotencyRepo.On("GetIdempotencyKey", mock.Anything, "bukalapak", "retry-1").Return(&model.IdempotencyKey{
+			Channel:        "bukalapak",
+			Key:            "retry-1",
+			RequestHash:    partnerHash,
+			ContractNumber: "CON-1",
+			Response:       []byte(`{"ContractNumber":"CON-1","CustomerNIK":"3171010101900001","Channel":"tokopaedi"}`),
+		}, nil).Once()
+
+		uc := NewTransactionUsecase(nil, &mockCustomerRepository{}, &mockLimitRepository{}, &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, idempotencyRepo, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+		hasil, err := uc.CreateTransaction(partnerCtx, newReq())
+
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+		assert.Nil(t, hasil)
+	})
+}
+
+func TestTransactionUsecase_PartnerChannel(t *testing.T) {
+	partnerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Type:    auth.PrincipalPartner,
+		ID:      "1",
+		Partner: "tokopaedi",
+		Roles:   []string{auth.RolePartner},
+	})
+
+	t.Run("partner tidak boleh membuat transaksi untuk channel lain", func(t *testing.T) {
+		uc := NewTransactionUsecase(nil, &mockCustomerRepository{}, &mockLimitRepository{}, &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+		_, err := uc.CreateTransaction(partnerCtx, &dto.CreateTransactionRequest{
+			CustomerNIK: "3171010101900001",
+			OTR:         1000000,
+			Tenor:       6,
+			Channel:     "bukalapax",
+		})
+
+		assert.ErrorIs(t, err, ErrChannelForbidden)
+	})
+
+	t.Run("kontrak channel lain tidak terlihat oleh partner", func(t *testing.T) {
+		txRepo := &mockTransactionRepository{}
+		txRepo.On("GetTransaction", mock.Anything, "CON-1").Return(&model.Transaction{ContractNumber: "CON-1", Channel: "bukalapax"}, nil)
+		txRepo.On("GetTransaction", mock.Anything, "CON-2").Return(&model.Transaction{ContractNumber: "CON-2", Channel: "tokopaedi"}, nil)
+
+		uc := NewTransactionUsecase(nil, &mockCustomerRepository{}, &mockLimitRepository{}, txRepo, &mockInstallmentRepository{}, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+
+		_, err := uc.GetTransaction(partnerCtx, "CON-1")
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+		hasil, err := uc.GetTransaction(partnerCtx, "CON-2")
+		assert.NoError(t, err)
+		assert.Equal(t, "CON-2", hasil.ContractNumber)
+
+		hasil, err = uc.GetTransaction(context.Background(), "CON-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "CON-1", hasil.ContractNumber)
+	})
+}
+
+func TestTransactionUsecase_CancelTransaction(t *testing.T) {
+	newContract := func(createdAt time.Time) *model.Transaction {
+		return &model.Transaction{
+			ContractNumber: "CON-1",
+			CustomerNIK:    "3171010101900001",
+			OTR:            1000000,
+			AdminFee:       50000,
+			Tenor:          3,
+			Status:         model.TransactionStatusActive,
+			CreatedAt:      createdAt,
+		}
+	}
+
+	tests := []struct {
+		namaTest       string
+		setupMocks     func(limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock)
+		erorDiharapkan error
+	}{
+		{
+			namaTest: "pembatalan mengembalikan OTR dan admin fee ke limit",
+			setupMocks: func(limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(newContract(time.Now().Add(-time.Hour)), nil).Once()
+				paymentRepo.On("CountPayments", mock.Anything, mock.Anything, "CON-1").Return(0, nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 3).Return(&model.CustomerLimit{LimitAmount: 200000}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 3, int64(1250000)).Return(nil).Once()
+				limitRepo.On("CreateLimitMovements", mock.Anything, mock.Anything, mock.MatchedBy(func(m []model.LimitMovement) bool {
+					return len(m) == 1 &&
+						m[0].Delta == 1050000 &&
+						m[0].Balance == 1250000 &&
+						m[0].ReferenceType == model.LimitMovementCancellation &&
+						m[0].ReferenceID == "CON-1" &&
+						m[0].Note == "salah input aset"
+				})).Return(nil).Once()
+				txRepo.On("CancelTransaction", mock.Anything, mock.Anything, "CON-1", "salah input aset", mock.Anything).Return(nil).Once()
+				installmentRepo.On("CancelInstallments", mock.Anything, mock.Anything, "CON-1").Return(nil).Once()
+				sqlMock.ExpectCommit()
+			},
+		},
+		{
+			namaTest: "lewat masa cooling-off",
+			setupMocks: func(limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(newContract(time.Now().Add(-25*time.Hour)), nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrCancellationWindowClosed,
+		},
+		{
+			namaTest: "sudah ada pembayaran",
+			setupMocks: func(limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(newContract(time.Now()), nil).Once()
+				paymentRepo.On("CountPayments", mock.Anything, mock.Anything, "CON-1").Return(1, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrContractHasPayments,
+		},
+		{
+			namaTest: "kontrak sudah dibatalkan",
+			setupMocks: func(limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				cancelled := newContract(time.Now())
+				cancelled.Status = model.TransactionStatusCancelled
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(cancelled, nil).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: ErrContractNotActive,
+		},
+		{
+			namaTest: "gagal mengembalikan limit",
+			setupMocks: func(limitRepo *mockLimitRepository, txRepo *mockTransactionRepository, installmentRepo *mockInstallmentRepository, paymentRepo *mockPaymentRepository, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectBegin()
+				txRepo.On("GetTransactionForUpdate", mock.Anything, mock.Anything, "CON-1").Return(newContract(time.Now()), nil).Once()
+				paymentRepo.On("CountPayments", mock.Anything, mock.Anything, "CON-1").Return(0, nil).Once()
+				limitRepo.On("GetLimitForUpdate", mock.Anything, mock.Anything, "3171010101900001", 3).Return(&model.CustomerLimit{LimitAmount: 0}, nil).Once()
+				limitRepo.On("UpdateLimit", mock.Anything, mock.Anything, "3171010101900001", 3, int64(1050000)).Return(errors.New("db error")).Once()
+				sqlMock.ExpectRollback()
+			},
+			erorDiharapkan: errors.New("gagal mengembalikan limit: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			limitRepo := &mockLimitRepository{}
+			txRepo := &mockTransactionRepository{}
+			installmentRepo := &mockInstallmentRepository{}
+			paymentRepo := &mockPaymentRepository{}
+			tt.setupMocks(limitRepo, txRepo, installmentRepo, paymentRepo, sqlMock)
+
+			ledger := &recordingLedger{}
+			outbox := &recordingOutbox{}
+			audit := &recordingAudit{}
+			uc := NewTransactionUsecase(sqlx.NewDb(db, "sqlmock"), &mockCustomerRepository{}, limitRepo, txRepo, installmentRepo, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, paymentRepo, ledger, outbox, audit, nil, 24*time.Hour, 15*time.Minute)
+			hasil, err := uc.CancelTransaction(context.Background(), "CON-1", &dto.CancelTransactionRequest{Reason: "salah input aset"})
+
+			if tt.erorDiharapkan != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.erorDiharapkan.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, model.TransactionStatusCancelled, hasil.Status)
+				assert.NotNil(t, hasil.CancelledAt)
+				assert.Len(t, ledger.entries, 1)
+				assert.Equal(t, model.JournalEntryCancellation, ledger.entries[0].EntryType)
+				assert.Len(t, outbox.events, 1)
+				assert.Equal(t, model.EventContractCancelled, outbox.events[0].EventType)
+				assert.Contains(t, string(outbox.events[0].Payload), `"status":"cancelled"`)
+				if assert.Len(t, audit.entries, 2) {
+					assert.Equal(t, model.AuditEntityCustomerLimit, audit.entries[0].EntityType)
+					assert.Equal(t, model.AuditEntityTransaction, audit.entries[1].EntityType)
+					assert.Contains(t, string(audit.entries[1].Before), `"status":"active"`)
+					assert.Contains(t, string(audit.entries[1].After), `"status":"cancelled"`)
+					assert.Equal(t, "salah input aset", audit.entries[1].Reason)
+				}
+			}
+
+			limitRepo.AssertExpectations(t)
+			txRepo.AssertExpectations(t)
+			installmentRepo.AssertExpectations(t)
+			paymentRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+// fakeLimitRepository keeps limits in memory and applies DeductLimit as one
+// atomic check-and-decrement, the way the conditional UPDATE behaves in MySQL.
+type fakeLimitRepository struct {
+	mu        sync.Mutex
+	limits    map[string]int64
+	movements []model.LimitMovement
+}
+
+func (f *fakeLimitRepository) key(nik string, tenor int) string {
+	return fmt.Sprintf("%s/%d", nik, tenor)
+}
+
+func (f *fakeLimitRepository) DeductLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current, ok := f.limits[f.key(nik, tenor)]
+	if !ok || current < amount {
+		return false, nil
+	}
+	f.limits[f.key(nik, tenor)] = current - amount
+	return true, nil
+}
+
+func (f *fakeLimitRepository) GetLimitForUpdate(ctx context.Context, tx repo.DBTx, nik string, tenor int) (*model.CustomerLimit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	amount, ok := f.limits[f.key(nik, tenor)]
+	if !ok {
+		return nil, nil
+	}
+	return &model.CustomerLimit{CustomerNIK: nik, Tenor: tenor, LimitAmount: amount}, nil
+}
+
+func (f *fakeLimitRepository) UpdateLimit(ctx context.Context, tx repo.DBTx, nik string, tenor int, amount int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.limits[f.key(nik, tenor)] = amount
+	return nil
+}
+
+func (f *fakeLimitRepository) CreateLimitMovements(ctx context.Context, tx repo.DBTx, movements []model.LimitMovement) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.movements = append(f.movements, movements...)
+	return nil
+}
+
+// fakeHoldRepository keeps limit holds in memory.
+type fakeHoldRepository struct {
+	mu     sync.Mutex
+	holds  map[int64]model.LimitHold
+	nextID int64
+}
+
+func (f *fakeHoldRepository) CreateHold(ctx context.Context, tx repo.DBTx, h *model.LimitHold) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.holds == nil {
+		f.holds = map[int64]model.LimitHold{}
+	}
+	f.nextID++
+	h.ID = f.nextID
+	f.holds[h.ID] = *h
+	return nil
+}
+
+func (f *fakeHoldRepository) GetHold(ctx context.Context, id int64) (*model.LimitHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h, ok := f.holds[id]
+	if !ok {
+		return nil, nil
+	}
+	return &h, nil
+}
+
+func (f *fakeHoldRepository) GetHoldForUpdate(ctx context.Context, tx repo.DBTx, id int64) (*model.LimitHold, error) {
+	return f.GetHold(ctx, id)
+}
+
+func (f *fakeHoldRepository) ResolveHold(ctx context.Context, tx repo.DBTx, id int64, status string, contractNumber *string, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h := f.holds[id]
+	h.Status, h.ContractNumber, h.ResolvedAt = status, contractNumber, &at
+	f.holds[id] = h
+	return nil
+}
+
+func (f *fakeHoldRepository) ListExpiredHoldIDs(ctx context.Context, now time.Time, afterID int64, limit int) ([]int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := []int64{}
+	for id := afterID + 1; id <= f.nextID && len(ids) < limit; id++ {
+		if h, ok := f.holds[id]; ok && h.Status == model.LimitHoldActive && !h.ExpiresAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Runs the real repository against sqlmock to pin down that a conditional
+// UPDATE touching no row is what rejects the purchase; the database, not the
+// usecase, serialises concurrent deductions.
+func TestTransactionUsecase_CreateTransaction_ConditionalDeduction(t *testing.T) {
+	const (
+		nik   = "3171010101900001"
+		tenor = 6
+	)
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Gagal membuat mock database: %v", err)
+	}
+	defer db.Close()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE customer_limits\s+SET limit_amount = limit_amount - \?\s+WHERE customer_nik = \? AND tenor = \? AND limit_amount >= \?`).
+		WithArgs(int64(1000000), nik, tenor, int64(1000000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectRollback()
+
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	uc := NewTransactionUsecase(sqlxDB, customerRepo, repo.NewLimitRepository(sqlxDB), &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+	_, err = uc.CreateTransaction(context.Background(), &dto.CreateTransactionRequest{
+		CustomerNIK: nik,
+		OTR:         950000,
+		AdminFee:    50000,
+		Installment: 1000000,
+		Interest:    100000,
+		AssetName:   "Laptop",
+		Tenor:       tenor,
+	})
+
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTransactionUsecase_CreateHold_ConcurrentRequests(t *testing.T) {
+	const (
+		nik         = "3171010101900001"
+		tenor       = 6
+		jumlahReq   = 20
+		limitAwal   = int64(10000000)
+		nilaiPerReq = int64(1000000)
+	)
+	berhasilDiharapkan := int(limitAwal / nilaiPerReq)
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Gagal membuat mock database: %v", err)
+	}
+	defer db.Close()
+	sqlMock.MatchExpectationsInOrder(false)
+	for i := 0; i < jumlahReq; i++ {
+		sqlMock.ExpectBegin()
+	}
+	for i := 0; i < berhasilDiharapkan; i++ {
+		sqlMock.ExpectCommit()
+	}
+	for i := 0; i < jumlahReq-berhasilDiharapkan; i++ {
+		sqlMock.ExpectRollback()
+	}
+
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+	limitRepo := &fakeLimitRepository{limits: map[string]int64{fmt.Sprintf("%s/%d", nik, tenor): limitAwal}}
+	holdRepo := &fakeHoldRepository{}
+
+	uc := NewTransactionUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, limitRepo, &mockTransactionRepository{}, &mockInstallmentRepository{}, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, holdRepo, 24*time.Hour, 15*time.Minute)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		berhasil int
+		ditolak  int
+	)
+	for i := 0; i < jumlahReq; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.CreateHold(context.Background(), &dto.CreateHoldRequest{CustomerNIK: nik, Tenor: tenor, Amount: nilaiPerReq})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				berhasil++
+			case errors.Is(err, ErrLimitExceeded):
+				ditolak++
+			default:
+				t.Errorf("error tidak terduga: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, berhasilDiharapkan, berhasil)
+	assert.Equal(t, jumlahReq-berhasilDiharapkan, ditolak)
+	assert.Equal(t, int64(0), limitRepo.limits[limitRepo.key(nik, tenor)])
+	assert.Len(t, holdRepo.holds, berhasilDiharapkan)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTransactionUsecase_HoldLifecycle(t *testing.T) {
+	const (
+		nik   = "3171010101900001"
+		tenor = 6
+	)
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Gagal membuat mock database: %v", err)
+	}
+	defer db.Close()
+
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+	limitRepo := &fakeLimitRepository{limits: map[string]int64{fmt.Sprintf("%s/%d", nik, tenor): 5000000}}
+	saldo := func() int64 { return limitRepo.limits[limitRepo.key(nik, tenor)] }
+	holdRepo := &fakeHoldRepository{}
+	txRepo := &mockTransactionRepository{}
+	txRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	installmentRepo := &mockInstallmentRepository{}
+	installmentRepo.On("CreateInstallments", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	outbox := &recordingOutbox{}
+
+	uc := NewTransactionUsecase(sqlx.NewDb(db, "sqlmock"), customerRepo, limitRepo, txRepo, installmentRepo, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, outbox, &recordingAudit{}, holdRepo, 24*time.Hour, 15*time.Minute)
+	ctx := context.Background()
+
+	t.Run("konfirmasi memakai sebagian hold dan mengembalikan sisanya", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+		hold, err := uc.CreateHold(ctx, &dto.CreateHoldRequest{CustomerNIK: nik, Tenor: tenor, Amount: 3000000})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2000000), saldo())
+
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+		contract, err := uc.ConfirmHold(ctx, hold.ID, &dto.ConfirmHoldRequest{OTR: 2450000, AdminFee: 50000, AssetName: "Kulkas"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2500000), saldo())
+		assert.Equal(t, model.EventContractCreated, outbox.events[len(outbox.events)-1].EventType)
+
+		stored, _ := holdRepo.GetHold(ctx, hold.ID)
+		assert.Equal(t, model.LimitHoldConfirmed, stored.Status)
+		assert.Equal(t, contract.ContractNumber, *stored.ContractNumber)
+
+		movements := limitRepo.movements[len(limitRepo.movements)-2:]
+		assert.Equal(t, model.LimitMovementHoldRelease, movements[0].ReferenceType)
+		assert.Equal(t, int64(3000000), movements[0].Delta)
+		assert.Equal(t, model.LimitMovementContract, movements[1].ReferenceType)
+		assert.Equal(t, int64(-2500000), movements[1].Delta)
+		assert.Equal(t, int64(2500000), movements[1].Balance)
+
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectRollback()
+		_, err = uc.ConfirmHold(ctx, hold.ID, &dto.ConfirmHoldRequest{OTR: 2450000, AdminFee: 50000, AssetName: "Kulkas"})
+		assert.Equal(t, ErrHoldNotActive, err)
+	})
+
+	t.Run("konfirmasi melebihi hold ditolak", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+		hold, err := uc.CreateHold(ctx, &dto.CreateHoldRequest{CustomerNIK: nik, Tenor: tenor, Amount: 500000})
+		assert.NoError(t, err)
+
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectRollback()
+		_, err = uc.ConfirmHold(ctx, hold.ID, &dto.ConfirmHoldRequest{OTR: 550000, AdminFee: 50000, AssetName: "Kulkas"})
+		assert.Equal(t, ErrHoldAmountExceeded, err)
+
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+		released, err := uc.ReleaseHold(ctx, hold.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.LimitHoldReleased, released.Status)
+		assert.Equal(t, int64(2500000), saldo())
+	})
+
+	t.Run("hold kedaluwarsa dikembalikan oleh sweeper", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+		hold, err := uc.CreateHold(ctx, &dto.CreateHoldRequest{CustomerNIK: nik, Tenor: tenor, Amount: 1000000})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1500000), saldo())
+
+		expired, err := uc.ExpireHolds(ctx, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, expired)
+
+		// Masa hold habis sebelum sweeper berjalan.
+		stored := holdRepo.holds[hold.ID]
+		stored.ExpiresAt = time.Now().Add(-time.Second)
+		holdRepo.holds[hold.ID] = stored
+
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectRollback()
+		_, err = uc.ConfirmHold(ctx, hold.ID, &dto.ConfirmHoldRequest{OTR: 1000000, AssetName: "Kulkas"})
+		assert.Equal(t, ErrHoldExpired, err)
+
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+		expired, err = uc.ExpireHolds(ctx, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.Equal(t, int64(2500000), saldo())
+
+		assert.Equal(t, model.LimitHoldExpired, holdRepo.holds[hold.ID].Status)
+	})
+
+	t.Run("partner tidak melihat hold channel lain", func(t *testing.T) {
+		partnerCtx := auth.WithPrincipal(ctx, &auth.Principal{Type: auth.PrincipalPartner, ID: "key-1", Partner: "tokopedia", Roles: []string{auth.RolePartner}})
+		_, err := uc.GetHold(partnerCtx, 1)
+		assert.Equal(t, ErrHoldNotFound, err)
+	})
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTransactionUsecase_ListCustomerTransactions_Cursor(t *testing.T) {
+	const nik = "3171010101900001"
+	t1 := time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)
+	t2 := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	t3 := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	customerRepo := &mockCustomerRepository{}
+	customerRepo.On("GetCustomer", mock.Anything, nik).Return(&model.Customer{NIK: nik}, nil)
+	txRepo := &mockTransactionRepository{}
+
+	// Halaman pertama: tiga baris untuk limit 2 berarti masih ada halaman berikutnya.
+	txRepo.On("ListTransactions", mock.Anything, mock.MatchedBy(func(f model.TransactionFilter) bool {
+		return f.AfterContractNumber == "" && f.Limit == 3 && f.Tenor == 6 && f.To != nil && f.To.Day() == 4
+	})).Return([]model.Transaction{
+		{ContractNumber: "CON-3", CreatedAt: t1},
+		{ContractNumber: "CON-2", CreatedAt: t2},
+		{ContractNumber: "CON-1", CreatedAt: t3},
+	}, nil).Once()
+
+	uc := NewTransactionUsecase(nil, customerRepo, &mockLimitRepository{}, txRepo, &mockInstallmentRepository{}, &stubPricingService{}, &mockIdempotencyRepository{}, &sequentialContractNumbers{}, &mockPaymentRepository{}, &recordingLedger{}, &recordingOutbox{}, &recordingAudit{}, nil, 24*time.Hour, 15*time.Minute)
+	page1, err := uc.ListCustomerTransactions(context.Background(), nik, &dto.ListTransactionsRequest{Tenor: 6, To: "2025-01-03", Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page1.Items, 2)
+	assert.NotEmpty(t, page1.NextCursor)
+
+	// Halaman kedua memakai cursor dari baris terakhir halaman pertama.
+	txRepo.On("ListTransactions", mock.Anything, mock.MatchedBy(func(f model.TransactionFilter) bool {
+		return f.AfterContractNumber == "CON-2" && f.AfterCreatedAt.Equal(t2)
+	})).Return([]model.Transaction{{ContractNumber: "CON-1", CreatedAt: t3}}, nil).Once()
+
+	page2, err := uc.ListCustomerTransactions(context.Background(), nik, &dto.ListTransactionsRequest{Cursor: page1.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page2.Items, 1)
+	assert.Empty(t, page2.NextCursor)
+
+	_, err = uc.ListCustomerTransactions(context.Background(), nik, &dto.ListTransactionsRequest{Cursor: "!!!"})
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	txRepo.AssertExpectations(t)
+}
+
 // generateContractNumber generates a unique contract number based on NIK and timestamp
 func generateContractNumber(nik string, now time.Time) string {
 	// Take first 8 characters of NIK, or less if NIK is shorter
@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DeliveryJob is the background job that sends queued webhook deliveries to partners.
+type DeliveryJob struct {
+	usecase WebhookUsecase
+}
+
+func NewDeliveryJob(usecase WebhookUsecase) *DeliveryJob {
+	return &DeliveryJob{usecase: usecase}
+}
+
+func (j *DeliveryJob) Name() string {
+	return "webhook-delivery"
+}
+
+func (j *DeliveryJob) Run(ctx context.Context) error {
+	delivered, err := j.usecase.DeliverPending(ctx, time.Now())
+	if delivered > 0 {
+		log.Printf("Webhook delivery sent %d webhooks", delivered)
+	}
+	return err
+}
@@ -0,0 +1,328 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultDeliveryListLimit = 50
+	maxDeliveryListLimit     = 200
+)
+
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, s *model.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	ListActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, s *model.WebhookSubscription) error
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	ListClaimableDeliveries(ctx context.Context, tx repo.DBTx, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	ClaimDeliveries(ctx context.Context, tx repo.DBTx, ids []int64, owner string, until time.Time) error
+	UpdateDelivery(ctx context.Context, d *model.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit int) ([]model.WebhookDelivery, error)
+}
+
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, req *dto.CreateWebhookSubscriptionRequest) (*model.WebhookSubscription, error)
+	// ListSubscriptions and GetSubscription only show a partner its own
+	// subscriptions; to it any other subscription does not exist.
+	ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id int64, req *dto.UpdateWebhookSubscriptionRequest) (*model.WebhookSubscription, error)
+	ListDeliveries(ctx context.Context, id int64, req *dto.ListWebhookDeliveriesRequest) ([]model.WebhookDelivery, error)
+	// Publish queues a relayed event for every active subscription of the event's
+	// channel to its type, so the usecase can act as the outbox relay's event
+	// sink. Events of no channel are not sent to partners.
+	Publish(ctx context.Context, event service.EventEnvelope) error
+	// DeliverPending claims the deliveries due at now and sends them, at least
+	// once each, and schedules a retry for the ones the partner rejects until the
+	// attempts run out. It returns the number of deliveries that succeeded.
+	DeliverPending(ctx context.Context, now time.Time) (int, error)
+}
+
+type webhookUsecase struct {
+	db          *sqlx.DB
+	webhookRepo WebhookRepository
+	client      service.WebhookClient
+	backoff     service.Backoff
+	owner       string
+	claimTTL    time.Duration
+	maxAttempts int
+	batchSize   int
+}
+
+// NewWebhookUsecase builds the webhook usecase. owner identifies this process in
+// the delivery claims it takes; a claim older than claimTTL is taken over by
+// another sender, so it must outlast sending a whole batch.
+func NewWebhookUsecase(db *sqlx.DB, webhookRepo WebhookRepository, client service.WebhookClient, backoff service.Backoff, owner string, claimTTL time.Duration, maxAttempts, batchSize int) WebhookUsecase {
+	return &webhookUsecase{
+		db:          db,
+		webhookRepo: webhookRepo,
+		client:      client,
+		backoff:     backoff,
+		owner:       owner,
+		claimTTL:    claimTTL,
+		maxAttempts: maxAttempts,
+		batchSize:   batchSize,
+	}
+}
+
+func (u *webhookUsecase) CreateSubscription(ctx context.Context, req *dto.CreateWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = service.GenerateWebhookSecret(); err != nil {
+			return nil, fmt.Errorf("gagal membuat secret webhook: %w", err)
+		}
+	}
+
+	now := time.Now()
+	sub := &model.WebhookSubscription{
+		Partner:    strings.TrimSpace(req.Partner),
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: strings.Join(req.EventTypes, ","),
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := u.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("gagal membuat subscription webhook: %w", err)
+	}
+	return sub, nil
+}
+
+func (u *webhookUsecase) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	subs, err := u.webhookRepo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan subscription webhook: %w", err)
+	}
+
+	visible := subs[:0]
+	for i := range subs {
+		if visibleTo(ctx, &subs[i]) {
+			visible = append(visible, subs[i])
+		}
+	}
+	return visible, nil
+}
+
+func (u *webhookUsecase) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	sub, err := u.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan subscription webhook: %w", err)
+	}
+	if sub == nil || !visibleTo(ctx, sub) {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+// visibleTo reports whether the caller may see the subscription. Partners only
+// see their own subscriptions.
+func visibleTo(ctx context.Context, sub *model.WebhookSubscription) bool {
+	p := auth.FromContext(ctx)
+	return p == nil || !p.IsPartner() || sub.Partner == p.Partner
+}
+
+func (u *webhookUsecase) UpdateSubscription(ctx context.Context, id int64, req *dto.UpdateWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	sub, err := u.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		sub.URL = req.URL
+	}
+	if len(req.EventTypes) > 0 {
+		sub.EventTypes = strings.Join(req.EventTypes, ",")
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+	if req.RotateSecret {
+		if sub.Secret, err = service.GenerateWebhookSecret(); err != nil {
+			return nil, fmt.Errorf("gagal membuat secret webhook: %w", err)
+		}
+	}
+	sub.UpdatedAt = time.Now()
+
+	if err := u.webhookRepo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui subscription webhook: %w", err)
+	}
+	return sub, nil
+}
+
+func (u *webhookUsecase) ListDeliveries(ctx context.Context, id int64, req *dto.ListWebhookDeliveriesRequest) ([]model.WebhookDelivery, error) {
+	if _, err := u.GetSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultDeliveryListLimit
+	}
+	if limit > maxDeliveryListLimit {
+		limit = maxDeliveryListLimit
+	}
+
+	deliveries, err := u.webhookRepo.ListDeliveries(ctx, id, req.Status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan log pengiriman webhook: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (u *webhookUsecase) Publish(ctx context.Context, event service.EventEnvelope) error {
+	if !service.IsWebhookEventType(event.Type) || event.Channel == "" {
+		return nil
+	}
+
+	subs, err := u.webhookRepo.ListActiveSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal mendapatkan subscription webhook: %w", err)
+	}
+
+	var payload []byte
+	var deliveries []model.WebhookDelivery
+	now := time.Now()
+	for i := range subs {
+		// A partner only receives the events of contracts booked through its own channel.
+		if subs[i].Partner != event.Channel || !subs[i].Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("gagal membuat payload webhook: %w", err)
+			}
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			SubscriptionID: subs[i].ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+
+	if err := u.webhookRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("gagal menyimpan pengiriman webhook: %w", err)
+	}
+	return nil
+}
+
+func (u *webhookUsecase) DeliverPending(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := u.claim(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := make(map[int64]*model.WebhookSubscription)
+	var delivered, failed int
+	for i := range deliveries {
+		d := &deliveries[i]
+		if err := ctx.Err(); err != nil {
+			// The claims of the remaining deliveries expire and another run picks them up.
+			return delivered, err
+		}
+
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			if sub, err = u.webhookRepo.GetSubscription(ctx, d.SubscriptionID); err != nil {
+				return delivered, fmt.Errorf("gagal mendapatkan subscription webhook: %w", err)
+			}
+			subs[d.SubscriptionID] = sub
+		}
+
+		d.Attempts++
+		var statusCode int
+		var sendErr error
+		if sub == nil || !sub.Active {
+			// Deliveries of a deactivated subscription are dropped rather than left to retry.
+			sendErr = errors.New("subscription is inactive")
+			d.Attempts = max(d.Attempts, u.maxAttempts)
+		} else {
+			statusCode, sendErr = u.client.Send(ctx, sub, d, time.Now())
+		}
+		if statusCode != 0 {
+			d.LastStatusCode = &statusCode
+		}
+
+		if sendErr == nil {
+			deliveredAt := time.Now()
+			d.Status = model.WebhookDeliveryDelivered
+			d.DeliveredAt = &deliveredAt
+			d.LastError = nil
+			delivered++
+		} else {
+			failed++
+			message := sendErr.Error()
+			d.LastError = &message
+			if d.Attempts >= u.maxAttempts {
+				d.Status = model.WebhookDeliveryFailed
+				log.Printf("Error - pengiriman webhook %d (%s) gagal setelah %d percobaan: %v", d.ID, d.EventType, d.Attempts, sendErr)
+			} else {
+				d.NextAttemptAt = now.Add(u.backoff.Delay(d.Attempts))
+				log.Printf("Error - gagal mengirim webhook %d (%s), dicoba lagi %s: %v", d.ID, d.EventType, d.NextAttemptAt.Format(time.RFC3339), sendErr)
+			}
+		}
+
+		if err := u.webhookRepo.UpdateDelivery(ctx, d); err != nil {
+			return delivered, fmt.Errorf("gagal memperbarui pengiriman webhook: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return delivered, fmt.Errorf("gagal mengirim %d webhook", failed)
+	}
+	return delivered, nil
+}
+
+// claim locks the due deliveries, leases them to this sender and commits, so
+// other replicas skip them while they are sent outside the transaction.
+func (u *webhookUsecase) claim(ctx context.Context, now time.Time) ([]model.WebhookDelivery, error) {
+	dbTx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer dbTx.Rollback() // nolint:errcheck
+
+	deliveries, err := u.webhookRepo.ListClaimableDeliveries(ctx, dbTx, now, u.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan pengiriman webhook: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	ids := make([]int64, len(deliveries))
+	for i := range deliveries {
+		ids[i] = deliveries[i].ID
+	}
+	if err := u.webhookRepo.ClaimDeliveries(ctx, dbTx, ids, u.owner, now.Add(u.claimTTL)); err != nil {
+		return nil, fmt.Errorf("gagal mengklaim pengiriman webhook: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return deliveries, nil
+}
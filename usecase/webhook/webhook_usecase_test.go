@@ -0,0 +1,315 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"multifinance/auth"
+	"multifinance/delivery/dto"
+	"multifinance/model"
+	repo "multifinance/repository"
+	"multifinance/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *mockWebhookRepository) CreateSubscription(ctx context.Context, s *model.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) UpdateSubscription(ctx context.Context, s *model.WebhookSubscription) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) ListClaimableDeliveries(ctx context.Context, tx repo.DBTx, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, tx, now, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ClaimDeliveries(ctx context.Context, tx repo.DBTx, ids []int64, owner string, until time.Time) error {
+	args := m.Called(ctx, tx, ids, owner, until)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) UpdateDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	args := m.Called(ctx, *d)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, status, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+// stubClient answers with the status code listed per event, 200 by default.
+type stubClient struct {
+	status map[string]int
+	sent   []string
+}
+
+func (c *stubClient) Send(ctx context.Context, sub *model.WebhookSubscription, d *model.WebhookDelivery, at time.Time) (int, error) {
+	c.sent = append(c.sent, d.EventID)
+	if code, ok := c.status[d.EventID]; ok {
+		return code, &service.WebhookError{StatusCode: code}
+	}
+	return 200, nil
+}
+
+func newTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return sqlx.NewDb(db, "sqlmock"), sqlMock
+}
+
+func TestWebhookUsecase_CreateSubscription(t *testing.T) {
+	repo := &mockWebhookRepository{}
+	repo.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(s *model.WebhookSubscription) bool {
+		return s.Partner == "tokopedia" && s.Active && len(s.Secret) > 16 &&
+			s.EventTypes == "contract.created,payment.received"
+	})).Return(nil).Once()
+
+	uc := NewWebhookUsecase(nil, repo, &stubClient{}, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+	sub, err := uc.CreateSubscription(context.Background(), &dto.CreateWebhookSubscriptionRequest{
+		Partner:    " tokopedia ",
+		URL:        "https://partner.example/hooks",
+		EventTypes: []string{model.EventContractCreated, model.EventPaymentReceived},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, sub.Subscribes(model.EventPaymentReceived))
+	assert.False(t, sub.Subscribes(model.EventLimitChanged))
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookUsecase_UpdateSubscription(t *testing.T) {
+	tests := []struct {
+		namaTest       string
+		setupMocks     func(repo *mockWebhookRepository)
+		erorDiharapkan error
+	}{
+		{
+			namaTest: "menonaktifkan subscription dan mengganti secret",
+			setupMocks: func(repo *mockWebhookRepository) {
+				repo.On("GetSubscription", mock.Anything, int64(1)).
+					Return(&model.WebhookSubscription{ID: 1, Secret: "lama", EventTypes: "contract.created", Active: true}, nil).Once()
+				repo.On("UpdateSubscription", mock.Anything, mock.MatchedBy(func(s *model.WebhookSubscription) bool {
+					return !s.Active && s.Secret != "lama" && s.EventTypes == "contract.created"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			namaTest: "subscription tidak ditemukan",
+			setupMocks: func(repo *mockWebhookRepository) {
+				repo.On("GetSubscription", mock.Anything, int64(1)).Return(nil, nil).Once()
+			},
+			erorDiharapkan: ErrSubscriptionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namaTest, func(t *testing.T) {
+			repo := &mockWebhookRepository{}
+			tt.setupMocks(repo)
+
+			inactive := false
+			uc := NewWebhookUsecase(nil, repo, &stubClient{}, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+			_, err := uc.UpdateSubscription(context.Background(), 1, &dto.UpdateWebhookSubscriptionRequest{Active: &inactive, RotateSecret: true})
+
+			assert.Equal(t, tt.erorDiharapkan, err)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookUsecase_Publish(t *testing.T) {
+	repo := &mockWebhookRepository{}
+	repo.On("ListActiveSubscriptions", mock.Anything).Return([]model.WebhookSubscription{
+		{ID: 1, Partner: "tokopaedi", EventTypes: "contract.created,payment.received"},
+		{ID: 2, Partner: "tokopaedi", EventTypes: "contract.created"},
+		{ID: 3, Partner: "tokopaedi", EventTypes: "payment.received"},
+	}, nil).Once()
+	repo.On("CreateDeliveries", mock.Anything, mock.MatchedBy(func(deliveries []model.WebhookDelivery) bool {
+		return len(deliveries) == 2 &&
+			deliveries[0].SubscriptionID == 1 && deliveries[1].SubscriptionID == 3 &&
+			deliveries[0].EventID == "evt-1" && deliveries[0].Status == model.WebhookDeliveryPending
+	})).Return(nil).Once()
+
+	uc := NewWebhookUsecase(nil, repo, &stubClient{}, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+	err := uc.Publish(context.Background(), service.EventEnvelope{ID: "evt-1", Type: model.EventPaymentReceived, Channel: "tokopaedi"})
+	assert.NoError(t, err)
+
+	// Events partners cannot subscribe to are not fanned out at all.
+	err = uc.Publish(context.Background(), service.EventEnvelope{ID: "evt-2", Type: model.EventContractPaidOff, Channel: "tokopaedi"})
+	assert.NoError(t, err)
+
+	// Nor are events of no channel.
+	err = uc.Publish(context.Background(), service.EventEnvelope{ID: "evt-3", Type: model.EventPaymentReceived})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookUsecase_Publish_ScopedToPartner(t *testing.T) {
+	subs := []model.WebhookSubscription{
+		{ID: 1, Partner: "tokopaedi", EventTypes: "contract.created,payment.received"},
+		{ID: 2, Partner: "bukalapax", EventTypes: "contract.created,payment.received"},
+	}
+
+	for _, partner := range []struct {
+		channel        string
+		subscriptionID int64
+	}{
+		{"tokopaedi", 1},
+		{"bukalapax", 2},
+	} {
+		repo := &mockWebhookRepository{}
+		repo.On("ListActiveSubscriptions", mock.Anything).Return(subs, nil)
+		repo.On("CreateDeliveries", mock.Anything, mock.MatchedBy(func(deliveries []model.WebhookDelivery) bool {
+			return len(deliveries) == 1 && deliveries[0].SubscriptionID == partner.subscriptionID
+		})).Return(nil).Twice()
+
+		uc := NewWebhookUsecase(nil, repo, &stubClient{}, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+		for _, eventType := range []string{model.EventContractCreated, model.EventPaymentReceived} {
+			err := uc.Publish(context.Background(), service.EventEnvelope{ID: "evt-" + partner.channel, Type: eventType, Channel: partner.channel})
+			assert.NoError(t, err)
+		}
+		repo.AssertExpectations(t)
+	}
+}
+
+func TestWebhookUsecase_DeliverPending(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	deliveries := []model.WebhookDelivery{
+		{ID: 1, SubscriptionID: 1, EventID: "e1", EventType: model.EventContractCreated, Status: model.WebhookDeliveryPending},
+		{ID: 2, SubscriptionID: 1, EventID: "e2", EventType: model.EventPaymentReceived, Attempts: 1, Status: model.WebhookDeliveryPending},
+		{ID: 3, SubscriptionID: 1, EventID: "e3", EventType: model.EventPaymentReceived, Attempts: 2, Status: model.WebhookDeliveryPending},
+		{ID: 4, SubscriptionID: 2, EventID: "e4", EventType: model.EventContractCreated, Status: model.WebhookDeliveryPending},
+	}
+
+	db, sqlMock := newTestDB(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	repo := &mockWebhookRepository{}
+	repo.On("ListClaimableDeliveries", mock.Anything, mock.Anything, now, 100).Return(deliveries, nil).Once()
+	repo.On("ClaimDeliveries", mock.Anything, mock.Anything, []int64{1, 2, 3, 4}, "sender-1", now.Add(time.Minute)).Return(nil).Once()
+	repo.On("GetSubscription", mock.Anything, int64(1)).Return(&model.WebhookSubscription{ID: 1, Active: true}, nil).Once()
+	repo.On("GetSubscription", mock.Anything, int64(2)).Return(&model.WebhookSubscription{ID: 2, Active: false}, nil).Once()
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d model.WebhookDelivery) bool {
+		return d.ID == 1 && d.Status == model.WebhookDeliveryDelivered && d.Attempts == 1 && d.DeliveredAt != nil
+	})).Return(nil).Once()
+	// Second failure in a row: 10s doubled once.
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d model.WebhookDelivery) bool {
+		return d.ID == 2 && d.Status == model.WebhookDeliveryPending && d.Attempts == 2 &&
+			d.NextAttemptAt.Equal(now.Add(20*time.Second)) && *d.LastStatusCode == 503
+	})).Return(nil).Once()
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d model.WebhookDelivery) bool {
+		return d.ID == 3 && d.Status == model.WebhookDeliveryFailed && d.Attempts == 3
+	})).Return(nil).Once()
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d model.WebhookDelivery) bool {
+		return d.ID == 4 && d.Status == model.WebhookDeliveryFailed && *d.LastError == "subscription is inactive"
+	})).Return(nil).Once()
+
+	client := &stubClient{status: map[string]int{"e2": 503, "e3": 500}}
+	uc := NewWebhookUsecase(db, repo, client, service.Backoff{Base: 10 * time.Second, Max: time.Hour}, "sender-1", time.Minute, 3, 100)
+	delivered, err := uc.DeliverPending(context.Background(), now)
+
+	assert.EqualError(t, err, "gagal mengirim 3 webhook")
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []string{"e1", "e2", "e3"}, client.sent)
+	repo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestWebhookUsecase_DeliverPending_NothingToClaim(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	// Deliveries claimed by another replica are not returned, so nothing is sent twice.
+	db, sqlMock := newTestDB(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+
+	repo := &mockWebhookRepository{}
+	repo.On("ListClaimableDeliveries", mock.Anything, mock.Anything, now, 100).Return([]model.WebhookDelivery{}, nil).Once()
+
+	client := &stubClient{}
+	uc := NewWebhookUsecase(db, repo, client, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+	delivered, err := uc.DeliverPending(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Zero(t, delivered)
+	assert.Empty(t, client.sent)
+	repo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestWebhookUsecase_ListDeliveries_SubscriptionNotFound(t *testing.T) {
+	repo := &mockWebhookRepository{}
+	repo.On("GetSubscription", mock.Anything, int64(9)).Return(nil, errors.New("database error")).Once()
+
+	uc := NewWebhookUsecase(nil, repo, &stubClient{}, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+	_, err := uc.ListDeliveries(context.Background(), 9, &dto.ListWebhookDeliveriesRequest{})
+
+	assert.EqualError(t, err, "gagal mendapatkan subscription webhook: database error")
+}
+
+func TestWebhookUsecase_PartnerSeesOnlyOwnSubscriptions(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Type: auth.PrincipalPartner, ID: "12", Partner: "tokopaedi", Roles: []string{auth.RolePartner}})
+	own := &model.WebhookSubscription{ID: 1, Partner: "tokopaedi"}
+	other := &model.WebhookSubscription{ID: 2, Partner: "bukalapax"}
+
+	repo := &mockWebhookRepository{}
+	repo.On("ListSubscriptions", mock.Anything).Return([]model.WebhookSubscription{*own, *other}, nil).Once()
+	repo.On("GetSubscription", mock.Anything, int64(1)).Return(own, nil).Once()
+	repo.On("GetSubscription", mock.Anything, int64(2)).Return(other, nil).Once()
+	repo.On("ListDeliveries", mock.Anything, int64(1), "", defaultDeliveryListLimit).Return([]model.WebhookDelivery{{ID: 7, SubscriptionID: 1}}, nil).Once()
+
+	uc := NewWebhookUsecase(nil, repo, &stubClient{}, service.Backoff{}, "sender-1", time.Minute, 3, 100)
+
+	subs, err := uc.ListSubscriptions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.WebhookSubscription{*own}, subs)
+
+	deliveries, err := uc.ListDeliveries(ctx, 1, &dto.ListWebhookDeliveriesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	// Another partner's delivery log does not exist to the caller.
+	_, err = uc.ListDeliveries(ctx, 2, &dto.ListWebhookDeliveriesRequest{})
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+	repo.AssertExpectations(t)
+}
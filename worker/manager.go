@@ -0,0 +1,132 @@
+// Package worker runs periodic background jobs and reports their health.
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run on a fixed interval.
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// JobStatus is a snapshot of a job's most recent run.
+type JobStatus struct {
+	Name      string
+	Interval  time.Duration
+	Running   bool
+	LastStart time.Time
+	LastEnd   time.Time
+	LastError error
+}
+
+type scheduledJob struct {
+	job      Job
+	interval time.Duration
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// Manager starts registered jobs in their own goroutines and stops them together.
+type Manager struct {
+	jobs   []*scheduledJob
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Every registers job to run once at start and then every interval. Jobs must be
+// registered before Start.
+func (m *Manager) Every(interval time.Duration, job Job) {
+	m.jobs = append(m.jobs, &scheduledJob{
+		job:      job,
+		interval: interval,
+		status:   JobStatus{Name: job.Name(), Interval: interval},
+	})
+}
+
+func (m *Manager) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+	for _, j := range m.jobs {
+		m.wg.Add(1)
+		go m.loop(ctx, j)
+	}
+}
+
+// Stop cancels all jobs and waits until the ones in progress return.
+func (m *Manager) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// Shutdown cancels all jobs like Stop but gives up waiting for the ones in
+// progress when ctx is done, returning ctx's error.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status returns the state of every registered job.
+func (m *Manager) Status() []JobStatus {
+	statuses := make([]JobStatus, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		statuses = append(statuses, j.status)
+		j.mu.Unlock()
+	}
+	return statuses
+}
+
+func (m *Manager) loop(ctx context.Context, j *scheduledJob) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		m.run(ctx, j)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) run(ctx context.Context, j *scheduledJob) {
+	j.mu.Lock()
+	j.status.Running = true
+	j.status.LastStart = time.Now()
+	j.mu.Unlock()
+
+	err := j.job.Run(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Error - job %s failed: %v", j.job.Name(), err)
+	}
+
+	j.mu.Lock()
+	j.status.Running = false
+	j.status.LastEnd = time.Now()
+	j.status.LastError = err
+	j.mu.Unlock()
+}
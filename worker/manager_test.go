@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingJob struct {
+	runs int32
+	err  error
+}
+
+func (j *countingJob) Name() string { return "counting" }
+
+func (j *countingJob) Run(ctx context.Context) error {
+	atomic.AddInt32(&j.runs, 1)
+	return j.err
+}
+
+func TestManager_RunsJobsUntilStopped(t *testing.T) {
+	job := &countingJob{err: errors.New("boom")}
+	m := NewManager()
+	m.Every(5*time.Millisecond, job)
+
+	m.Start(context.Background())
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&job.runs) >= 3 }, time.Second, time.Millisecond)
+	m.Stop()
+
+	runs := atomic.LoadInt32(&job.runs)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, runs, atomic.LoadInt32(&job.runs), "no runs after Stop")
+
+	status := m.Status()
+	assert.Len(t, status, 1)
+	assert.Equal(t, "counting", status[0].Name)
+	assert.False(t, status[0].Running)
+	assert.EqualError(t, status[0].LastError, "boom")
+}
+
+type blockingJob struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (j *blockingJob) Name() string { return "blocking" }
+
+// Run ignores cancellation, like a job stuck in a slow query.
+func (j *blockingJob) Run(ctx context.Context) error {
+	close(j.started)
+	<-j.release
+	return nil
+}
+
+func TestManager_ShutdownGivesUpAtDeadline(t *testing.T) {
+	job := &blockingJob{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(job.release)
+	m := NewManager()
+	m.Every(time.Hour, job)
+
+	m.Start(context.Background())
+	<-job.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, m.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestManager_ShutdownWaitsForJobs(t *testing.T) {
+	job := &countingJob{}
+	m := NewManager()
+	m.Every(time.Hour, job)
+
+	m.Start(context.Background())
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&job.runs) == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, m.Shutdown(context.Background()))
+}